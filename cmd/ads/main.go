@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"goads/internal/ads/adapters/pgrepo"
 	"goads/internal/ads/app"
-	"goads/internal/ads/grpc"
+	grpcPort "goads/internal/ads/grpc"
+	"goads/internal/ads/screener"
+	authProto "goads/internal/auth/proto"
 	"goads/internal/pkg/config"
 	"goads/internal/pkg/shutdown"
+	"goads/internal/pkg/userevents"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"log"
 	"os"
 	"time"
@@ -19,26 +24,52 @@ type Config struct {
 	Env          string `env:"ENV" env-default:"local"`
 	GRPCAddress  string `env:"GRPC_ADDRESS" env-default:":8888"`
 	PostgresConn string `env:"POSTGRES_CONN" env-required:"true"`
+	AuthPath     string `env:"AUTH_PATH" env-required:"true"`
+	EventsPoll   int    `env:"USER_EVENTS_POLL_SECONDS" env-default:"30"`
+	EventsBatch  int    `env:"USER_EVENTS_BATCH_SIZE" env-default:"100"`
+	// BannedWords are comma-separated words of ads which are rejected without review
+	BannedWords []string `env:"BANNED_WORDS" env-separator:","`
 }
 
 func main() {
 	cfg := config.MustLoadENV[Config](os.Getenv("CONFIG_PATH"))
 	eg, ctx := errgroup.WithContext(context.Background())
 
-	conn, err := pgx.Connect(ctx, cfg.PostgresConn)
+	// the pool is shared by the server and background workers, a single connection cannot be used concurrently
+	pool, err := pgxpool.New(ctx, cfg.PostgresConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+	err = pool.Ping(ctx)
 	for i := 0; i < 5 && err != nil; i++ {
 		time.Sleep(time.Second * 3)
 		fmt.Printf("Reconnect to PostgreSQL #%d\n", i+1)
-		conn, err = pgx.Connect(ctx, cfg.PostgresConn)
+		err = pool.Ping(ctx)
 	}
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() { _ = conn.Close(ctx) }()
+	authConn, err := grpc.DialContext(ctx, cfg.AuthPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	for i := 0; i < 10 && err != nil; i++ {
+		time.Sleep(time.Second * 3)
+		fmt.Printf("Reconnect to Auth #%d\n", i+1)
+		authConn, err = grpc.DialContext(ctx, cfg.AuthPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if err != nil {
+		log.Fatalf("Cannot start connection with Auth: %v", err)
+	}
+
+	repo := pgrepo.New(pool)
+	a := app.New(repo, screener.New(cfg.BannedWords))
+	grpcServer := grpcPort.NewServer(cfg.GRPCAddress, a)
 
-	grpcServer := grpc.NewServer(cfg.GRPCAddress, app.New(pgrepo.New(conn)))
+	consumer := userevents.New(
+		"ads", userevents.NewAuthSource(authProto.NewAuthServiceClient(authConn)), repo, a,
+		time.Duration(cfg.EventsPoll)*time.Second, cfg.EventsBatch,
+	)
 
-	shutdown.Gracefully(eg, ctx, grpcServer)
+	shutdown.Gracefully(eg, ctx, grpcServer, consumer)
 
 	if err := eg.Wait(); err != nil {
 		log.Println("Graceful shutdown servers:", err)
@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	adProto "goads/internal/ads/proto"
+	"goads/internal/api/auth/verifier"
 	"goads/internal/api/server"
 	authProto "goads/internal/auth/proto"
 	"goads/internal/pkg/config"
+	"goads/internal/pkg/permissions"
 	"goads/internal/pkg/shutdown"
 	shProto "goads/internal/urlshortener/proto"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"log"
+	"net/http"
 	"os"
 	"time"
 )
@@ -23,12 +26,21 @@ type Config struct {
 	URLShortenerPath string `env:"URL_SHORTENER_PATH" env-required:"true"`
 	AuthPath         string `env:"AUTH_PATH" env-required:"true"`
 	AdsPath          string `env:"ADS_PATH" env-required:"true"`
+	RedirectCode     int    `env:"REDIRECT_CODE" env-default:"302"`
+	LocalVerify      bool   `env:"AUTH_LOCAL_VERIFY" env-default:"false"`
+	KeysFetchSeconds int    `env:"KEYS_FETCH_SECONDS" env-default:"30"`
+	// RevocationsFetchSeconds is how long revoked tokens can be accepted by local verification
+	RevocationsFetchSeconds int `env:"REVOCATIONS_FETCH_SECONDS" env-default:"5"`
 }
 
 func connect(ctx context.Context, name string, path string) *grpc.ClientConn {
-	conn, err := grpc.DialContext(ctx, path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(permissions.UnaryClientInterceptor),
+	}
+	conn, err := grpc.DialContext(ctx, path, opts...)
 	for i := 0; i < 10 && err != nil; i++ {
-		conn, err = grpc.DialContext(ctx, path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err = grpc.DialContext(ctx, path, opts...)
 		fmt.Printf("Reconnect to %s #%d", name, i+1)
 		time.Sleep(time.Second * 3)
 	}
@@ -40,6 +52,11 @@ func connect(ctx context.Context, name string, path string) *grpc.ClientConn {
 
 func main() {
 	cfg := config.MustLoadENV[Config](os.Getenv("CONFIG_PATH"))
+	switch cfg.RedirectCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect:
+	default:
+		log.Fatalf("Unsupported redirect code: %d", cfg.RedirectCode)
+	}
 
 	eg, ctx := errgroup.WithContext(context.Background())
 
@@ -51,8 +68,23 @@ func main() {
 	authSvc := authProto.NewAuthServiceClient(authConn)
 	adsSvc := adProto.NewAdServiceClient(adsConn)
 
-	srv := server.New(cfg.HTTPAddress, authSvc, shSvc, adsSvc)
-	shutdown.Gracefully(eg, ctx, srv)
+	servers := []shutdown.Server{}
+	if cfg.LocalVerify {
+		client := verifier.New(
+			authSvc,
+			time.Duration(cfg.KeysFetchSeconds)*time.Second,
+			time.Second,
+			time.Duration(cfg.RevocationsFetchSeconds)*time.Second,
+		)
+		if err := client.Fetch(ctx); err != nil {
+			log.Println(err)
+		}
+		authSvc = client
+		servers = append(servers, client)
+	}
+
+	srv := server.New(cfg.HTTPAddress, cfg.RedirectCode, authSvc, shSvc, adsSvc)
+	shutdown.Gracefully(eg, ctx, append(servers, srv)...)
 
 	if err := eg.Wait(); err != nil {
 		log.Println("Graceful shutdown server:", err)
@@ -3,11 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"goads/internal/auth/adapters/actions"
+	"goads/internal/auth/adapters/argon2"
 	"goads/internal/auth/adapters/bcrypt"
+	"goads/internal/auth/adapters/guard"
+	"goads/internal/auth/adapters/hashers"
 	"goads/internal/auth/adapters/jwt"
+	"goads/internal/auth/adapters/keyring"
+	"goads/internal/auth/adapters/mailer"
 	"goads/internal/auth/adapters/pgrepo"
+	"goads/internal/auth/adapters/revocations"
+	"goads/internal/auth/adapters/totp"
 	"goads/internal/auth/app"
 	"goads/internal/auth/grpc"
+	"goads/internal/auth/purger"
 	"goads/internal/pkg/config"
 	"goads/internal/pkg/shutdown"
 	"golang.org/x/sync/errgroup"
@@ -15,17 +24,48 @@ import (
 	"os"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Config struct {
-	Env          string `env:"ENV" env-default:"local"`
-	PrivateKey   string `env:"AUTH_PRIVATE_KEY" env-required:"true"`
-	PublicKey    string `env:"AUTH_PUBLIC_KEY" env-required:"true"`
-	Expires      int    `env:"AUTH_EXPIRES_HOURS" env-default:"24"`
-	PasswordCost int    `env:"PASSWORD_COST" env-default:"10"`
-	GRPCAddress  string `env:"GRPC_ADDRESS" env-default:":8888"`
-	PostgresConn string `env:"POSTGRES_CONN" env-required:"true"`
+	Env                      string `env:"ENV" env-default:"local"`
+	PrivateKey               string `env:"AUTH_PRIVATE_KEY"`
+	KeyRotationHours         int    `env:"AUTH_KEY_ROTATION_HOURS" env-default:"720"`
+	KeyGraceHours            int    `env:"AUTH_KEY_GRACE_HOURS" env-default:"24"`
+	KeysReloadSeconds        int    `env:"KEYS_RELOAD_SECONDS" env-default:"60"`
+	Expires                  int    `env:"AUTH_EXPIRES_MINUTES" env-default:"15"`
+	RefreshExpires           int    `env:"AUTH_REFRESH_EXPIRES_HOURS" env-default:"720"`
+	RevocationsReloadSeconds int    `env:"REVOCATIONS_RELOAD_SECONDS" env-default:"30"`
+	PasswordHasher           string `env:"PASSWORD_HASHER" env-default:"argon2id"`
+	PasswordCost             int    `env:"PASSWORD_COST" env-default:"10"`
+	Argon2MemoryKiB          uint32 `env:"ARGON2_MEMORY_KIB" env-default:"65536"`
+	Argon2Time               uint32 `env:"ARGON2_TIME" env-default:"3"`
+	Argon2Threads            uint8  `env:"ARGON2_THREADS" env-default:"2"`
+	ActionSecret             string `env:"AUTH_ACTION_SECRET" env-required:"true"`
+	VerifyURL                string `env:"VERIFY_EMAIL_URL" env-required:"true"`
+	ResetURL                 string `env:"RESET_PASSWORD_URL" env-required:"true"`
+	RestoreURL               string `env:"RESTORE_ACCOUNT_URL" env-required:"true"`
+	VerifyExpiresHours       int    `env:"VERIFY_EMAIL_EXPIRES_HOURS" env-default:"48"`
+	ResetExpiresMinutes      int    `env:"RESET_PASSWORD_EXPIRES_MINUTES" env-default:"30"`
+	Mailer                   string `env:"MAILER" env-default:"log"`
+	MailLogFile              string `env:"MAIL_LOG_FILE"`
+	SMTPAddress              string `env:"SMTP_ADDRESS"`
+	SMTPUsername             string `env:"SMTP_USERNAME"`
+	SMTPPassword             string `env:"SMTP_PASSWORD"`
+	SMTPFrom                 string `env:"SMTP_FROM"`
+	TOTPIssuer               string `env:"TOTP_ISSUER" env-default:"goads"`
+	TOTPSkewSteps            int    `env:"TOTP_SKEW_STEPS" env-default:"1"`
+	ChallengeExpiresMinutes  int    `env:"TWO_FACTOR_CHALLENGE_MINUTES" env-default:"5"`
+	LoginFreeFailures        int    `env:"LOGIN_FREE_FAILURES" env-default:"3"`
+	LoginMaxFailures         int    `env:"LOGIN_MAX_FAILURES" env-default:"10"`
+	LoginIPFreeFailures      int    `env:"LOGIN_IP_FREE_FAILURES" env-default:"20"`
+	LoginIPMaxFailures       int    `env:"LOGIN_IP_MAX_FAILURES" env-default:"100"`
+	LoginBackoffSeconds      int    `env:"LOGIN_BACKOFF_SECONDS" env-default:"1"`
+	LoginLockoutMinutes      int    `env:"LOGIN_LOCKOUT_MINUTES" env-default:"15"`
+	DeletionGraceHours       int    `env:"ACCOUNT_DELETION_GRACE_HOURS" env-default:"720"`
+	PurgeMinutes             int    `env:"PURGE_INTERVAL_MINUTES" env-default:"10"`
+	GRPCAddress              string `env:"GRPC_ADDRESS" env-default:":8888"`
+	PostgresConn             string `env:"POSTGRES_CONN" env-required:"true"`
 }
 
 func mustReadFile(file string) []byte {
@@ -36,35 +76,124 @@ func mustReadFile(file string) []byte {
 	return b
 }
 
+// mustCreateMailer creates SMTP mailer or log mailer writing to the file or stdout if the file is not set
+func mustCreateMailer(cfg *Config) app.Mailer {
+	switch cfg.Mailer {
+	case "smtp":
+		return mailer.NewSMTP(cfg.SMTPAddress, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	case "log":
+		if cfg.MailLogFile == "" {
+			return mailer.NewLog(os.Stdout)
+		}
+		f, err := os.OpenFile(cfg.MailLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return mailer.NewLog(f)
+	}
+	log.Fatalf("Unsupported mailer: %s", cfg.Mailer)
+	return nil
+}
+
+// mustCreateHasher creates hasher of the configured algorithm. Hashes of other algorithms are still accepted
+// and upgraded on login. PASSWORD_COST is cost of bcrypt
+func mustCreateHasher(cfg *Config) app.Hasher {
+	bcryptHasher := bcrypt.New(cfg.PasswordCost)
+	argon2Hasher := argon2.New(argon2.Params{
+		Memory:  cfg.Argon2MemoryKiB,
+		Time:    cfg.Argon2Time,
+		Threads: cfg.Argon2Threads,
+	})
+	switch cfg.PasswordHasher {
+	case "argon2id":
+		return hashers.New(argon2Hasher, bcryptHasher)
+	case "bcrypt":
+		return hashers.New(bcryptHasher, argon2Hasher)
+	}
+	log.Fatalf("Unsupported password hasher: %s", cfg.PasswordHasher)
+	return nil
+}
+
 func main() {
 	cfg := config.MustLoadENV[Config](os.Getenv("CONFIG_PATH"))
-	tokenizer, err := jwt.NewTokenizer(time.Duration(cfg.Expires)*time.Hour, mustReadFile(cfg.PrivateKey))
+	eg, ctx := errgroup.WithContext(context.Background())
+
+	// the pool is shared by the server and background workers, a single connection cannot be used concurrently
+	pool, err := pgxpool.New(ctx, cfg.PostgresConn)
 	if err != nil {
 		log.Fatal(err)
 	}
-	validator, err := jwt.NewValidator(mustReadFile(cfg.PublicKey))
+	defer pool.Close()
+	err = pool.Ping(ctx)
+	for i := 0; i < 5 && err != nil; i++ {
+		time.Sleep(time.Second * 3)
+		fmt.Printf("Reconnect to PostgreSQL #%d", i+1)
+		err = pool.Ping(ctx)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	eg, ctx := errgroup.WithContext(context.Background())
+	repo := pgrepo.New(pool)
+	store := revocations.New(
+		repo,
+		time.Duration(cfg.RevocationsReloadSeconds)*time.Second,
+		time.Duration(cfg.Expires)*time.Minute,
+	)
+	if err := store.Load(ctx); err != nil {
+		log.Fatal(err)
+	}
 
-	conn, err := pgx.Connect(ctx, cfg.PostgresConn)
-	for i := 0; i < 5 && err != nil; i++ {
-		time.Sleep(time.Second * 3)
-		fmt.Printf("Reconnect to PostgreSQL #%d", i+1)
-		conn, err = pgx.Connect(ctx, cfg.PostgresConn)
+	// the configured key is only the first key of the ring, next ones are generated by rotation
+	var initialKey []byte
+	if cfg.PrivateKey != "" {
+		initialKey = mustReadFile(cfg.PrivateKey)
 	}
-	if err != nil {
+	keys := keyring.New(
+		repo,
+		initialKey,
+		time.Duration(cfg.KeyRotationHours)*time.Hour,
+		time.Duration(cfg.KeyGraceHours)*time.Hour,
+		time.Duration(cfg.KeysReloadSeconds)*time.Second,
+	)
+	if err := keys.Load(ctx); err != nil {
 		log.Fatal(err)
 	}
-	defer func() { _ = conn.Close(ctx) }()
+	tokenizer := jwt.NewTokenizer(time.Duration(cfg.Expires)*time.Minute, keys)
+	validator := jwt.NewValidator(keys, store)
+
+	signer := actions.New([]byte(cfg.ActionSecret))
+	mailing := app.Mailing{
+		Mailer:        mustCreateMailer(cfg),
+		Signer:        signer,
+		VerifyURL:     cfg.VerifyURL,
+		ResetURL:      cfg.ResetURL,
+		RestoreURL:    cfg.RestoreURL,
+		VerifyExpires: time.Duration(cfg.VerifyExpiresHours) * time.Hour,
+		ResetExpires:  time.Duration(cfg.ResetExpiresMinutes) * time.Minute,
+	}
+	twoFactor := app.TwoFactor{
+		OTP:              totp.New(cfg.TOTPIssuer, cfg.TOTPSkewSteps),
+		Signer:           signer,
+		ChallengeExpires: time.Duration(cfg.ChallengeExpiresMinutes) * time.Minute,
+	}
+
+	backoff := time.Duration(cfg.LoginBackoffSeconds) * time.Second
+	lockout := time.Duration(cfg.LoginLockoutMinutes) * time.Minute
+	loginGuard := guard.New(
+		repo,
+		guard.Policy{Free: cfg.LoginFreeFailures, Max: cfg.LoginMaxFailures, Backoff: backoff, Lockout: lockout},
+		guard.Policy{Free: cfg.LoginIPFreeFailures, Max: cfg.LoginIPMaxFailures, Backoff: backoff, Lockout: lockout},
+	)
 
-	a := app.New(pgrepo.New(conn), tokenizer, bcrypt.New(cfg.PasswordCost), validator)
+	a := app.New(repo, tokenizer, mustCreateHasher(cfg), validator, validator, keys, mailing, twoFactor,
+		loginGuard, time.Duration(cfg.RefreshExpires)*time.Hour, time.Duration(cfg.DeletionGraceHours)*time.Hour)
 
 	grpcServer := grpc.NewServer(cfg.GRPCAddress, a)
 
-	shutdown.Gracefully(eg, ctx, grpcServer)
+	pr := purger.New(repo, time.Duration(cfg.PurgeMinutes)*time.Minute)
+
+	shutdown.Gracefully(eg, ctx, grpcServer, store, keys, pr)
 
 	if err := eg.Wait(); err != nil {
 		log.Println("Graceful shutdown server:", err)
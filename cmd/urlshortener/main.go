@@ -4,13 +4,22 @@ import (
 	"context"
 	"fmt"
 	adProto "goads/internal/ads/proto"
+	authProto "goads/internal/auth/proto"
 	"goads/internal/pkg/config"
 	"goads/internal/pkg/shutdown"
+	"goads/internal/pkg/userevents"
 	"goads/internal/urlshortener/adapters/ads"
+	"goads/internal/urlshortener/adapters/impressions"
+	"goads/internal/urlshortener/adapters/limiter"
+	"goads/internal/urlshortener/adapters/passwords"
 	"goads/internal/urlshortener/adapters/pgrepo"
+	"goads/internal/urlshortener/analytics"
 	"goads/internal/urlshortener/app"
+	"goads/internal/urlshortener/entities/links"
 	"goads/internal/urlshortener/generator"
 	grpcPort "goads/internal/urlshortener/grpc"
+	adSelectors "goads/internal/urlshortener/selectors"
+	"goads/internal/urlshortener/sweeper"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -18,7 +27,7 @@ import (
 	"os"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Config struct {
@@ -26,6 +35,22 @@ type Config struct {
 	GRPCAddress  string `env:"GRPC_ADDRESS" env-default:":8888"`
 	PostgresConn string `env:"POSTGRES_CONN" env-required:"true"`
 	AdsPath      string `env:"ADS_PATH" env-required:"true"`
+	AuthPath     string `env:"AUTH_PATH" env-required:"true"`
+	ClicksSalt   string `env:"CLICKS_IP_SALT" env-required:"true"`
+	// ImpressionSecret signs tokens of shown ads, clicks are recorded only with them
+	ImpressionSecret  string  `env:"IMPRESSION_SECRET" env-required:"true"`
+	ImpressionMinutes int     `env:"IMPRESSION_EXPIRES_MINUTES" env-default:"60"`
+	ClickWindowMins   int     `env:"AD_CLICK_WINDOW_MINUTES" env-default:"1440"`
+	ClicksQueue       int     `env:"CLICKS_QUEUE_SIZE" env-default:"10000"`
+	ClicksBatch       int     `env:"CLICKS_BATCH_SIZE" env-default:"100"`
+	ClicksFlush       int     `env:"CLICKS_FLUSH_SECONDS" env-default:"5"`
+	SweepMinutes      int     `env:"SWEEP_INTERVAL_MINUTES" env-default:"10"`
+	PasswordCost      int     `env:"PASSWORD_COST" env-default:"10"`
+	Attempts          int     `env:"LINK_PASSWORD_ATTEMPTS" env-default:"5"`
+	AttemptsMins      int     `env:"LINK_PASSWORD_WINDOW_MINUTES" env-default:"15"`
+	Epsilon           float64 `env:"BANDIT_EPSILON" env-default:"0.1"`
+	EventsPoll        int     `env:"USER_EVENTS_POLL_SECONDS" env-default:"30"`
+	EventsBatch       int     `env:"USER_EVENTS_BATCH_SIZE" env-default:"100"`
 }
 
 func main() {
@@ -33,16 +58,21 @@ func main() {
 
 	eg, ctx := errgroup.WithContext(context.Background())
 
-	conn, err := pgx.Connect(ctx, cfg.PostgresConn)
+	// the pool is shared by the server and background workers, a single connection cannot be used concurrently
+	pool, err := pgxpool.New(ctx, cfg.PostgresConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+	err = pool.Ping(ctx)
 	for i := 0; i < 5 && err != nil; i++ {
 		time.Sleep(time.Second * 3)
 		fmt.Printf("Reconnect to PostgreSQL #%d", i+1)
-		conn, err = pgx.Connect(ctx, cfg.PostgresConn)
+		err = pool.Ping(ctx)
 	}
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() { _ = conn.Close(ctx) }()
 	adsConn, err := grpc.DialContext(ctx, cfg.AdsPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	for i := 0; i < 10 && err != nil; i++ {
 		time.Sleep(time.Second * 3)
@@ -53,13 +83,41 @@ func main() {
 		log.Fatalf("Cannot start connection with Ads: %v", err)
 	}
 	adsSvc := adProto.NewAdServiceClient(adsConn)
+	authConn, err := grpc.DialContext(ctx, cfg.AuthPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	for i := 0; i < 10 && err != nil; i++ {
+		time.Sleep(time.Second * 3)
+		fmt.Printf("Reconnect to Auth #%d", i+1)
+		authConn, err = grpc.DialContext(ctx, cfg.AuthPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if err != nil {
+		log.Fatalf("Cannot start connection with Auth: %v", err)
+	}
 
-	repo := pgrepo.New(conn)
-	a := app.New(repo, generator.New(repo), ads.New(adsSvc))
+	repo := pgrepo.New(pool)
+	recorder := analytics.New(
+		repo, cfg.ClicksSalt, cfg.ClicksQueue, cfg.ClicksBatch, time.Duration(cfg.ClicksFlush)*time.Second,
+	)
+	attempts := limiter.New(cfg.Attempts, time.Duration(cfg.AttemptsMins)*time.Minute)
+	selectors := map[string]app.AdSelector{
+		links.StrategyWeighted:   adSelectors.NewWeighted(),
+		links.StrategyRoundRobin: adSelectors.NewRoundRobin(),
+		links.StrategyBandit:     adSelectors.NewBandit(repo, cfg.Epsilon),
+	}
+	signer := impressions.New([]byte(cfg.ImpressionSecret), time.Duration(cfg.ImpressionMinutes)*time.Minute)
+	a := app.New(
+		repo, generator.New(repo), ads.New(adsSvc), recorder, passwords.New(cfg.PasswordCost), attempts, selectors,
+		signer, time.Duration(cfg.ClickWindowMins)*time.Minute,
+	)
 
 	grpcServer := grpcPort.NewServer(cfg.GRPCAddress, a)
 
-	shutdown.Gracefully(eg, ctx, grpcServer)
+	sw := sweeper.New(repo, time.Duration(cfg.SweepMinutes)*time.Minute)
+	consumer := userevents.New(
+		"urlshortener", userevents.NewAuthSource(authProto.NewAuthServiceClient(authConn)), repo, a,
+		time.Duration(cfg.EventsPoll)*time.Second, cfg.EventsBatch,
+	)
+
+	shutdown.Gracefully(eg, ctx, grpcServer, recorder, sw, consumer)
 
 	if err := eg.Wait(); err != nil {
 		log.Println("Graceful shutdown server:", err)
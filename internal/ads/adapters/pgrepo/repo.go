@@ -6,30 +6,74 @@ import (
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"goads/internal/ads/ads"
 	"goads/internal/ads/app"
+	"goads/internal/ads/campaigns"
 	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/pagination"
+	"html"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Repo struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
 const (
-	constrAuthorID = "ads_author_id_key"
+	constrAuthorID         = "ads_author_id_key"
+	constrCampaignID       = "ads_campaign_id_key"
+	constrCampaignAuthorID = "campaigns_author_id_key"
 )
 
+// searchConfig is text search configuration of ads which stems both English and Russian words
+const searchConfig = "ads_search"
+
+// matchStart and matchStop are private use characters wrapping matches of the search query in snippets.
+// They are removed from the text before highlighting, so only the database can place them
+const (
+	matchStart = "\ue000"
+	matchStop  = "\ue001"
+)
+
+// headlineOptions wraps matches of the search query in snippets by matchStart and matchStop
+const headlineOptions = `StartSel="` + matchStart + `", StopSel="` + matchStop + `", MaxFragments=2, MaxWords=30, MinWords=10`
+
+var snippetMarker = strings.NewReplacer(matchStart, "<mark>", matchStop, "</mark>")
+
+// markSnippet escapes HTML of the text of ad and wraps matches in snippet by <mark> tags,
+// so the snippet can be rendered as HTML safely
+func markSnippet(snippet string) string {
+	return snippetMarker.Replace(html.EscapeString(snippet))
+}
+
+// campaignRunning is the condition on the campaign c to be running and not exhausted at the moment $2
+const campaignRunning = `
+	(c.start_date IS NULL OR c.start_date <= $2::timestamp) AND (c.end_date IS NULL OR $2::timestamp < c.end_date)
+	AND (c.total_cap = 0 OR c.impressions < c.total_cap)
+	AND (c.daily_cap = 0 OR c.impressions_day <> $2::timestamp::date OR c.day_impressions < c.daily_cap)
+`
+
 func (r Repo) Store(ctx context.Context, ad ads.Ad) (int64, error) {
-	const query = `INSERT INTO ads (author_id, published, title, text, create_date, update_date) 	
-			       VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	const query = `
+		WITH ad AS (
+			INSERT INTO ads (author_id, published, title, text, create_date, update_date, campaign_id,
+			                 status, reject_reason)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), $8, $9) RETURNING id, author_id, title, text
+		)
+		INSERT INTO ad_revisions (ad_id, version, editor_id, title, text, created_at)
+		SELECT id, 1, author_id, title, text, $10 FROM ad
+		RETURNING ad_id`
 	const op = "pgrepo.Store"
 
 	var id int64 = -1
 	err := r.db.QueryRow(
 		ctx, query,
 		ad.AuthorID, ad.Published, ad.Title,
-		ad.Text, ad.GetCreateDate(), ad.GetUpdateDate(),
+		ad.Text, ad.GetCreateDate(), ad.GetUpdateDate(), ad.CampaignID, ad.Status, ad.RejectReason, ad.UpdateDate,
 	).Scan(&id)
 
 	if err != nil {
@@ -44,13 +88,17 @@ func (r Repo) Store(ctx context.Context, ad ads.Ad) (int64, error) {
 }
 
 func (r Repo) GetByID(ctx context.Context, id int64) (ads.Ad, error) {
-	const query = `SELECT id, author_id, published, title, text, create_date, update_date 
+	const query = `SELECT id, author_id, published, title, text, create_date, update_date, COALESCE(campaign_id, 0),
+                          status, reject_reason
                    FROM ads WHERE id=$1`
 	const op = "pgrepo.GetByID"
 
 	var ad ads.Ad
 	err := r.db.QueryRow(ctx, query, id).
-		Scan(&ad.ID, &ad.AuthorID, &ad.Published, &ad.Title, &ad.Text, &ad.CreateDate, &ad.UpdateDate)
+		Scan(
+			&ad.ID, &ad.AuthorID, &ad.Published, &ad.Title, &ad.Text, &ad.CreateDate, &ad.UpdateDate, &ad.CampaignID,
+			&ad.Status, &ad.RejectReason,
+		)
 	if errors.Is(err, pgx.ErrNoRows) {
 		err = errwrap.New(app.ErrAdNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("ad", id)
 	} else if err != nil {
@@ -59,9 +107,16 @@ func (r Repo) GetByID(ctx context.Context, id int64) (ads.Ad, error) {
 	return ad, err
 }
 
-func (r Repo) GetOnlyPublished(ctx context.Context, ids []int64) (res []ads.Ad, err error) {
-	const query = `SELECT id, author_id, published, title, text, create_date, update_date 
-			  FROM ads WHERE published = true AND id = ANY($1)`
+// GetOnlyPublished returns published ads with given ids, which campaigns are running and not exhausted at the moment.
+// Only approved ads can be published, so unreviewed ads are never returned
+func (r Repo) GetOnlyPublished(ctx context.Context, ids []int64, moment time.Time) (res []ads.Ad, err error) {
+	const query = `
+		SELECT a.id, a.author_id, a.published, a.title, a.text, a.create_date, a.update_date,
+		       COALESCE(a.campaign_id, 0), a.status, a.reject_reason
+		FROM ads a
+			LEFT JOIN campaigns c ON c.id = a.campaign_id
+		WHERE a.status = 'published' AND a.id = ANY($1) AND (c.id IS NULL OR (` + campaignRunning + `))
+	`
 	const op = "pgrepo.GetOnlyPublished"
 
 	defer func() {
@@ -70,7 +125,7 @@ func (r Repo) GetOnlyPublished(ctx context.Context, ids []int64) (res []ads.Ad,
 		}
 	}()
 
-	rows, err := r.db.Query(ctx, query, ids)
+	rows, err := r.db.Query(ctx, query, ids, moment)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = errwrap.New(app.ErrAdNotFound, app.ServiceName, op).WithDetails(err.Error())
@@ -83,7 +138,10 @@ func (r Repo) GetOnlyPublished(ctx context.Context, ids []int64) (res []ads.Ad,
 
 	for rows.Next() {
 		var ad ads.Ad
-		err = rows.Scan(&ad.ID, &ad.AuthorID, &ad.Published, &ad.Title, &ad.Text, &ad.CreateDate, &ad.UpdateDate)
+		err = rows.Scan(
+			&ad.ID, &ad.AuthorID, &ad.Published, &ad.Title, &ad.Text, &ad.CreateDate, &ad.UpdateDate, &ad.CampaignID,
+			&ad.Status, &ad.RejectReason,
+		)
 		if err != nil {
 			err = errwrap.New(err, app.ServiceName, op)
 			return
@@ -93,85 +151,272 @@ func (r Repo) GetOnlyPublished(ctx context.Context, ids []int64) (res []ads.Ad,
 	return
 }
 
-func (r Repo) GetFiltered(ctx context.Context, filter app.Filter) ([]ads.Ad, error) {
-	const op = "pgrepo.GetFiltered"
+// GetMissing returns given ids of ads which do not exist
+func (r Repo) GetMissing(ctx context.Context, ids []int64) ([]int64, error) {
+	const query = `
+		SELECT g.id
+		FROM unnest($1::BIGINT[]) AS g(id)
+		WHERE NOT EXISTS (SELECT 1 FROM ads a WHERE a.id = g.id)
+	`
+	const op = "pgrepo.GetMissing"
 
-	// query forms dynamically
-	query := "SELECT id, author_id, published, title, text, create_date, update_date FROM ads"
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("given ads: %v", ids))
+	}
+	missing, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("given ads: %v", ids))
+	}
+	return missing, nil
+}
 
-	where := false
-	if !filter.All {
-		query += " WHERE published=true"
-		where = true
+// sortKeys are SQL expressions of sort keys of ads with their types
+var sortKeys = map[string][2]string{
+	pagination.SortCreated:   {"create_date", "date"},
+	pagination.SortUpdated:   {"update_date", "date"},
+	pagination.SortTitle:     {"title", "text"},
+	pagination.SortRelevance: {"ts_rank_cd(search, q)", "real"},
+}
+
+// GetFiltered returns the page of ads by keyset pagination: the next page starts after the sort key and ID of
+// the last ad, so pages are not shifted by inserted ads and are selected by index without OFFSET.
+// Values of the filter are passed only as bind parameters
+func (r Repo) GetFiltered(ctx context.Context, filter app.Filter, page pagination.Page) ([]ads.Ad, pagination.Info, error) {
+	const op = "pgrepo.GetFiltered"
+
+	var info pagination.Info
+	var where []string
+	var args []any
+	// arg adds the argument of the query and returns its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
 	}
-	if filter.AuthorID != -1 {
-		if !where {
-			query += " WHERE "
-			where = true
-		} else {
-			query += " AND "
+	if filter.Published != nil {
+		where = append(where, "published="+arg(*filter.Published))
+	}
+	if len(filter.Statuses) > 0 {
+		where = append(where, "status=ANY("+arg(filter.Statuses)+"::text[])")
+	}
+	if len(filter.AuthorIDs) > 0 {
+		where = append(where, "author_id=ANY("+arg(filter.AuthorIDs)+"::bigint[])")
+	}
+	// dateRange adds inclusive bounds of the date column, which are compared by days
+	dateRange := func(column string, from, to time.Time) {
+		if !from.IsZero() {
+			where = append(where, column+">="+arg(from)+"::timestamp::date")
+		}
+		if !to.IsZero() {
+			where = append(where, column+"<="+arg(to)+"::timestamp::date")
 		}
-		// %d cannot create sql injection
-		query += fmt.Sprintf("author_id=%d", filter.AuthorID)
 	}
-	if !filter.Date.IsZero() && filter.Date.UnixMilli() != 0 {
-		if !where {
-			query += " WHERE "
-			where = true
-		} else {
-			query += " AND "
+	dateRange("create_date", filter.CreatedFrom, filter.CreatedTo)
+	dateRange("update_date", filter.UpdatedFrom, filter.UpdatedTo)
+	from, snippet := "ads", "''"
+	if filter.Query != "" {
+		config := arg(searchConfig) + "::regconfig"
+		from += ", websearch_to_tsquery(" + config + ", " + arg(filter.Query) + ") q"
+		text := "translate(text, " + arg(matchStart+matchStop) + ", '')"
+		snippet = "ts_headline(" + config + ", " + text + ", q, " + arg(headlineOptions) + ")"
+		where = append(where, "search @@ q")
+	}
+	if page.Total {
+		query := `SELECT COUNT(*) FROM ` + from + whereClause(where)
+		if err := r.db.QueryRow(ctx, query, args...).Scan(&info.Total); err != nil {
+			return nil, info, errwrap.New(err, app.ServiceName, op)
 		}
-		// %d cannot create sql injection
-		d := filter.Date.Truncate(time.Hour * 24)
-		query += fmt.Sprintf("create_date='%d-%d-%d'", d.Year(), d.Month(), d.Day())
 	}
-	if !where {
-		query += " WHERE "
-	} else {
-		query += " AND "
+
+	name, desc := page.Key()
+	key, ok := sortKeys[name]
+	if !ok {
+		return nil, info, errwrap.New(app.ErrInvalidFilter, app.ServiceName, op).
+			WithDetails(fmt.Sprintf("unknown sort: %s", page.Sort))
 	}
-	query += "title LIKE '%' || $1 || '%'"
-	rows, err := r.db.Query(ctx, query, filter.Title)
+	cursor, err := page.Cursor()
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			err = errwrap.New(app.ErrAdNotFound, app.ServiceName, op).WithDetails(err.Error())
-		} else {
-			err = errwrap.New(err, app.ServiceName, op)
-		}
-		return nil, err
+		return nil, info, errwrap.New(errors.Join(app.ErrInvalidFilter, err), app.ServiceName, op)
+	}
+	after, order := pagination.Keyset(key[0], key[1], "id", desc, cursor, arg)
+	if after != "" {
+		where = append(where, after)
+	}
+	query := `SELECT id, author_id, published, title, text, create_date, update_date, COALESCE(campaign_id, 0), ` +
+		`status, reject_reason, ` +
+		snippet + `, ` + key[0] + `::text FROM ` + from + whereClause(where) +
+		` ORDER BY ` + order + ` LIMIT ` + arg(page.Size+1)
 
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, info, errwrap.New(err, app.ServiceName, op)
 	}
 	defer rows.Close()
 
-	var res []ads.Ad
+	res := make([]ads.Ad, 0, page.Size)
+	var last pagination.Cursor
 	for rows.Next() {
 		ad := ads.Ad{}
-		err := rows.Scan(&ad.ID, &ad.AuthorID, &ad.Published, &ad.Title, &ad.Text, &ad.CreateDate, &ad.UpdateDate)
+		var sortKey string
+		err := rows.Scan(
+			&ad.ID, &ad.AuthorID, &ad.Published, &ad.Title, &ad.Text, &ad.CreateDate, &ad.UpdateDate, &ad.CampaignID,
+			&ad.Status, &ad.RejectReason, &ad.Snippet, &sortKey,
+		)
 		if err != nil {
-			return res, errwrap.New(err, app.ServiceName, op)
+			return res, info, errwrap.New(err, app.ServiceName, op)
+		}
+		if len(res) == page.Size {
+			info.NextToken = last.Encode()
+			break
+		}
+		if filter.Query != "" {
+			ad.Snippet = markSnippet(ad.Snippet)
 		}
+		last = pagination.Cursor{Sort: page.Sort, Key: sortKey, ID: ad.ID}
 		res = append(res, ad)
 	}
-	return res, nil
+	if rows.Err() != nil {
+		return res, info, errwrap.New(rows.Err(), app.ServiceName, op)
+	}
+	return res, info, nil
+}
+
+// whereClause joins conditions by AND
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
 }
 
 func (r Repo) Update(ctx context.Context, ad ads.Ad) error {
-	const query = `UPDATE ads SET author_id=$1, published=$2, title=$3, text=$4, create_date=$5, update_date=$6 
+	const query = `UPDATE ads SET author_id=$1, published=$2, title=$3, text=$4, create_date=$5, update_date=$6,
+                   campaign_id=NULLIF($8, 0), status=$9, reject_reason=$10
                    WHERE id=$7`
 	const op = "pgrepo.Update"
 
 	_, err := r.db.Exec(
 		ctx, query,
-		ad.AuthorID, ad.Published, ad.Title, ad.Text, ad.GetCreateDate(), ad.GetUpdateDate(), ad.ID,
+		ad.AuthorID, ad.Published, ad.Title, ad.Text, ad.GetCreateDate(), ad.GetUpdateDate(), ad.ID, ad.CampaignID,
+		ad.Status, ad.RejectReason,
 	)
+	var pgErr *pgconn.PgError
 	if errors.Is(err, pgx.ErrNoRows) {
 		err = errwrap.New(app.ErrAdNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("ad", ad.ID)
+	} else if errors.As(err, &pgErr) && pgErr.ConstraintName == constrCampaignID {
+		err = errwrap.New(app.ErrCampaignNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("ad", ad.ID)
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("ad", ad.ID)
+	}
+	return err
+}
+
+// UpdateRevised updates the ad and inserts the revision with the next version in one statement.
+// The version is incremented in the locked row of the ad, so concurrent edits get distinct versions
+func (r Repo) UpdateRevised(ctx context.Context, ad ads.Ad, revision ads.Revision) error {
+	const query = `
+		WITH ad AS (
+			UPDATE ads SET author_id=$1, published=$2, title=$3, text=$4, create_date=$5, update_date=$6,
+			               campaign_id=NULLIF($8, 0), status=$9, reject_reason=$10, version=version + 1
+			WHERE id=$7 RETURNING id, version
+		)
+		INSERT INTO ad_revisions (ad_id, version, editor_id, title, text, created_at)
+		SELECT ad.id, ad.version, $11, $12, $13, $14
+		FROM ad`
+	const op = "pgrepo.UpdateRevised"
+
+	tag, err := r.db.Exec(
+		ctx, query,
+		ad.AuthorID, ad.Published, ad.Title, ad.Text, ad.GetCreateDate(), ad.GetUpdateDate(), ad.ID, ad.CampaignID,
+		ad.Status, ad.RejectReason,
+		revision.EditorID, revision.Title, revision.Text, revision.CreateDate,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == constrCampaignID {
+		err = errwrap.New(app.ErrCampaignNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("ad", ad.ID)
 	} else if err != nil {
 		err = errwrap.New(err, app.ServiceName, op).OnObject("ad", ad.ID)
+	} else if tag.RowsAffected() == 0 {
+		err = errwrap.New(app.ErrAdNotFound, app.ServiceName, op).OnObject("ad", ad.ID)
 	}
 	return err
 }
 
+// GetRevisions returns the page of revisions of the ad by keyset pagination on their versions
+func (r Repo) GetRevisions(ctx context.Context, adID int64, page pagination.Page) ([]ads.Revision, pagination.Info, error) {
+	const op = "pgrepo.GetRevisions"
+
+	var info pagination.Info
+	var args []any
+	// arg adds the argument of the query and returns its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := "ad_id=" + arg(adID)
+	if page.Total {
+		err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM ad_revisions WHERE `+where, args...).Scan(&info.Total)
+		if err != nil {
+			return nil, info, errwrap.New(err, app.ServiceName, op).OnObject("ad", adID)
+		}
+	}
+	cursor, err := page.Cursor()
+	if err != nil {
+		return nil, info, errwrap.New(errors.Join(app.ErrInvalidFilter, err), app.ServiceName, op)
+	}
+	_, desc := page.Key()
+	after, order := pagination.Keyset("version", "integer", "id", desc, cursor, arg)
+	if after != "" {
+		where += " AND " + after
+	}
+	query := `SELECT id, version, editor_id, title, text, created_at FROM ad_revisions WHERE ` + where +
+		` ORDER BY ` + order + ` LIMIT ` + arg(page.Size+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, info, errwrap.New(err, app.ServiceName, op).OnObject("ad", adID)
+	}
+	defer rows.Close()
+	res := make([]ads.Revision, 0, page.Size)
+	var last pagination.Cursor
+	for rows.Next() {
+		revision := ads.Revision{AdID: adID}
+		var id int64
+		err := rows.Scan(
+			&id, &revision.Version, &revision.EditorID, &revision.Title, &revision.Text, &revision.CreateDate,
+		)
+		if err != nil {
+			return res, info, errwrap.New(err, app.ServiceName, op).OnObject("ad", adID)
+		}
+		if len(res) == page.Size {
+			info.NextToken = last.Encode()
+			break
+		}
+		last = pagination.Cursor{Sort: page.Sort, Key: strconv.Itoa(revision.Version), ID: id}
+		res = append(res, revision)
+	}
+	if rows.Err() != nil {
+		return res, info, errwrap.New(rows.Err(), app.ServiceName, op).OnObject("ad", adID)
+	}
+	return res, info, nil
+}
+
+func (r Repo) GetRevision(ctx context.Context, adID int64, version int) (ads.Revision, error) {
+	const query = `SELECT editor_id, title, text, created_at FROM ad_revisions WHERE ad_id=$1 AND version=$2`
+	const op = "pgrepo.GetRevision"
+
+	revision := ads.Revision{AdID: adID, Version: version}
+	err := r.db.QueryRow(ctx, query, adID, version).
+		Scan(&revision.EditorID, &revision.Title, &revision.Text, &revision.CreateDate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errwrap.New(app.ErrRevisionNotFound, app.ServiceName, op).OnObject("ad", adID).
+			WithDetails(fmt.Sprintf("%v | version: %d", err, version))
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("ad", adID)
+	}
+	return revision, err
+}
+
 func (r Repo) Delete(ctx context.Context, id int64) error {
 	const query = `DELETE FROM ads WHERE id=$1`
 	const op = "pgrepo.Delete"
@@ -186,6 +431,141 @@ func (r Repo) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
-func New(conn *pgx.Conn) Repo {
+// nullTime converts zero time to NULL
+func nullTime(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: !t.IsZero()}
+}
+
+func (r Repo) StoreCampaign(ctx context.Context, campaign campaigns.Campaign) (int64, error) {
+	const query = `INSERT INTO campaigns (author_id, name, start_date, end_date, total_cap, daily_cap)
+			       VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	const op = "pgrepo.StoreCampaign"
+
+	var id int64 = -1
+	err := r.db.QueryRow(
+		ctx, query,
+		campaign.AuthorID, campaign.Name, nullTime(campaign.StartDate), nullTime(campaign.EndDate),
+		campaign.TotalCap, campaign.DailyCap,
+	).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == constrCampaignAuthorID {
+			err = errwrap.New(app.ErrAuthorNotFound, app.ServiceName, op).WithDetails(err.Error())
+		} else {
+			err = errwrap.New(err, app.ServiceName, op)
+		}
+	}
+	return id, err
+}
+
+func (r Repo) GetCampaignByID(ctx context.Context, id int64) (campaigns.Campaign, error) {
+	const query = `SELECT id, author_id, name, start_date, end_date, total_cap, daily_cap,
+                          impressions, impressions_day, day_impressions
+                   FROM campaigns WHERE id=$1`
+	const op = "pgrepo.GetCampaignByID"
+
+	var c campaigns.Campaign
+	var start, end, day pgtype.Timestamp
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&c.ID, &c.AuthorID, &c.Name, &start, &end, &c.TotalCap, &c.DailyCap,
+		&c.Impressions, &day, &c.DayImpressions,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errwrap.New(app.ErrCampaignNotFound, app.ServiceName, op).WithDetails(err.Error()).
+			OnObject("campaign", id)
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("campaign", id)
+	}
+	c.StartDate, c.EndDate, c.Day = start.Time, end.Time, day.Time
+	return c, err
+}
+
+func (r Repo) DeleteCampaign(ctx context.Context, id int64) error {
+	const query = `DELETE FROM campaigns WHERE id=$1`
+	const op = "pgrepo.DeleteCampaign"
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("campaign", id)
+	}
+	return err
+}
+
+// CountImpression increments impressions of the ad's campaign by one statement, so the caps cannot be exceeded
+// by concurrent impressions. Returns false if the campaign is exhausted or out of schedule
+func (r Repo) CountImpression(ctx context.Context, adID int64, moment time.Time) (bool, error) {
+	const query = `
+		UPDATE campaigns c
+		SET impressions     = c.impressions + 1,
+		    day_impressions = CASE WHEN c.impressions_day = $2::timestamp::date THEN c.day_impressions + 1 ELSE 1 END,
+		    impressions_day = $2::timestamp::date
+		WHERE c.id = (SELECT campaign_id FROM ads WHERE id=$1) AND ` + campaignRunning + `
+	`
+	const campaignQuery = `SELECT campaign_id IS NOT NULL FROM ads WHERE id=$1`
+	const op = "pgrepo.CountImpression"
+
+	tag, err := r.db.Exec(ctx, query, adID, moment)
+	if err != nil {
+		return false, errwrap.New(err, app.ServiceName, op).OnObject("ad", adID)
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+	var inCampaign bool
+	err = r.db.QueryRow(ctx, campaignQuery, adID).Scan(&inCampaign)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, errwrap.New(app.ErrAdNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("ad", adID)
+	} else if err != nil {
+		return false, errwrap.New(err, app.ServiceName, op).OnObject("ad", adID)
+	}
+	return !inCampaign, nil
+}
+
+// DeleteByAuthor removes ads and campaigns of the author in one transaction
+func (r Repo) DeleteByAuthor(ctx context.Context, authorID int64) error {
+	const adsQuery = `DELETE FROM ads WHERE author_id=$1`
+	const campaignsQuery = `DELETE FROM campaigns WHERE author_id=$1`
+	const op = "pgrepo.DeleteByAuthor"
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, adsQuery, authorID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, campaignsQuery, authorID)
+		return err
+	})
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("author", authorID)
+	}
+	return err
+}
+
+func (r Repo) GetEventCursor(ctx context.Context, consumer string) (int64, error) {
+	const query = `SELECT last_event_id FROM event_cursors WHERE consumer=$1`
+	const op = "pgrepo.GetEventCursor"
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, consumer).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("consumer: %s", consumer))
+	}
+	return id, nil
+}
+
+func (r Repo) SetEventCursor(ctx context.Context, consumer string, id int64) error {
+	const query = `INSERT INTO event_cursors (consumer, last_event_id) VALUES ($1, $2)
+		ON CONFLICT (consumer) DO UPDATE SET last_event_id=EXCLUDED.last_event_id`
+	const op = "pgrepo.SetEventCursor"
+
+	_, err := r.db.Exec(ctx, query, consumer, id)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("consumer: %s, event: %d", consumer, id))
+	}
+	return err
+}
+
+func New(conn *pgxpool.Pool) Repo {
 	return Repo{db: conn}
 }
@@ -0,0 +1,12 @@
+package pgrepo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMarkSnippet(t *testing.T) {
+	got := markSnippet("<script>alert(1)</script> " + matchStart + "red" + matchStop + " bicycle & <b>bell</b>")
+	assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt; <mark>red</mark> bicycle &amp; &lt;b&gt;bell&lt;/b&gt;", got)
+	assert.Equal(t, "no matches", markSnippet("no matches"))
+}
@@ -5,23 +5,31 @@ import (
 	"time"
 )
 
+// Ad is an advertisement. CampaignID is 0 if the ad is not in a campaign. Published is true only for ads
+// in StatusPublished, so it must be changed by SetStatus. RejectReason is set only for rejected ads
 type Ad struct {
-	ID         int64
-	AuthorID   int64
-	Published  bool
-	Title      string `validate:"min:1; max:99"`
-	Text       string `validate:"min:1"`
-	CreateDate time.Time
-	UpdateDate time.Time
+	ID           int64
+	AuthorID     int64
+	Published    bool
+	Status       string
+	RejectReason string
+	Title        string `validate:"min:1; max:99"`
+	Text         string `validate:"min:1"`
+	CreateDate   time.Time
+	UpdateDate   time.Time
+	CampaignID   int64
+	// Snippet is HTML of a fragment of the escaped text with matches of the search query wrapped by <mark> tags.
+	// It is set only by search
+	Snippet string
 }
 
 func (a Ad) String() string {
 	return fmt.Sprintf(
-		"<Ad id=%d authorID=%d created=%s published=%v title=`%s` text=`%s`>",
+		"<Ad id=%d authorID=%d created=%s status=%s title=`%s` text=`%s`>",
 		a.ID,
 		a.AuthorID,
 		a.CreateDate,
-		a.Published,
+		a.Status,
 		a.Title,
 		a.Text,
 	)
@@ -42,6 +50,6 @@ func New(title string, text string, authorID int64) Ad {
 		Text:       text,
 		CreateDate: time.Now().UTC(),
 		UpdateDate: time.Now().UTC(),
-		Published:  true,
+		Status:     StatusDraft,
 	}
 }
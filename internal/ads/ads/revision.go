@@ -0,0 +1,25 @@
+package ads
+
+import "time"
+
+// Revision is an immutable version of the ad's content. Versions of the ad start from 1, EditorID is the user
+// who changed the content
+type Revision struct {
+	AdID       int64
+	Version    int
+	EditorID   int64
+	Title      string
+	Text       string
+	CreateDate time.Time
+}
+
+// NewRevision returns the revision of the current content of the ad. Its version is assigned by the repository
+func NewRevision(ad Ad, editorID int64) Revision {
+	return Revision{
+		AdID:       ad.ID,
+		EditorID:   editorID,
+		Title:      ad.Title,
+		Text:       ad.Text,
+		CreateDate: ad.UpdateDate,
+	}
+}
@@ -0,0 +1,43 @@
+package ads
+
+// Statuses of the moderation. A new ad is a draft which is submitted for review by its author. Moderators approve
+// or reject pending ads, approved ads are published and paused by their authors. Changed content of a reviewed ad
+// is reviewed again. Archived ads cannot be changed
+const (
+	StatusDraft     = "draft"
+	StatusPending   = "pending"
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusPublished = "published"
+	StatusPaused    = "paused"
+	StatusArchived  = "archived"
+)
+
+// transitions are statuses which the ad can be moved to from the status
+var transitions = map[string][]string{
+	StatusDraft:     {StatusPending, StatusArchived},
+	StatusPending:   {StatusApproved, StatusRejected, StatusArchived},
+	StatusApproved:  {StatusPublished, StatusPending, StatusArchived},
+	StatusRejected:  {StatusDraft, StatusPending, StatusArchived},
+	StatusPublished: {StatusPaused, StatusPending, StatusArchived},
+	StatusPaused:    {StatusPublished, StatusPending, StatusArchived},
+}
+
+// CanTransit returns true if the ad can be moved to the status
+func (a Ad) CanTransit(status string) bool {
+	for _, s := range transitions[a.Status] {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStatus sets the status and publication of the ad without checking the transition
+func (a *Ad) SetStatus(status string) {
+	a.Status = status
+	a.Published = status == StatusPublished
+	if status != StatusRejected {
+		a.RejectReason = ""
+	}
+}
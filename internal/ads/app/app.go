@@ -6,29 +6,74 @@ import (
 	"fmt"
 	"github.com/ormequ/validator"
 	"goads/internal/ads/ads"
+	"goads/internal/ads/campaigns"
 	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/pagination"
+	"goads/internal/pkg/permissions"
 	"time"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Repository
 type Repository interface {
+	// Store saves the ad with its content as the first revision
 	Store(ctx context.Context, ad ads.Ad) (int64, error)
 	GetByID(ctx context.Context, id int64) (ads.Ad, error)
-	GetFiltered(ctx context.Context, filter Filter) ([]ads.Ad, error)
+	// GetFiltered returns the page of ads satisfying filter. The page must be normalized
+	GetFiltered(ctx context.Context, filter Filter, page pagination.Page) ([]ads.Ad, pagination.Info, error)
 	Update(ctx context.Context, ad ads.Ad) error
 	Delete(ctx context.Context, id int64) error
-	GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error)
+	GetOnlyPublished(ctx context.Context, ids []int64, moment time.Time) ([]ads.Ad, error)
+	// GetMissing returns given ids of ads which do not exist
+	GetMissing(ctx context.Context, ids []int64) ([]int64, error)
+	StoreCampaign(ctx context.Context, campaign campaigns.Campaign) (int64, error)
+	GetCampaignByID(ctx context.Context, id int64) (campaigns.Campaign, error)
+	DeleteCampaign(ctx context.Context, id int64) error
+	CountImpression(ctx context.Context, adID int64, moment time.Time) (bool, error)
+	// DeleteByAuthor removes all ads and campaigns of the author
+	DeleteByAuthor(ctx context.Context, authorID int64) error
+	// UpdateRevised updates the ad and saves the revision of its content as the next version at once
+	UpdateRevised(ctx context.Context, ad ads.Ad, revision ads.Revision) error
+	// GetRevisions returns the page of revisions of the ad. The page must be normalized
+	GetRevisions(ctx context.Context, adID int64, page pagination.Page) ([]ads.Revision, pagination.Info, error)
+	GetRevision(ctx context.Context, adID int64, version int) (ads.Revision, error)
 }
 
+// Filter of ads. Empty AuthorIDs mean any author and nil Published means both published and unpublished ads.
+// Empty Statuses mean any status of the moderation.
+// Dates are inclusive bounds of creation and update days, zero dates are unbounded. Query is a full-text
+// search query in web search syntax, matched ads are ordered by relevance
 type Filter struct {
-	AuthorID int64
-	Date     time.Time
-	Title    string
-	All      bool
+	AuthorIDs   []int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	Published   *bool
+	Statuses    []string
+	Query       string
 }
 
+// maxFilterAuthors limits the number of authors in the filter
+const maxFilterAuthors = 100
+
+// validate checks that the filter has no more than maxFilterAuthors authors and its date ranges are not empty
+func (f Filter) validate() error {
+	if len(f.AuthorIDs) > maxFilterAuthors {
+		return fmt.Errorf("too many authors: %d, max is %d", len(f.AuthorIDs), maxFilterAuthors)
+	}
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && f.CreatedTo.Before(f.CreatedFrom) {
+		return errors.New("creation range ends before its start")
+	}
+	if !f.UpdatedFrom.IsZero() && !f.UpdatedTo.IsZero() && f.UpdatedTo.Before(f.UpdatedFrom) {
+		return errors.New("update range ends before its start")
+	}
+	return nil
+}
+
+// App of ads. Texts of submitted ads are pre-screened by Screener if it is set
 type App struct {
-	Repo Repository
+	Repo     Repository
+	Screener Screener
 }
 
 // Create creates a new ad with incremented id
@@ -51,79 +96,215 @@ func (a App) GetByID(ctx context.Context, id int64) (ads.Ad, error) {
 	return ad, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) getEditable(ctx context.Context, id int64, userID int64) (ads.Ad, error) {
+// getEditable returns the ad if userID is equal to author id of the ad or the user's role has the permission
+func (a App) getEditable(ctx context.Context, id int64, userID int64, permission permissions.Permission) (ads.Ad, error) {
 	const op = "app.getEditable"
 
 	ad, err := a.Repo.GetByID(ctx, id)
 	if err != nil {
 		return ad, errwrap.JoinWithCaller(err, op)
 	}
-	if ad.AuthorID != userID {
+	if !permissions.Allowed(ctx, userID, ad.AuthorID, permission) {
 		return ad, errwrap.New(ErrPermissionDenied, ServiceName, op).OnObject("ad", ad.ID).
 			WithDetails(fmt.Sprintf("ad created by %d and cannot be changed by %d", ad.AuthorID, userID))
 	}
 	return ad, nil
 }
 
-// change applies function changer for an ad and updates it in the Repo
-func (a App) change(ctx context.Context, id int64, userID int64, changer func(ads.Ad) ads.Ad) (ads.Ad, error) {
+// change applies function changer for an ad and updates it in the Repo. The ad is not updated if changer fails.
+// Changed content is saved as a new revision edited by userID
+func (a App) change(
+	ctx context.Context,
+	id int64,
+	userID int64,
+	permission permissions.Permission,
+	changer func(ads.Ad) (ads.Ad, error),
+) (ads.Ad, error) {
 	const op = "app.change"
 
-	ad, err := a.getEditable(ctx, id, userID)
+	ad, err := a.getEditable(ctx, id, userID, permission)
+	if err != nil {
+		return ad, errwrap.JoinWithCaller(err, op)
+	}
+	newAd, err := changer(ad)
 	if err != nil {
 		return ad, errwrap.JoinWithCaller(err, op)
 	}
-	newAd := changer(ad)
 	err = govalid.Validate(newAd)
 	if err != nil {
 		err = errors.Join(ErrInvalidContent, err)
 		return ad, errwrap.New(err, ServiceName, op)
 	}
 	newAd.UpdateDate = time.Now().UTC()
-	err = a.Repo.Update(ctx, newAd)
+	if newAd.Title != ad.Title || newAd.Text != ad.Text {
+		err = a.Repo.UpdateRevised(ctx, newAd, ads.NewRevision(newAd, userID))
+	} else {
+		err = a.Repo.Update(ctx, newAd)
+	}
 	if err != nil {
 		newAd = ad
 	}
 	return newAd, errwrap.JoinWithCaller(err, op)
 }
 
-// ChangeStatus changes ad's status only if userID is equal to author id of the ad
+// ChangeStatus publishes approved or paused ad and pauses published one only if userID is equal to author id
+// of the ad. Admins can change status of any ad, moderators can only unpublish it
 func (a App) ChangeStatus(ctx context.Context, id int64, userID int64, published bool) (ads.Ad, error) {
 	const op = "app.ChangeStatus"
-	ad, err := a.change(ctx, id, userID, func(ad ads.Ad) ads.Ad {
-		ad.Published = published
-		return ad
+	permission, status := permissions.EditAny, ads.StatusPublished
+	if !published {
+		permission, status = permissions.UnpublishAny, ads.StatusPaused
+	}
+	ad, err := a.change(ctx, id, userID, permission, func(ad ads.Ad) (ads.Ad, error) {
+		return ad, transit(&ad, status)
 	})
 	return ad, errwrap.JoinWithCaller(err, op)
 }
 
-// Update changes ad's content (title and text) only if userID is equal to author id of the ad
+// Update changes ad's content (title and text) only if userID is equal to author id of the ad or the user is admin.
+// Rejected ad becomes a draft again and the ad which has been submitted is submitted again with the new content
 func (a App) Update(ctx context.Context, id int64, userID int64, title string, text string) (ads.Ad, error) {
 	const op = "app.Update"
-	ad, err := a.change(ctx, id, userID, func(ad ads.Ad) ads.Ad {
+	ad, err := a.change(ctx, id, userID, permissions.EditAny, func(ad ads.Ad) (ads.Ad, error) {
 		ad.Title, ad.Text = title, text
-		return ad
+		switch ad.Status {
+		case ads.StatusArchived:
+			return ad, errwrap.New(ErrInvalidStatus, ServiceName, op).OnObject("ad", ad.ID).
+				WithDetails("archived ad cannot be changed")
+		case ads.StatusDraft:
+		case ads.StatusRejected:
+			ad.SetStatus(ads.StatusDraft)
+		default:
+			a.submit(&ad)
+		}
+		return ad, nil
 	})
 	return ad, errwrap.JoinWithCaller(err, op)
 }
 
-// GetFiltered returns list of ads satisfy filter. Usable filters look at /internal/filters
-func (a App) GetFiltered(ctx context.Context, opt Filter) ([]ads.Ad, error) {
+// GetFiltered returns the page of ads satisfying filter. Ads are sorted by creation, update or title,
+// newest first by default. Ads found by the search query can also be sorted by relevance, which is the default
+func (a App) GetFiltered(ctx context.Context, opt Filter, page pagination.Page) ([]ads.Ad, pagination.Info, error) {
 	const op = "app.GetFiltered"
-	list, err := a.Repo.GetFiltered(ctx, opt)
-	return list, errwrap.JoinWithCaller(err, op)
+
+	if err := opt.validate(); err != nil {
+		return nil, pagination.Info{}, errwrap.New(errors.Join(ErrInvalidFilter, err), ServiceName, op)
+	}
+	sorts := []string{"-" + pagination.SortCreated, pagination.SortUpdated, pagination.SortTitle}
+	if opt.Query != "" {
+		sorts = append([]string{"-" + pagination.SortRelevance}, sorts...)
+	}
+	page, err := page.Normalize(sorts...)
+	if err != nil {
+		return nil, pagination.Info{}, errwrap.New(errors.Join(ErrInvalidFilter, err), ServiceName, op)
+	}
+	list, info, err := a.Repo.GetFiltered(ctx, opt, page)
+	return list, info, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error) {
+// GetOnlyPublished returns published ads with given ids. Ads of exhausted or out of schedule campaigns are excluded.
+// Ids of deleted ads are returned as missing, so that their links can be cleaned up
+func (a App) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, []int64, error) {
 	const op = "app.GetOnlyPublished"
-	list, err := a.Repo.GetOnlyPublished(ctx, ids)
-	return list, errwrap.JoinWithCaller(err, op)
+	list, err := a.Repo.GetOnlyPublished(ctx, ids, time.Now().UTC())
+	if err != nil || len(list) == len(ids) {
+		return list, nil, errwrap.JoinWithCaller(err, op)
+	}
+	missing, err := a.Repo.GetMissing(ctx, ids)
+	return list, missing, errwrap.JoinWithCaller(err, op)
 }
 
-// Delete removes ad with got id if userID equals to author ID of the ad
+// CountImpression counts the impression of the ad in its campaign. The impression is not counted and
+// ErrCampaignEnded is returned if the campaign is exhausted or out of schedule. Ads without campaign are
+// always counted
+func (a App) CountImpression(ctx context.Context, adID int64) error {
+	const op = "app.CountImpression"
+	counted, err := a.Repo.CountImpression(ctx, adID, time.Now().UTC())
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if !counted {
+		return errwrap.New(ErrCampaignEnded, ServiceName, op).OnObject("ad", adID)
+	}
+	return nil
+}
+
+// CreateCampaign creates a new campaign. Zero dates mean unlimited schedule and zero caps mean unlimited impressions
+func (a App) CreateCampaign(
+	ctx context.Context,
+	name string,
+	authorID int64,
+	startDate time.Time,
+	endDate time.Time,
+	totalCap int64,
+	dailyCap int64,
+) (campaigns.Campaign, error) {
+	const op = "app.CreateCampaign"
+
+	campaign := campaigns.New(name, authorID, startDate, endDate, totalCap, dailyCap)
+	err := govalid.Validate(campaign)
+	if err != nil {
+		err = errors.Join(ErrInvalidContent, err)
+		return campaign, errwrap.New(err, ServiceName, op)
+	}
+	if totalCap < 0 || dailyCap < 0 {
+		return campaign, errwrap.New(ErrInvalidContent, ServiceName, op).
+			WithDetails(fmt.Sprintf("negative caps: total %d, daily %d", totalCap, dailyCap))
+	}
+	if !startDate.IsZero() && !endDate.IsZero() && !endDate.After(startDate) {
+		return campaign, errwrap.New(ErrInvalidContent, ServiceName, op).
+			WithDetails(fmt.Sprintf("campaign ends at %s before start at %s", endDate, startDate))
+	}
+	campaign.ID, err = a.Repo.StoreCampaign(ctx, campaign)
+	return campaign, errwrap.JoinWithCaller(err, op)
+}
+
+// GetCampaign returns the campaign only if userID is equal to author id of the campaign
+func (a App) GetCampaign(ctx context.Context, id int64, userID int64) (campaigns.Campaign, error) {
+	const op = "app.GetCampaign"
+
+	campaign, err := a.Repo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return campaign, errwrap.JoinWithCaller(err, op)
+	}
+	if campaign.AuthorID != userID {
+		return campaigns.Campaign{}, errwrap.New(ErrPermissionDenied, ServiceName, op).OnObject("campaign", id).
+			WithDetails(fmt.Sprintf("campaign created by %d and cannot be accessed by %d", campaign.AuthorID, userID))
+	}
+	return campaign, nil
+}
+
+// DeleteCampaign removes the campaign if userID equals to author ID of the campaign. Its ads are left without campaign
+func (a App) DeleteCampaign(ctx context.Context, id int64, userID int64) error {
+	const op = "app.DeleteCampaign"
+	_, err := a.GetCampaign(ctx, id, userID)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Repo.DeleteCampaign(ctx, id)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// SetCampaign moves the ad to the campaign. Both of them must be created by userID. Zero campaignID removes
+// the ad from its campaign
+func (a App) SetCampaign(ctx context.Context, id int64, userID int64, campaignID int64) (ads.Ad, error) {
+	const op = "app.SetCampaign"
+	if campaignID != 0 {
+		if _, err := a.GetCampaign(ctx, campaignID, userID); err != nil {
+			return ads.Ad{}, errwrap.JoinWithCaller(err, op)
+		}
+	}
+	ad, err := a.change(ctx, id, userID, permissions.EditAny, func(ad ads.Ad) (ads.Ad, error) {
+		ad.CampaignID = campaignID
+		return ad, nil
+	})
+	return ad, errwrap.JoinWithCaller(err, op)
+}
+
+// Delete removes ad with got id if userID equals to author ID of the ad or the user is admin
 func (a App) Delete(ctx context.Context, id int64, userID int64) error {
 	const op = "app.Delete"
-	_, err := a.getEditable(ctx, id, userID)
+	_, err := a.getEditable(ctx, id, userID, permissions.DeleteAny)
 	if err != nil {
 		return errwrap.JoinWithCaller(err, op)
 	}
@@ -131,13 +312,20 @@ func (a App) Delete(ctx context.Context, id int64, userID int64) error {
 	return errwrap.JoinWithCaller(err, op)
 }
 
-// Search finds ads with prefix equals to title
-func (a App) Search(ctx context.Context, title string) ([]ads.Ad, error) {
+// DeleteUserData removes ads and campaigns of the deleted user. It is called on user events from Auth
+func (a App) DeleteUserData(ctx context.Context, userID int64) error {
+	const op = "app.DeleteUserData"
+	err := a.Repo.DeleteByAuthor(ctx, userID)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// Search returns the first page of ads of any author matching the full-text query ordered by relevance
+func (a App) Search(ctx context.Context, query string) ([]ads.Ad, error) {
 	const op = "app.Search"
-	list, err := a.Repo.GetFiltered(ctx, Filter{Title: title, AuthorID: -1, All: true})
+	list, _, err := a.GetFiltered(ctx, Filter{Query: query}, pagination.Page{})
 	return list, errwrap.JoinWithCaller(err, op)
 }
 
-func New(repo Repository) App {
-	return App{Repo: repo}
+func New(repo Repository, screener Screener) App {
+	return App{Repo: repo, Screener: screener}
 }
@@ -10,6 +10,10 @@ var (
 	ErrAuthorNotFound   = errors.New("author not found")
 	ErrInvalidContent   = errors.New("invalid ad's content")
 	ErrInvalidFilter    = errors.New("invalid ad's filter")
+	ErrCampaignNotFound = errors.New("campaign not found")
+	ErrCampaignEnded    = errors.New("campaign is exhausted or out of schedule")
+	ErrInvalidStatus    = errors.New("ad cannot be moved to this status")
+	ErrRevisionNotFound = errors.New("revision not found")
 )
 
 const ServiceName = "Ads"
@@ -3,10 +3,12 @@
 package mocks
 
 import (
+	context "context"
 	ads "goads/internal/ads/ads"
 	app "goads/internal/ads/app"
-
-	context "context"
+	campaigns "goads/internal/ads/campaigns"
+	pagination "goads/internal/pkg/pagination"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -16,6 +18,30 @@ type Repository struct {
 	mock.Mock
 }
 
+// CountImpression provides a mock function with given fields: ctx, adID, moment
+func (_m *Repository) CountImpression(ctx context.Context, adID int64, moment time.Time) (bool, error) {
+	ret := _m.Called(ctx, adID, moment)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) (bool, error)); ok {
+		return rf(ctx, adID, moment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) bool); ok {
+		r0 = rf(ctx, adID, moment)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time) error); ok {
+		r1 = rf(ctx, adID, moment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, id
 func (_m *Repository) Delete(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
@@ -30,6 +56,34 @@ func (_m *Repository) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
+// DeleteByAuthor provides a mock function with given fields: ctx, authorID
+func (_m *Repository) DeleteByAuthor(ctx context.Context, authorID int64) error {
+	ret := _m.Called(ctx, authorID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, authorID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCampaign provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteCampaign(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *Repository) GetByID(ctx context.Context, id int64) (ads.Ad, error) {
 	ret := _m.Called(ctx, id)
@@ -54,46 +108,77 @@ func (_m *Repository) GetByID(ctx context.Context, id int64) (ads.Ad, error) {
 	return r0, r1
 }
 
-// GetFiltered provides a mock function with given fields: ctx, filter
-func (_m *Repository) GetFiltered(ctx context.Context, filter app.Filter) ([]ads.Ad, error) {
-	ret := _m.Called(ctx, filter)
+// GetCampaignByID provides a mock function with given fields: ctx, id
+func (_m *Repository) GetCampaignByID(ctx context.Context, id int64) (campaigns.Campaign, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 []ads.Ad
+	var r0 campaigns.Campaign
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, app.Filter) ([]ads.Ad, error)); ok {
-		return rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (campaigns.Campaign, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, app.Filter) []ads.Ad); ok {
-		r0 = rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) campaigns.Campaign); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(campaigns.Campaign)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFiltered provides a mock function with given fields: ctx, filter, page
+func (_m *Repository) GetFiltered(ctx context.Context, filter app.Filter, page pagination.Page) ([]ads.Ad, pagination.Info, error) {
+	ret := _m.Called(ctx, filter, page)
+
+	var r0 []ads.Ad
+	var r1 pagination.Info
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.Filter, pagination.Page) ([]ads.Ad, pagination.Info, error)); ok {
+		return rf(ctx, filter, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, app.Filter, pagination.Page) []ads.Ad); ok {
+		r0 = rf(ctx, filter, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, app.Filter) error); ok {
-		r1 = rf(ctx, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, app.Filter, pagination.Page) pagination.Info); ok {
+		r1 = rf(ctx, filter, page)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(pagination.Info)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, app.Filter, pagination.Page) error); ok {
+		r2 = rf(ctx, filter, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// GetOnlyPublished provides a mock function with given fields: ctx, ids
-func (_m *Repository) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error) {
+// GetMissing provides a mock function with given fields: ctx, ids
+func (_m *Repository) GetMissing(ctx context.Context, ids []int64) ([]int64, error) {
 	ret := _m.Called(ctx, ids)
 
-	var r0 []ads.Ad
+	var r0 []int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]ads.Ad, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]int64, error)); ok {
 		return rf(ctx, ids)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []int64) []ads.Ad); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []int64); ok {
 		r0 = rf(ctx, ids)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]ads.Ad)
+			r0 = ret.Get(0).([]int64)
 		}
 	}
 
@@ -106,6 +191,89 @@ func (_m *Repository) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.
 	return r0, r1
 }
 
+// GetOnlyPublished provides a mock function with given fields: ctx, ids, moment
+func (_m *Repository) GetOnlyPublished(ctx context.Context, ids []int64, moment time.Time) ([]ads.Ad, error) {
+	ret := _m.Called(ctx, ids, moment)
+
+	var r0 []ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64, time.Time) ([]ads.Ad, error)); ok {
+		return rf(ctx, ids, moment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64, time.Time) []ads.Ad); ok {
+		r0 = rf(ctx, ids, moment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64, time.Time) error); ok {
+		r1 = rf(ctx, ids, moment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevision provides a mock function with given fields: ctx, adID, version
+func (_m *Repository) GetRevision(ctx context.Context, adID int64, version int) (ads.Revision, error) {
+	ret := _m.Called(ctx, adID, version)
+
+	var r0 ads.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) (ads.Revision, error)); ok {
+		return rf(ctx, adID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ads.Revision); ok {
+		r0 = rf(ctx, adID, version)
+	} else {
+		r0 = ret.Get(0).(ads.Revision)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, adID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevisions provides a mock function with given fields: ctx, adID, page
+func (_m *Repository) GetRevisions(ctx context.Context, adID int64, page pagination.Page) ([]ads.Revision, pagination.Info, error) {
+	ret := _m.Called(ctx, adID, page)
+
+	var r0 []ads.Revision
+	var r1 pagination.Info
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, pagination.Page) ([]ads.Revision, pagination.Info, error)); ok {
+		return rf(ctx, adID, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, pagination.Page) []ads.Revision); ok {
+		r0 = rf(ctx, adID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ads.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, pagination.Page) pagination.Info); ok {
+		r1 = rf(ctx, adID, page)
+	} else {
+		r1 = ret.Get(1).(pagination.Info)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, pagination.Page) error); ok {
+		r2 = rf(ctx, adID, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Store provides a mock function with given fields: ctx, ad
 func (_m *Repository) Store(ctx context.Context, ad ads.Ad) (int64, error) {
 	ret := _m.Called(ctx, ad)
@@ -130,6 +298,30 @@ func (_m *Repository) Store(ctx context.Context, ad ads.Ad) (int64, error) {
 	return r0, r1
 }
 
+// StoreCampaign provides a mock function with given fields: ctx, campaign
+func (_m *Repository) StoreCampaign(ctx context.Context, campaign campaigns.Campaign) (int64, error) {
+	ret := _m.Called(ctx, campaign)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, campaigns.Campaign) (int64, error)); ok {
+		return rf(ctx, campaign)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, campaigns.Campaign) int64); ok {
+		r0 = rf(ctx, campaign)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, campaigns.Campaign) error); ok {
+		r1 = rf(ctx, campaign)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, ad
 func (_m *Repository) Update(ctx context.Context, ad ads.Ad) error {
 	ret := _m.Called(ctx, ad)
@@ -144,6 +336,20 @@ func (_m *Repository) Update(ctx context.Context, ad ads.Ad) error {
 	return r0
 }
 
+// UpdateRevised provides a mock function with given fields: ctx, ad, revision
+func (_m *Repository) UpdateRevised(ctx context.Context, ad ads.Ad, revision ads.Revision) error {
+	ret := _m.Called(ctx, ad, revision)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Ad, ads.Revision) error); ok {
+		r0 = rf(ctx, ad, revision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goads/internal/ads/ads"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/pagination"
+	"goads/internal/pkg/permissions"
+	"strings"
+)
+
+// Screener finds banned words in the text
+type Screener interface {
+	Find(text string) []string
+}
+
+// BannedWordsReason is the reason of ads rejected by pre-screening
+const BannedWordsReason = "banned words"
+
+// transit moves the ad to the status if the transition is allowed
+func transit(ad *ads.Ad, status string) error {
+	const op = "app.transit"
+	if !ad.CanTransit(status) {
+		return errwrap.New(ErrInvalidStatus, ServiceName, op).OnObject("ad", ad.ID).
+			WithDetails(fmt.Sprintf("%s ad cannot be moved to %s", ad.Status, status))
+	}
+	ad.SetStatus(status)
+	return nil
+}
+
+// submit moves the ad to the review. The ad with banned words is rejected without review
+func (a App) submit(ad *ads.Ad) {
+	ad.SetStatus(ads.StatusPending)
+	if a.Screener == nil {
+		return
+	}
+	if words := a.Screener.Find(ad.Title + "\n" + ad.Text); len(words) > 0 {
+		ad.SetStatus(ads.StatusRejected)
+		ad.RejectReason = fmt.Sprintf("%s: %s", BannedWordsReason, strings.Join(words, ", "))
+	}
+}
+
+// Submit sends the draft or rejected ad to the review only if userID is equal to author id of the ad or the user
+// is admin. The ad is rejected at once if it contains banned words
+func (a App) Submit(ctx context.Context, id int64, userID int64) (ads.Ad, error) {
+	const op = "app.Submit"
+	ad, err := a.change(ctx, id, userID, permissions.EditAny, func(ad ads.Ad) (ads.Ad, error) {
+		if ad.Status != ads.StatusDraft && ad.Status != ads.StatusRejected {
+			return ad, errwrap.New(ErrInvalidStatus, ServiceName, op).OnObject("ad", ad.ID).
+				WithDetails(fmt.Sprintf("%s ad cannot be submitted", ad.Status))
+		}
+		a.submit(&ad)
+		return ad, nil
+	})
+	return ad, errwrap.JoinWithCaller(err, op)
+}
+
+// Archive hides the ad forever only if userID is equal to author id of the ad or the user is admin
+func (a App) Archive(ctx context.Context, id int64, userID int64) (ads.Ad, error) {
+	const op = "app.Archive"
+	ad, err := a.change(ctx, id, userID, permissions.EditAny, func(ad ads.Ad) (ads.Ad, error) {
+		return ad, transit(&ad, ads.StatusArchived)
+	})
+	return ad, errwrap.JoinWithCaller(err, op)
+}
+
+// Review approves or rejects the pending ad. The user's role must have permission to moderate ads and moderators
+// cannot review their own ads. Reason is required for rejection
+func (a App) Review(ctx context.Context, id int64, userID int64, approve bool, reason string) (ads.Ad, error) {
+	const op = "app.Review"
+
+	if !permissions.Has(permissions.FromContext(ctx), permissions.ModerateAds) {
+		return ads.Ad{}, errwrap.New(ErrPermissionDenied, ServiceName, op).OnObject("ad", id).
+			WithDetails(fmt.Sprintf("user %d cannot moderate ads", userID))
+	}
+	status, reason := ads.StatusApproved, strings.TrimSpace(reason)
+	if !approve {
+		status = ads.StatusRejected
+		if reason == "" {
+			return ads.Ad{}, errwrap.New(ErrInvalidContent, ServiceName, op).OnObject("ad", id).
+				WithDetails("reason of rejection is required")
+		}
+	}
+	ad, err := a.change(ctx, id, userID, permissions.ModerateAds, func(ad ads.Ad) (ads.Ad, error) {
+		if ad.AuthorID == userID {
+			return ad, errwrap.New(ErrPermissionDenied, ServiceName, op).OnObject("ad", id).
+				WithDetails(fmt.Sprintf("user %d cannot review own ad", userID))
+		}
+		if ad.Status != ads.StatusPending {
+			return ad, errwrap.New(ErrInvalidStatus, ServiceName, op).OnObject("ad", ad.ID).
+				WithDetails(fmt.Sprintf("%s ad cannot be reviewed", ad.Status))
+		}
+		ad.SetStatus(status)
+		if !approve {
+			ad.RejectReason = reason
+		}
+		return ad, nil
+	})
+	return ad, errwrap.JoinWithCaller(err, op)
+}
+
+// GetModerationQueue returns the page of pending ads, which are sorted by update date with the oldest first by
+// default. The user's role must have permission to moderate ads
+func (a App) GetModerationQueue(ctx context.Context, userID int64, page pagination.Page) ([]ads.Ad, pagination.Info, error) {
+	const op = "app.GetModerationQueue"
+
+	if !permissions.Has(permissions.FromContext(ctx), permissions.ModerateAds) {
+		return nil, pagination.Info{}, errwrap.New(ErrPermissionDenied, ServiceName, op).
+			WithDetails(fmt.Sprintf("user %d cannot moderate ads", userID))
+	}
+	page, err := page.Normalize(pagination.SortUpdated, pagination.SortCreated, pagination.SortTitle)
+	if err != nil {
+		return nil, pagination.Info{}, errwrap.New(errors.Join(ErrInvalidFilter, err), ServiceName, op)
+	}
+	list, info, err := a.Repo.GetFiltered(ctx, Filter{Statuses: []string{ads.StatusPending}}, page)
+	return list, info, errwrap.JoinWithCaller(err, op)
+}
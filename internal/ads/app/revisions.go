@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"goads/internal/ads/ads"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/pagination"
+	"goads/internal/pkg/permissions"
+)
+
+// ListRevisions returns the page of revisions of the ad, the newest first by default. The user must be the author
+// of the ad or admin
+func (a App) ListRevisions(ctx context.Context, id int64, userID int64, page pagination.Page) ([]ads.Revision, pagination.Info, error) {
+	const op = "app.ListRevisions"
+
+	if _, err := a.getEditable(ctx, id, userID, permissions.EditAny); err != nil {
+		return nil, pagination.Info{}, errwrap.JoinWithCaller(err, op)
+	}
+	page, err := page.Normalize("-" + pagination.SortVersion)
+	if err != nil {
+		return nil, pagination.Info{}, errwrap.New(errors.Join(ErrInvalidFilter, err), ServiceName, op)
+	}
+	list, info, err := a.Repo.GetRevisions(ctx, id, page)
+	return list, info, errwrap.JoinWithCaller(err, op)
+}
+
+// GetRevision returns the version of the ad. The user must be the author of the ad or admin
+func (a App) GetRevision(ctx context.Context, id int64, userID int64, version int) (ads.Revision, error) {
+	const op = "app.GetRevision"
+
+	if _, err := a.getEditable(ctx, id, userID, permissions.EditAny); err != nil {
+		return ads.Revision{}, errwrap.JoinWithCaller(err, op)
+	}
+	revision, err := a.Repo.GetRevision(ctx, id, version)
+	return revision, errwrap.JoinWithCaller(err, op)
+}
+
+// RestoreRevision updates the ad with content of the version. Revisions are immutable, so the restored content
+// is saved as the newest revision and is reviewed like any other update
+func (a App) RestoreRevision(ctx context.Context, id int64, userID int64, version int) (ads.Ad, error) {
+	const op = "app.RestoreRevision"
+
+	revision, err := a.GetRevision(ctx, id, userID, version)
+	if err != nil {
+		return ads.Ad{}, errwrap.JoinWithCaller(err, op)
+	}
+	ad, err := a.Update(ctx, id, userID, revision.Title, revision.Text)
+	return ad, errwrap.JoinWithCaller(err, op)
+}
@@ -8,6 +8,10 @@ import (
 	"goads/internal/ads/ads"
 	"goads/internal/ads/app"
 	"goads/internal/ads/app/mocks"
+	"goads/internal/ads/campaigns"
+	"goads/internal/ads/screener"
+	"goads/internal/pkg/pagination"
+	"goads/internal/pkg/permissions"
 	"testing"
 	"time"
 )
@@ -29,24 +33,42 @@ func getByIDRepo(t *testing.T) app.Repository {
 }
 
 func getByIDUpdateRepo(t *testing.T) app.Repository {
-	r := mocks.NewRepository(t)
-	r.
-		On("GetByID", mock.Anything, mock.AnythingOfType("int64")).
-		Return(ads.Ad{
+	return getByIDUpdateStatusRepo(ads.StatusPublished)(t)
+}
+
+func getByIDUpdateStatusRepo(status string) func(t *testing.T) app.Repository {
+	return func(t *testing.T) app.Repository {
+		r := mocks.NewRepository(t)
+		ad := ads.Ad{
 			Title: "test title",
 			Text:  "test text",
-		}, nil)
+		}
+		ad.SetStatus(status)
+		r.
+			On("GetByID", mock.Anything, mock.AnythingOfType("int64")).
+			Return(ad, nil)
 
-	r.
-		On("Update", mock.Anything, mock.AnythingOfType("ads.Ad")).
-		Return(func(_ context.Context, ad ads.Ad) error {
-			if ad.Title == "not found" {
-				return app.ErrAdNotFound
-			}
-			return nil
-		})
+		r.
+			On("Update", mock.Anything, mock.AnythingOfType("ads.Ad")).
+			Return(func(_ context.Context, ad ads.Ad) error {
+				if ad.Title == "not found" {
+					return app.ErrAdNotFound
+				}
+				return nil
+			}).
+			Maybe()
 
-	return r
+		r.
+			On("UpdateRevised", mock.Anything, mock.AnythingOfType("ads.Ad"), mock.AnythingOfType("ads.Revision")).
+			Return(func(_ context.Context, ad ads.Ad, _ ads.Revision) error {
+				if ad.Title == "not found" {
+					return app.ErrAdNotFound
+				}
+				return nil
+			}).
+			Maybe()
+		return r
+	}
 }
 
 func getByIDDeleteRepo(t *testing.T) app.Repository {
@@ -111,7 +133,7 @@ func TestApp_Create(t *testing.T) {
 			want: ads.Ad{
 				ID:         0,
 				AuthorID:   0,
-				Published:  true,
+				Status:     ads.StatusDraft,
 				Title:      "correct",
 				Text:       "correct",
 				CreateDate: time.Now().UTC(),
@@ -129,7 +151,7 @@ func TestApp_Create(t *testing.T) {
 			want: ads.Ad{
 				ID:         -1,
 				AuthorID:   0,
-				Published:  true,
+				Status:     ads.StatusDraft,
 				Title:      "no author",
 				Text:       "valid",
 				CreateDate: time.Now().UTC(),
@@ -150,7 +172,7 @@ func TestApp_Create(t *testing.T) {
 			want: ads.Ad{
 				ID:         0,
 				AuthorID:   0,
-				Published:  true,
+				Status:     ads.StatusDraft,
 				Title:      "",
 				Text:       "valid",
 				CreateDate: time.Now().UTC(),
@@ -171,7 +193,7 @@ func TestApp_Create(t *testing.T) {
 			want: ads.Ad{
 				ID:         0,
 				AuthorID:   0,
-				Published:  true,
+				Status:     ads.StatusDraft,
 				Title:      "valid",
 				Text:       "",
 				CreateDate: time.Now().UTC(),
@@ -250,6 +272,27 @@ func TestApp_Delete(t *testing.T) {
 				return assert.ErrorIs(t, err, app.ErrPermissionDenied, i)
 			},
 		},
+		{
+			name:   "admin deletes any ad",
+			fields: fields{repository: getByIDDeleteRepo(t)},
+			args: args{
+				ctx:    permissions.WithRole(context.Background(), permissions.RoleAdmin),
+				id:     0,
+				userID: 1,
+			},
+		},
+		{
+			name:   "moderator cannot delete",
+			fields: fields{repository: getByIDRepo(t)},
+			args: args{
+				ctx:    permissions.WithRole(context.Background(), permissions.RoleModerator),
+				id:     0,
+				userID: 1,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrPermissionDenied, i)
+			},
+		},
 		{
 			name:   "deleting error",
 			fields: fields{repository: getByIDDeleteRepo(t)},
@@ -310,6 +353,7 @@ func TestApp_Update(t *testing.T) {
 				ID:         0,
 				AuthorID:   0,
 				Published:  false,
+				Status:     ads.StatusPending,
 				Title:      "valid",
 				Text:       "valid",
 				CreateDate: time.Time{},
@@ -416,7 +460,8 @@ func TestApp_Update(t *testing.T) {
 			want: ads.Ad{
 				ID:         0,
 				AuthorID:   0,
-				Published:  false,
+				Published:  true,
+				Status:     ads.StatusPublished,
 				Title:      "test title",
 				Text:       "test text",
 				CreateDate: time.Time{},
@@ -443,3 +488,359 @@ func TestApp_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_ChangeStatus(t *testing.T) {
+	type args struct {
+		ctx       context.Context
+		userID    int64
+		published bool
+	}
+	moderator := permissions.WithRole(context.Background(), permissions.RoleModerator)
+	admin := permissions.WithRole(context.Background(), permissions.RoleAdmin)
+	tests := []struct {
+		name    string
+		repo    func(t *testing.T) app.Repository
+		args    args
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "author publishes",
+			repo: getByIDUpdateStatusRepo(ads.StatusApproved),
+			args: args{ctx: context.Background(), userID: 0, published: true},
+		},
+		{
+			name: "author cannot publish unreviewed ad",
+			repo: getByIDUpdateStatusRepo(ads.StatusDraft),
+			args: args{ctx: context.Background(), userID: 0, published: true},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrInvalidStatus, i)
+			},
+		},
+		{
+			name: "user cannot unpublish",
+			repo: getByIDRepo,
+			args: args{ctx: context.Background(), userID: 1, published: false},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrPermissionDenied, i)
+			},
+		},
+		{
+			name: "moderator unpublishes",
+			repo: getByIDUpdateRepo,
+			args: args{ctx: moderator, userID: 1, published: false},
+		},
+		{
+			name: "moderator cannot publish",
+			repo: getByIDRepo,
+			args: args{ctx: moderator, userID: 1, published: true},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrPermissionDenied, i)
+			},
+		},
+		{
+			name: "admin publishes",
+			repo: getByIDUpdateStatusRepo(ads.StatusPaused),
+			args: args{ctx: admin, userID: 1, published: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.App{
+				Repo: tt.repo(t),
+			}
+			got, err := a.ChangeStatus(tt.args.ctx, 0, tt.args.userID, tt.args.published)
+			if tt.wantErr != nil {
+				tt.wantErr(t, err, fmt.Sprintf("ChangeStatus(%v, 0, %v, %v)", tt.args.ctx, tt.args.userID, tt.args.published))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.args.published, got.Published)
+		})
+	}
+}
+
+func storeCampaignRepo(t *testing.T) app.Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("StoreCampaign", mock.Anything, mock.AnythingOfType("campaigns.Campaign")).
+		Return(int64(1), nil)
+	return r
+}
+
+func countImpressionRepo(t *testing.T, counted bool) app.Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("CountImpression", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).
+		Return(counted, nil)
+	return r
+}
+
+func TestApp_CreateCampaign(t *testing.T) {
+	type args struct {
+		name      string
+		startDate time.Time
+		endDate   time.Time
+		totalCap  int64
+		dailyCap  int64
+	}
+	tests := []struct {
+		name    string
+		repo    app.Repository
+		args    args
+		want    campaigns.Campaign
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "correct creating",
+			repo: storeCampaignRepo(t),
+			args: args{
+				name:     "spring",
+				totalCap: 1000,
+				dailyCap: 100,
+			},
+			want: campaigns.Campaign{
+				ID:       1,
+				Name:     "spring",
+				TotalCap: 1000,
+				DailyCap: 100,
+			},
+		},
+		{
+			name: "invalid name",
+			args: args{
+				name: "",
+			},
+			want: campaigns.Campaign{},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "negative cap",
+			args: args{
+				name:     "spring",
+				dailyCap: -1,
+			},
+			want: campaigns.Campaign{
+				Name:     "spring",
+				DailyCap: -1,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "ends before start",
+			args: args{
+				name:      "spring",
+				startDate: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				endDate:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: campaigns.Campaign{
+				Name:      "spring",
+				StartDate: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, app.ErrInvalidContent, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.App{
+				Repo: tt.repo,
+			}
+			got, err := a.CreateCampaign(context.Background(), tt.args.name, 0, tt.args.startDate, tt.args.endDate, tt.args.totalCap, tt.args.dailyCap)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("CreateCampaign(%v)", tt.args)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equalf(t, tt.want, got, "CreateCampaign(%v)", tt.args)
+		})
+	}
+}
+
+func TestApp_CountImpression(t *testing.T) {
+	a := app.App{Repo: countImpressionRepo(t, true)}
+	assert.NoError(t, a.CountImpression(context.Background(), 1))
+
+	a = app.App{Repo: countImpressionRepo(t, false)}
+	assert.ErrorIs(t, a.CountImpression(context.Background(), 1), app.ErrCampaignEnded)
+}
+
+func TestApp_DeleteUserData(t *testing.T) {
+	r := mocks.NewRepository(t)
+	r.On("DeleteByAuthor", mock.Anything, int64(1)).Return(nil).Once()
+	r.On("DeleteByAuthor", mock.Anything, int64(2)).Return(assert.AnError).Once()
+	a := app.App{Repo: r}
+
+	assert.NoError(t, a.DeleteUserData(context.Background(), 1))
+	assert.ErrorIs(t, a.DeleteUserData(context.Background(), 2), assert.AnError)
+}
+
+func TestApp_Search(t *testing.T) {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetFiltered", mock.Anything, app.Filter{Query: "red bicycle"},
+			pagination.Page{Size: pagination.DefaultSize, Sort: "-relevance"}).
+		Return([]ads.Ad{{ID: 1, Snippet: "<mark>red</mark> <mark>bicycle</mark>"}}, pagination.Info{}, nil).
+		Once()
+	a := app.App{Repo: r}
+
+	got, err := a.Search(context.Background(), "red bicycle")
+	assert.NoError(t, err)
+	assert.Equal(t, []ads.Ad{{ID: 1, Snippet: "<mark>red</mark> <mark>bicycle</mark>"}}, got)
+}
+
+func TestApp_GetFiltered_Page(t *testing.T) {
+	filter := app.Filter{AuthorIDs: []int64{1}}
+	r := mocks.NewRepository(t)
+	r.
+		On("GetFiltered", mock.Anything, filter, pagination.Page{Size: 10, Sort: "-created", Total: true}).
+		Return([]ads.Ad{{ID: 2}}, pagination.Info{NextToken: "next", Total: 11}, nil).
+		Once()
+	a := app.App{Repo: r}
+	ctx := context.Background()
+
+	list, info, err := a.GetFiltered(ctx, filter, pagination.Page{Size: 10, Total: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []ads.Ad{{ID: 2}}, list)
+	assert.Equal(t, pagination.Info{NextToken: "next", Total: 11}, info)
+
+	_, _, err = a.GetFiltered(ctx, filter, pagination.Page{Sort: pagination.SortRelevance})
+	assert.ErrorIs(t, err, app.ErrInvalidFilter, "relevance is allowed only for search")
+	_, _, err = a.GetFiltered(ctx, filter, pagination.Page{Sort: "author"})
+	assert.ErrorIs(t, err, app.ErrInvalidFilter)
+}
+
+func TestApp_GetFiltered_InvalidFilter(t *testing.T) {
+	a := app.App{Repo: mocks.NewRepository(t)}
+	ctx := context.Background()
+	day := time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC)
+
+	_, _, err := a.GetFiltered(ctx, app.Filter{CreatedFrom: day, CreatedTo: day.AddDate(0, 0, -1)}, pagination.Page{})
+	assert.ErrorIs(t, err, app.ErrInvalidFilter)
+	_, _, err = a.GetFiltered(ctx, app.Filter{UpdatedFrom: day, UpdatedTo: day.AddDate(0, 0, -1)}, pagination.Page{})
+	assert.ErrorIs(t, err, app.ErrInvalidFilter)
+	_, _, err = a.GetFiltered(ctx, app.Filter{AuthorIDs: make([]int64, 101)}, pagination.Page{})
+	assert.ErrorIs(t, err, app.ErrInvalidFilter)
+}
+
+func TestApp_Submit(t *testing.T) {
+	ctx := context.Background()
+	a := app.App{Repo: getByIDUpdateStatusRepo(ads.StatusDraft)(t), Screener: screener.New([]string{"scam"})}
+	got, err := a.Submit(ctx, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusPending, got.Status)
+
+	a.Repo = getByIDUpdateStatusRepo(ads.StatusRejected)(t)
+	a.Screener = screener.New([]string{"TEST"})
+	got, err = a.Submit(ctx, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusRejected, got.Status, "banned words are rejected without review")
+	assert.Equal(t, app.BannedWordsReason+": test", got.RejectReason)
+
+	a.Repo = getByIDUpdateStatusRepo(ads.StatusPublished)(t)
+	_, err = a.Submit(ctx, 0, 0)
+	assert.ErrorIs(t, err, app.ErrInvalidStatus)
+}
+
+func TestApp_Review(t *testing.T) {
+	moderator := permissions.WithRole(context.Background(), permissions.RoleModerator)
+	a := app.App{Repo: getByIDUpdateStatusRepo(ads.StatusPending)(t)}
+
+	_, err := a.Review(context.Background(), 0, 1, true, "")
+	assert.ErrorIs(t, err, app.ErrPermissionDenied, "users cannot moderate")
+	_, err = a.Review(moderator, 0, 0, true, "")
+	assert.ErrorIs(t, err, app.ErrPermissionDenied, "moderators cannot review own ads")
+	_, err = a.Review(moderator, 0, 1, false, " ")
+	assert.ErrorIs(t, err, app.ErrInvalidContent, "reason is required")
+
+	got, err := a.Review(moderator, 0, 1, false, "misleading")
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusRejected, got.Status)
+	assert.Equal(t, "misleading", got.RejectReason)
+	got, err = a.Review(moderator, 0, 1, true, "")
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusApproved, got.Status)
+	assert.False(t, got.Published)
+
+	a.Repo = getByIDUpdateStatusRepo(ads.StatusDraft)(t)
+	_, err = a.Review(moderator, 0, 1, true, "")
+	assert.ErrorIs(t, err, app.ErrInvalidStatus)
+}
+
+func TestApp_GetModerationQueue(t *testing.T) {
+	moderator := permissions.WithRole(context.Background(), permissions.RoleModerator)
+	r := mocks.NewRepository(t)
+	r.
+		On("GetFiltered", mock.Anything, app.Filter{Statuses: []string{ads.StatusPending}},
+			pagination.Page{Size: pagination.DefaultSize, Sort: "updated"}).
+		Return([]ads.Ad{{ID: 1, Status: ads.StatusPending}}, pagination.Info{}, nil).
+		Once()
+	a := app.App{Repo: r}
+
+	list, _, err := a.GetModerationQueue(moderator, 1, pagination.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, []ads.Ad{{ID: 1, Status: ads.StatusPending}}, list)
+	_, _, err = a.GetModerationQueue(context.Background(), 1, pagination.Page{})
+	assert.ErrorIs(t, err, app.ErrPermissionDenied)
+}
+
+func TestApp_ListRevisions(t *testing.T) {
+	ctx := context.Background()
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByID", mock.Anything, int64(1)).
+		Return(ads.Ad{ID: 1, AuthorID: 1}, nil)
+	r.
+		On("GetRevisions", mock.Anything, int64(1),
+			pagination.Page{Size: pagination.DefaultSize, Sort: "-" + pagination.SortVersion}).
+		Return([]ads.Revision{{AdID: 1, Version: 2}, {AdID: 1, Version: 1}}, pagination.Info{}, nil).
+		Once()
+	a := app.App{Repo: r}
+
+	list, _, err := a.ListRevisions(ctx, 1, 1, pagination.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, []ads.Revision{{AdID: 1, Version: 2}, {AdID: 1, Version: 1}}, list)
+	_, _, err = a.ListRevisions(ctx, 1, 2, pagination.Page{})
+	assert.ErrorIs(t, err, app.ErrPermissionDenied)
+}
+
+func TestApp_RestoreRevision(t *testing.T) {
+	ctx := context.Background()
+	r := mocks.NewRepository(t)
+	ad := ads.Ad{ID: 1, AuthorID: 1, Title: "new title", Text: "new text"}
+	ad.SetStatus(ads.StatusPublished)
+	r.
+		On("GetByID", mock.Anything, int64(1)).
+		Return(ad, nil)
+	r.
+		On("GetRevision", mock.Anything, int64(1), 1).
+		Return(ads.Revision{AdID: 1, Version: 1, EditorID: 1, Title: "old title", Text: "old text"}, nil).
+		Once()
+	r.
+		On("GetRevision", mock.Anything, int64(1), 5).
+		Return(ads.Revision{}, app.ErrRevisionNotFound).
+		Once()
+	r.
+		On("UpdateRevised", mock.Anything, mock.AnythingOfType("ads.Ad"), mock.MatchedBy(func(rev ads.Revision) bool {
+			return rev.EditorID == 1 && rev.Title == "old title" && rev.Text == "old text"
+		})).
+		Return(nil).
+		Once()
+	a := app.App{Repo: r, Screener: screener.New(nil)}
+
+	got, err := a.RestoreRevision(ctx, 1, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "old title", got.Title)
+	assert.Equal(t, "old text", got.Text)
+	assert.Equal(t, ads.StatusPending, got.Status, "restored content is reviewed again")
+
+	_, err = a.RestoreRevision(ctx, 1, 1, 5)
+	assert.ErrorIs(t, err, app.ErrRevisionNotFound)
+	_, err = a.RestoreRevision(ctx, 1, 2, 1)
+	assert.ErrorIs(t, err, app.ErrPermissionDenied)
+}
@@ -0,0 +1,67 @@
+package campaigns
+
+import (
+	"fmt"
+	"time"
+)
+
+// Campaign groups ads of the author. Zero StartDate and EndDate mean that the campaign is not limited in time,
+// zero TotalCap and DailyCap mean that impressions are not limited. DayImpressions are impressions during Day
+type Campaign struct {
+	ID             int64
+	AuthorID       int64
+	Name           string `validate:"min:1; max:99"`
+	StartDate      time.Time
+	EndDate        time.Time
+	TotalCap       int64
+	DailyCap       int64
+	Impressions    int64
+	Day            time.Time
+	DayImpressions int64
+}
+
+func (c Campaign) String() string {
+	return fmt.Sprintf(
+		"<Campaign id=%d authorID=%d name=`%s` start=%s end=%s totalCap=%d dailyCap=%d impressions=%d>",
+		c.ID,
+		c.AuthorID,
+		c.Name,
+		c.StartDate,
+		c.EndDate,
+		c.TotalCap,
+		c.DailyCap,
+		c.Impressions,
+	)
+}
+
+// IsScheduled reports whether the campaign is running at the moment
+func (c Campaign) IsScheduled(moment time.Time) bool {
+	return (c.StartDate.IsZero() || !moment.Before(c.StartDate)) && (c.EndDate.IsZero() || moment.Before(c.EndDate))
+}
+
+// IsExhausted reports whether the campaign has reached one of its caps at the moment
+func (c Campaign) IsExhausted(moment time.Time) bool {
+	if c.TotalCap > 0 && c.Impressions >= c.TotalCap {
+		return true
+	}
+	sameDay := c.Day.Equal(moment.Truncate(24 * time.Hour))
+	return c.DailyCap > 0 && sameDay && c.DayImpressions >= c.DailyCap
+}
+
+func New(
+	name string,
+	authorID int64,
+	startDate time.Time,
+	endDate time.Time,
+	totalCap int64,
+	dailyCap int64,
+) Campaign {
+	return Campaign{
+		AuthorID:  authorID,
+		Name:      name,
+		StartDate: startDate,
+		EndDate:   endDate,
+		TotalCap:  totalCap,
+		DailyCap:  dailyCap,
+	}
+}
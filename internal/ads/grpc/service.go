@@ -4,7 +4,9 @@ import (
 	"context"
 	"goads/internal/ads/ads"
 	"goads/internal/ads/app"
+	"goads/internal/ads/campaigns"
 	"goads/internal/ads/proto"
+	"goads/internal/pkg/pagination"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"time"
 )
@@ -14,10 +16,30 @@ type App interface {
 	GetByID(ctx context.Context, id int64) (ads.Ad, error)
 	ChangeStatus(ctx context.Context, id int64, userID int64, published bool) (ads.Ad, error)
 	Update(ctx context.Context, id int64, userID int64, title string, text string) (ads.Ad, error)
-	GetFiltered(ctx context.Context, opt app.Filter) ([]ads.Ad, error)
+	GetFiltered(ctx context.Context, opt app.Filter, page pagination.Page) ([]ads.Ad, pagination.Info, error)
 	Delete(ctx context.Context, id int64, userID int64) error
-	Search(ctx context.Context, title string) ([]ads.Ad, error)
-	GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error)
+	Search(ctx context.Context, query string) ([]ads.Ad, error)
+	GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, []int64, error)
+	SetCampaign(ctx context.Context, id int64, userID int64, campaignID int64) (ads.Ad, error)
+	CountImpression(ctx context.Context, adID int64) error
+	CreateCampaign(
+		ctx context.Context,
+		name string,
+		authorID int64,
+		startDate time.Time,
+		endDate time.Time,
+		totalCap int64,
+		dailyCap int64,
+	) (campaigns.Campaign, error)
+	GetCampaign(ctx context.Context, id int64, userID int64) (campaigns.Campaign, error)
+	DeleteCampaign(ctx context.Context, id int64, userID int64) error
+	Submit(ctx context.Context, id int64, userID int64) (ads.Ad, error)
+	Archive(ctx context.Context, id int64, userID int64) (ads.Ad, error)
+	Review(ctx context.Context, id int64, userID int64, approve bool, reason string) (ads.Ad, error)
+	GetModerationQueue(ctx context.Context, userID int64, page pagination.Page) ([]ads.Ad, pagination.Info, error)
+	ListRevisions(ctx context.Context, id int64, userID int64, page pagination.Page) ([]ads.Revision, pagination.Info, error)
+	GetRevision(ctx context.Context, id int64, userID int64, version int) (ads.Revision, error)
+	RestoreRevision(ctx context.Context, id int64, userID int64, version int) (ads.Ad, error)
 }
 
 type Service struct {
@@ -25,8 +47,10 @@ type Service struct {
 }
 
 func (s Service) GetOnlyPublished(ctx context.Context, request *proto.AdIDsRequest) (*proto.AdsResponse, error) {
-	list, err := s.app.GetOnlyPublished(ctx, request.Id)
-	return adsToResponse(list), getErrorStatus(err)
+	list, missing, err := s.app.GetOnlyPublished(ctx, request.Id)
+	res := adsToResponse(list)
+	res.Missing = missing
+	return res, getErrorStatus(err)
 }
 
 func (s Service) Create(ctx context.Context, request *proto.CreateAdRequest) (*proto.AdResponse, error) {
@@ -45,13 +69,15 @@ func (s Service) Update(ctx context.Context, request *proto.UpdateAdRequest) (*p
 }
 
 func (s Service) Filter(ctx context.Context, request *proto.FilterAdsRequest) (*proto.AdsResponse, error) {
-	list, err := s.app.GetFiltered(ctx, app.Filter{
-		AuthorID: request.AuthorId,
-		Date:     time.UnixMilli(request.Date).UTC(),
-		All:      request.All,
-		Title:    request.Title,
+	list, info, err := s.app.GetFiltered(ctx, filterFromRequest(request), pagination.Page{
+		Size:  int(request.PageSize),
+		Token: request.PageToken,
+		Sort:  request.OrderBy,
+		Total: request.WithTotal,
 	})
-	return adsToResponse(list), getErrorStatus(err)
+	res := adsToResponse(list)
+	res.NextPageToken, res.Total = info.NextToken, info.Total
+	return res, getErrorStatus(err)
 }
 
 func (s Service) GetByID(ctx context.Context, request *proto.GetAdByIDRequest) (*proto.AdResponse, error) {
@@ -64,6 +90,83 @@ func (s Service) Delete(ctx context.Context, request *proto.DeleteAdRequest) (*e
 	return new(emptypb.Empty), getErrorStatus(err)
 }
 
+func (s Service) SetCampaign(ctx context.Context, request *proto.SetAdCampaignRequest) (*proto.AdResponse, error) {
+	ad, err := s.app.SetCampaign(ctx, request.AdId, request.AuthorId, request.CampaignId)
+	return adToResponse(ad), getErrorStatus(err)
+}
+
+func (s Service) CountImpression(ctx context.Context, request *proto.CountImpressionRequest) (*emptypb.Empty, error) {
+	err := s.app.CountImpression(ctx, request.AdId)
+	return new(emptypb.Empty), getErrorStatus(err)
+}
+
+func (s Service) CreateCampaign(ctx context.Context, request *proto.CreateCampaignRequest) (*proto.CampaignResponse, error) {
+	campaign, err := s.app.CreateCampaign(
+		ctx, request.Name, request.AuthorId, millisToTime(request.StartDate), millisToTime(request.EndDate),
+		request.TotalCap, request.DailyCap,
+	)
+	return campaignToResponse(campaign), getErrorStatus(err)
+}
+
+func (s Service) GetCampaign(ctx context.Context, request *proto.GetCampaignRequest) (*proto.CampaignResponse, error) {
+	campaign, err := s.app.GetCampaign(ctx, request.Id, request.AuthorId)
+	return campaignToResponse(campaign), getErrorStatus(err)
+}
+
+func (s Service) DeleteCampaign(ctx context.Context, request *proto.GetCampaignRequest) (*emptypb.Empty, error) {
+	err := s.app.DeleteCampaign(ctx, request.Id, request.AuthorId)
+	return new(emptypb.Empty), getErrorStatus(err)
+}
+
+func (s Service) Submit(ctx context.Context, request *proto.AdActionRequest) (*proto.AdResponse, error) {
+	ad, err := s.app.Submit(ctx, request.AdId, request.AuthorId)
+	return adToResponse(ad), getErrorStatus(err)
+}
+
+func (s Service) Archive(ctx context.Context, request *proto.AdActionRequest) (*proto.AdResponse, error) {
+	ad, err := s.app.Archive(ctx, request.AdId, request.AuthorId)
+	return adToResponse(ad), getErrorStatus(err)
+}
+
+func (s Service) Review(ctx context.Context, request *proto.ReviewAdRequest) (*proto.AdResponse, error) {
+	ad, err := s.app.Review(ctx, request.AdId, request.ModeratorId, request.Approve, request.Reason)
+	return adToResponse(ad), getErrorStatus(err)
+}
+
+func (s Service) GetModerationQueue(ctx context.Context, request *proto.ModerationQueueRequest) (*proto.AdsResponse, error) {
+	list, info, err := s.app.GetModerationQueue(ctx, request.ModeratorId, pagination.Page{
+		Size:  int(request.PageSize),
+		Token: request.PageToken,
+		Sort:  request.OrderBy,
+		Total: request.WithTotal,
+	})
+	res := adsToResponse(list)
+	res.NextPageToken, res.Total = info.NextToken, info.Total
+	return res, getErrorStatus(err)
+}
+
+func (s Service) ListRevisions(ctx context.Context, request *proto.ListRevisionsRequest) (*proto.RevisionsResponse, error) {
+	list, info, err := s.app.ListRevisions(ctx, request.AdId, request.AuthorId, pagination.Page{
+		Size:  int(request.PageSize),
+		Token: request.PageToken,
+		Sort:  request.OrderBy,
+		Total: request.WithTotal,
+	})
+	res := revisionsToResponse(list)
+	res.NextPageToken, res.Total = info.NextToken, info.Total
+	return res, getErrorStatus(err)
+}
+
+func (s Service) GetRevision(ctx context.Context, request *proto.RevisionRequest) (*proto.RevisionResponse, error) {
+	revision, err := s.app.GetRevision(ctx, request.AdId, request.AuthorId, int(request.Version))
+	return revisionToResponse(revision), getErrorStatus(err)
+}
+
+func (s Service) RestoreRevision(ctx context.Context, request *proto.RevisionRequest) (*proto.AdResponse, error) {
+	ad, err := s.app.RestoreRevision(ctx, request.AdId, request.AuthorId, int(request.Version))
+	return adToResponse(ad), getErrorStatus(err)
+}
+
 func NewService(app App) Service {
 	return Service{app}
 }
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"goads/internal/ads/ads"
 	"goads/internal/ads/app"
+	"goads/internal/ads/campaigns"
 	"goads/internal/ads/proto"
 	"goads/internal/pkg/errwrap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"time"
 )
 
 func getErrorStatus(err error) error {
@@ -19,7 +21,8 @@ func getErrorStatus(err error) error {
 	if errors.As(err, &wrap) {
 		err = wrap.Unwrap() // hiding error information
 	}
-	if errors.Is(err, app.ErrAdNotFound) || errors.Is(err, app.ErrAuthorNotFound) {
+	if errors.Is(err, app.ErrAdNotFound) || errors.Is(err, app.ErrAuthorNotFound) ||
+		errors.Is(err, app.ErrCampaignNotFound) || errors.Is(err, app.ErrRevisionNotFound) {
 		code = codes.NotFound
 	}
 	if errors.Is(err, app.ErrPermissionDenied) {
@@ -28,6 +31,12 @@ func getErrorStatus(err error) error {
 	if errors.Is(err, app.ErrInvalidContent) || errors.Is(err, app.ErrInvalidFilter) {
 		code = codes.InvalidArgument
 	}
+	if errors.Is(err, app.ErrCampaignEnded) {
+		code = codes.ResourceExhausted
+	}
+	if errors.Is(err, app.ErrInvalidStatus) {
+		code = codes.FailedPrecondition
+	}
 
 	if code == codes.Internal {
 		err = errors.New("internal error")
@@ -37,13 +46,102 @@ func getErrorStatus(err error) error {
 
 func adToResponse(ad ads.Ad) *proto.AdResponse {
 	return &proto.AdResponse{
-		Id:         ad.ID,
-		Title:      ad.Title,
-		Published:  ad.Published,
-		Text:       ad.Text,
-		AuthorId:   ad.AuthorID,
-		CreateDate: ad.CreateDate.UnixMilli(),
-		UpdateDate: ad.UpdateDate.UnixMilli(),
+		Id:           ad.ID,
+		Title:        ad.Title,
+		Published:    ad.Published,
+		Text:         ad.Text,
+		AuthorId:     ad.AuthorID,
+		CreateDate:   ad.CreateDate.UnixMilli(),
+		UpdateDate:   ad.UpdateDate.UnixMilli(),
+		CampaignId:   ad.CampaignID,
+		Snippet:      ad.Snippet,
+		Status:       ad.Status,
+		RejectReason: ad.RejectReason,
+	}
+}
+
+func revisionToResponse(revision ads.Revision) *proto.RevisionResponse {
+	return &proto.RevisionResponse{
+		AdId:       revision.AdID,
+		Version:    int32(revision.Version),
+		EditorId:   revision.EditorID,
+		Title:      revision.Title,
+		Text:       revision.Text,
+		CreateDate: timeToMillis(revision.CreateDate),
+	}
+}
+
+func revisionsToResponse(list []ads.Revision) *proto.RevisionsResponse {
+	res := proto.RevisionsResponse{
+		List: make([]*proto.RevisionResponse, len(list)),
+	}
+	for i := range list {
+		res.List[i] = revisionToResponse(list[i])
+	}
+	return &res
+}
+
+// filterFromRequest converts the request to the filter. Deprecated fields are used only if their
+// replacements are not set
+func filterFromRequest(request *proto.FilterAdsRequest) app.Filter {
+	filter := app.Filter{
+		AuthorIDs:   request.AuthorIds,
+		CreatedFrom: millisToTime(request.CreatedFrom),
+		CreatedTo:   millisToTime(request.CreatedTo),
+		UpdatedFrom: millisToTime(request.UpdatedFrom),
+		UpdatedTo:   millisToTime(request.UpdatedTo),
+		Statuses:    request.Statuses,
+		Query:       request.Query,
+	}
+	if len(filter.AuthorIDs) == 0 && request.AuthorId > 0 {
+		filter.AuthorIDs = []int64{request.AuthorId}
+	}
+	if filter.CreatedFrom.IsZero() && filter.CreatedTo.IsZero() && request.Date != 0 {
+		filter.CreatedFrom, filter.CreatedTo = millisToTime(request.Date), millisToTime(request.Date)
+	}
+	published, unpublished := true, false
+	switch request.Published {
+	case proto.PublishedState_PUBLISHED_STATE_PUBLISHED:
+		filter.Published = &published
+	case proto.PublishedState_PUBLISHED_STATE_UNPUBLISHED:
+		filter.Published = &unpublished
+	case proto.PublishedState_PUBLISHED_STATE_UNSPECIFIED:
+		if !request.All {
+			filter.Published = &published
+		}
+	}
+	return filter
+}
+
+// millisToTime converts unix milliseconds to time. 0 means unset time and is converted to zero time
+func millisToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// timeToMillis converts time to unix milliseconds. Zero time is converted to 0
+func timeToMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func campaignToResponse(c campaigns.Campaign) *proto.CampaignResponse {
+	now := time.Now().UTC()
+	return &proto.CampaignResponse{
+		Id:             c.ID,
+		AuthorId:       c.AuthorID,
+		Name:           c.Name,
+		StartDate:      timeToMillis(c.StartDate),
+		EndDate:        timeToMillis(c.EndDate),
+		TotalCap:       c.TotalCap,
+		DailyCap:       c.DailyCap,
+		Impressions:    c.Impressions,
+		DayImpressions: c.DayImpressions,
+		Running:        c.ID != 0 && c.IsScheduled(now) && !c.IsExhausted(now),
 	}
 }
 
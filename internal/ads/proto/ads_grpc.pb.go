@@ -20,13 +20,25 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AdService_Create_FullMethodName           = "/ads.AdService/Create"
-	AdService_ChangeStatus_FullMethodName     = "/ads.AdService/ChangeStatus"
-	AdService_Update_FullMethodName           = "/ads.AdService/Update"
-	AdService_Filter_FullMethodName           = "/ads.AdService/Filter"
-	AdService_GetByID_FullMethodName          = "/ads.AdService/GetByID"
-	AdService_GetOnlyPublished_FullMethodName = "/ads.AdService/GetOnlyPublished"
-	AdService_Delete_FullMethodName           = "/ads.AdService/Delete"
+	AdService_Create_FullMethodName             = "/ads.AdService/Create"
+	AdService_ChangeStatus_FullMethodName       = "/ads.AdService/ChangeStatus"
+	AdService_Update_FullMethodName             = "/ads.AdService/Update"
+	AdService_Filter_FullMethodName             = "/ads.AdService/Filter"
+	AdService_GetByID_FullMethodName            = "/ads.AdService/GetByID"
+	AdService_GetOnlyPublished_FullMethodName   = "/ads.AdService/GetOnlyPublished"
+	AdService_Delete_FullMethodName             = "/ads.AdService/Delete"
+	AdService_SetCampaign_FullMethodName        = "/ads.AdService/SetCampaign"
+	AdService_CountImpression_FullMethodName    = "/ads.AdService/CountImpression"
+	AdService_CreateCampaign_FullMethodName     = "/ads.AdService/CreateCampaign"
+	AdService_GetCampaign_FullMethodName        = "/ads.AdService/GetCampaign"
+	AdService_DeleteCampaign_FullMethodName     = "/ads.AdService/DeleteCampaign"
+	AdService_Submit_FullMethodName             = "/ads.AdService/Submit"
+	AdService_Archive_FullMethodName            = "/ads.AdService/Archive"
+	AdService_Review_FullMethodName             = "/ads.AdService/Review"
+	AdService_GetModerationQueue_FullMethodName = "/ads.AdService/GetModerationQueue"
+	AdService_ListRevisions_FullMethodName      = "/ads.AdService/ListRevisions"
+	AdService_GetRevision_FullMethodName        = "/ads.AdService/GetRevision"
+	AdService_RestoreRevision_FullMethodName    = "/ads.AdService/RestoreRevision"
 )
 
 // AdServiceClient is the client API for AdService service.
@@ -40,6 +52,18 @@ type AdServiceClient interface {
 	GetByID(ctx context.Context, in *GetAdByIDRequest, opts ...grpc.CallOption) (*AdResponse, error)
 	GetOnlyPublished(ctx context.Context, in *AdIDsRequest, opts ...grpc.CallOption) (*AdsResponse, error)
 	Delete(ctx context.Context, in *DeleteAdRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetCampaign(ctx context.Context, in *SetAdCampaignRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	CountImpression(ctx context.Context, in *CountImpressionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*CampaignResponse, error)
+	GetCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*CampaignResponse, error)
+	DeleteCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Submit(ctx context.Context, in *AdActionRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	Archive(ctx context.Context, in *AdActionRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	Review(ctx context.Context, in *ReviewAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	GetModerationQueue(ctx context.Context, in *ModerationQueueRequest, opts ...grpc.CallOption) (*AdsResponse, error)
+	ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...grpc.CallOption) (*RevisionsResponse, error)
+	GetRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*RevisionResponse, error)
+	RestoreRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*AdResponse, error)
 }
 
 type adServiceClient struct {
@@ -113,6 +137,114 @@ func (c *adServiceClient) Delete(ctx context.Context, in *DeleteAdRequest, opts
 	return out, nil
 }
 
+func (c *adServiceClient) SetCampaign(ctx context.Context, in *SetAdCampaignRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_SetCampaign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) CountImpression(ctx context.Context, in *CountImpressionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AdService_CountImpression_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*CampaignResponse, error) {
+	out := new(CampaignResponse)
+	err := c.cc.Invoke(ctx, AdService_CreateCampaign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) GetCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*CampaignResponse, error) {
+	out := new(CampaignResponse)
+	err := c.cc.Invoke(ctx, AdService_GetCampaign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) DeleteCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AdService_DeleteCampaign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) Submit(ctx context.Context, in *AdActionRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_Submit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) Archive(ctx context.Context, in *AdActionRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_Archive_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) Review(ctx context.Context, in *ReviewAdRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_Review_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) GetModerationQueue(ctx context.Context, in *ModerationQueueRequest, opts ...grpc.CallOption) (*AdsResponse, error) {
+	out := new(AdsResponse)
+	err := c.cc.Invoke(ctx, AdService_GetModerationQueue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...grpc.CallOption) (*RevisionsResponse, error) {
+	out := new(RevisionsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListRevisions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) GetRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*RevisionResponse, error) {
+	out := new(RevisionResponse)
+	err := c.cc.Invoke(ctx, AdService_GetRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RestoreRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_RestoreRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AdServiceServer is the server API for AdService service.
 // All implementations should embed UnimplementedAdServiceServer
 // for forward compatibility
@@ -124,6 +256,18 @@ type AdServiceServer interface {
 	GetByID(context.Context, *GetAdByIDRequest) (*AdResponse, error)
 	GetOnlyPublished(context.Context, *AdIDsRequest) (*AdsResponse, error)
 	Delete(context.Context, *DeleteAdRequest) (*emptypb.Empty, error)
+	SetCampaign(context.Context, *SetAdCampaignRequest) (*AdResponse, error)
+	CountImpression(context.Context, *CountImpressionRequest) (*emptypb.Empty, error)
+	CreateCampaign(context.Context, *CreateCampaignRequest) (*CampaignResponse, error)
+	GetCampaign(context.Context, *GetCampaignRequest) (*CampaignResponse, error)
+	DeleteCampaign(context.Context, *GetCampaignRequest) (*emptypb.Empty, error)
+	Submit(context.Context, *AdActionRequest) (*AdResponse, error)
+	Archive(context.Context, *AdActionRequest) (*AdResponse, error)
+	Review(context.Context, *ReviewAdRequest) (*AdResponse, error)
+	GetModerationQueue(context.Context, *ModerationQueueRequest) (*AdsResponse, error)
+	ListRevisions(context.Context, *ListRevisionsRequest) (*RevisionsResponse, error)
+	GetRevision(context.Context, *RevisionRequest) (*RevisionResponse, error)
+	RestoreRevision(context.Context, *RevisionRequest) (*AdResponse, error)
 }
 
 // UnimplementedAdServiceServer should be embedded to have forward compatible implementations.
@@ -151,6 +295,42 @@ func (UnimplementedAdServiceServer) GetOnlyPublished(context.Context, *AdIDsRequ
 func (UnimplementedAdServiceServer) Delete(context.Context, *DeleteAdRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedAdServiceServer) SetCampaign(context.Context, *SetAdCampaignRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCampaign not implemented")
+}
+func (UnimplementedAdServiceServer) CountImpression(context.Context, *CountImpressionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountImpression not implemented")
+}
+func (UnimplementedAdServiceServer) CreateCampaign(context.Context, *CreateCampaignRequest) (*CampaignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCampaign not implemented")
+}
+func (UnimplementedAdServiceServer) GetCampaign(context.Context, *GetCampaignRequest) (*CampaignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCampaign not implemented")
+}
+func (UnimplementedAdServiceServer) DeleteCampaign(context.Context, *GetCampaignRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCampaign not implemented")
+}
+func (UnimplementedAdServiceServer) Submit(context.Context, *AdActionRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedAdServiceServer) Archive(context.Context, *AdActionRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Archive not implemented")
+}
+func (UnimplementedAdServiceServer) Review(context.Context, *ReviewAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Review not implemented")
+}
+func (UnimplementedAdServiceServer) GetModerationQueue(context.Context, *ModerationQueueRequest) (*AdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModerationQueue not implemented")
+}
+func (UnimplementedAdServiceServer) ListRevisions(context.Context, *ListRevisionsRequest) (*RevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRevisions not implemented")
+}
+func (UnimplementedAdServiceServer) GetRevision(context.Context, *RevisionRequest) (*RevisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRevision not implemented")
+}
+func (UnimplementedAdServiceServer) RestoreRevision(context.Context, *RevisionRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreRevision not implemented")
+}
 
 // UnsafeAdServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to AdServiceServer will
@@ -289,6 +469,222 @@ func _AdService_Delete_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AdService_SetCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAdCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).SetCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_SetCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).SetCampaign(ctx, req.(*SetAdCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_CountImpression_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountImpressionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CountImpression(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CountImpression_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CountImpression(ctx, req.(*CountImpressionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_CreateCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CreateCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CreateCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CreateCampaign(ctx, req.(*CreateCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_GetCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).GetCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_GetCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).GetCampaign(ctx, req.(*GetCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_DeleteCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).DeleteCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_DeleteCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).DeleteCampaign(ctx, req.(*GetCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).Submit(ctx, req.(*AdActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_Archive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).Archive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_Archive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).Archive(ctx, req.(*AdActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_Review_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).Review(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_Review_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).Review(ctx, req.(*ReviewAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_GetModerationQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).GetModerationQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_GetModerationQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).GetModerationQueue(ctx, req.(*ModerationQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListRevisions(ctx, req.(*ListRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_GetRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).GetRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_GetRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).GetRevision(ctx, req.(*RevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RestoreRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RestoreRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RestoreRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RestoreRevision(ctx, req.(*RevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AdService_ServiceDesc is the grpc.ServiceDesc for AdService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -324,6 +720,54 @@ var AdService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _AdService_Delete_Handler,
 		},
+		{
+			MethodName: "SetCampaign",
+			Handler:    _AdService_SetCampaign_Handler,
+		},
+		{
+			MethodName: "CountImpression",
+			Handler:    _AdService_CountImpression_Handler,
+		},
+		{
+			MethodName: "CreateCampaign",
+			Handler:    _AdService_CreateCampaign_Handler,
+		},
+		{
+			MethodName: "GetCampaign",
+			Handler:    _AdService_GetCampaign_Handler,
+		},
+		{
+			MethodName: "DeleteCampaign",
+			Handler:    _AdService_DeleteCampaign_Handler,
+		},
+		{
+			MethodName: "Submit",
+			Handler:    _AdService_Submit_Handler,
+		},
+		{
+			MethodName: "Archive",
+			Handler:    _AdService_Archive_Handler,
+		},
+		{
+			MethodName: "Review",
+			Handler:    _AdService_Review_Handler,
+		},
+		{
+			MethodName: "GetModerationQueue",
+			Handler:    _AdService_GetModerationQueue_Handler,
+		},
+		{
+			MethodName: "ListRevisions",
+			Handler:    _AdService_ListRevisions_Handler,
+		},
+		{
+			MethodName: "GetRevision",
+			Handler:    _AdService_GetRevision_Handler,
+		},
+		{
+			MethodName: "RestoreRevision",
+			Handler:    _AdService_RestoreRevision_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "ads.proto",
@@ -0,0 +1,38 @@
+package screener
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Screener finds banned words in texts of ads before their review
+type Screener struct {
+	words map[string]struct{}
+}
+
+// Find returns banned words of the text in order of their first occurrence. Words are compared case-insensitively
+func (s Screener) Find(text string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if _, ok := s.words[w]; ok && !seen[w] {
+			seen[w] = true
+			found = append(found, w)
+		}
+	}
+	return found
+}
+
+// New creates screener of the banned words. Empty words are ignored
+func New(words []string) Screener {
+	s := Screener{words: make(map[string]struct{}, len(words))}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			s.words[w] = struct{}{}
+		}
+	}
+	return s
+}
@@ -0,0 +1,13 @@
+package screener
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScreener_Find(t *testing.T) {
+	s := New([]string{"Casino", " scam ", ""})
+	assert.Equal(t, []string{"scam", "casino"}, s.Find("No SCAM: the best casino, really no scam"))
+	assert.Empty(t, s.Find("Casinos and scammers are not whole words"))
+	assert.Empty(t, New(nil).Find("casino"))
+}
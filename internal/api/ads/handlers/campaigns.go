@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/ads/proto"
+	"goads/internal/api/ads/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// createCampaignRequest is data of a new campaign. Zero dates and caps mean no limits
+type createCampaignRequest struct {
+	Name      string    `json:"name" binding:"required"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	TotalCap  int64     `json:"total_cap"`
+	DailyCap  int64     `json:"daily_cap"`
+}
+
+// timeToMillis converts time to unix milliseconds. Zero time is converted to 0
+func timeToMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func CreateCampaign(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createCampaignRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		campaign, err := client.CreateCampaign(c, &proto.CreateCampaignRequest{
+			Name:      req.Name,
+			AuthorId:  userID,
+			StartDate: timeToMillis(req.StartDate),
+			EndDate:   timeToMillis(req.EndDate),
+			TotalCap:  req.TotalCap,
+			DailyCap:  req.DailyCap,
+		})
+		errors.ProceedResult(c, responses.CampaignSuccess(campaign), err)
+	}
+}
+
+func GetCampaign(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("campaign_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		campaign, err := client.GetCampaign(c, &proto.GetCampaignRequest{
+			Id:       int64(id),
+			AuthorId: userID,
+		})
+		errors.ProceedResult(c, responses.CampaignSuccess(campaign), err)
+	}
+}
+
+func DeleteCampaign(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("campaign_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		_, err = client.DeleteCampaign(c, &proto.GetCampaignRequest{
+			Id:       int64(id),
+			AuthorId: userID,
+		})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
+
+// setCampaignRequest moves the ad to the campaign. Zero campaign removes the ad from its campaign
+type setCampaignRequest struct {
+	Campaign int64 `json:"campaign"`
+}
+
+func SetCampaign(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		var req setCampaignRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		ad, err := client.SetCampaign(c, &proto.SetAdCampaignRequest{
+			AdId:       int64(id),
+			AuthorId:   userID,
+			CampaignId: req.Campaign,
+		})
+		errors.ProceedResult(c, responses.AdSuccess(ad), err)
+	}
+}
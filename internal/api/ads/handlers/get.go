@@ -1,39 +1,115 @@
 package handlers
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"goads/internal/ads/proto"
 	"goads/internal/api/ads/responses"
 	"goads/internal/api/auth/utils"
 	"goads/internal/api/errors"
+	"goads/internal/api/paging"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// GetFiltered returns the page of caller's ads
 func GetFiltered(client proto.AdServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		filter := &proto.FilterAdsRequest{}
-		if _, ok := c.GetQuery("all"); ok {
-			filter.All = true
+		filter, page, err := filterFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
 		}
-		if date, ok := c.GetQuery("date"); ok {
-			datetime, err := time.Parse(time.RFC3339Nano, date)
+		authorID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		filter.AuthorIds = []int64{authorID}
+		proceedFiltered(c, client, filter, page)
+	}
+}
+
+// Browse returns the page of published ads of any authors or of authors listed by author_id parameters
+func Browse(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, page, err := filterFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		for _, s := range c.QueryArray("author_id") {
+			id, err := strconv.ParseInt(s, 10, 64)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, errors.Response(err))
+				c.JSON(http.StatusBadRequest, errors.Response(fmt.Errorf("invalid author ID: %s", s)))
 				return
 			}
-			filter.Date = datetime.UnixMilli()
+			filter.AuthorIds = append(filter.AuthorIds, id)
 		}
-		if q, ok := c.GetQuery("search"); ok {
-			filter.Title = q
+		filter.Published = proto.PublishedState_PUBLISHED_STATE_PUBLISHED
+		proceedFiltered(c, client, filter, page)
+	}
+}
+
+func proceedFiltered(c *gin.Context, client proto.AdServiceClient, filter *proto.FilterAdsRequest, page paging.Params) {
+	adsList, err := client.Filter(c, filter)
+	res := responses.AdsSuccess(adsList)
+	if err == nil {
+		res = paging.Apply(c, page, res, adsList.GetNextPageToken(), adsList.GetTotal())
+	}
+	errors.ProceedResult(c, res, err)
+}
+
+// publishedStates are values of published parameter
+var publishedStates = map[string]proto.PublishedState{
+	"true":  proto.PublishedState_PUBLISHED_STATE_PUBLISHED,
+	"false": proto.PublishedState_PUBLISHED_STATE_UNPUBLISHED,
+	"any":   proto.PublishedState_PUBLISHED_STATE_ANY,
+}
+
+// filterFromQuery reads the filter and the page from query parameters. Dates are in RFC 3339 format
+func filterFromQuery(c *gin.Context) (*proto.FilterAdsRequest, paging.Params, error) {
+	filter := &proto.FilterAdsRequest{}
+	page, err := paging.GetParams(c)
+	if err != nil {
+		return filter, page, err
+	}
+	filter.PageSize, filter.PageToken = page.Size, page.Token
+	filter.OrderBy, filter.WithTotal = page.Sort, page.WithTotal
+	if _, ok := c.GetQuery("all"); ok {
+		filter.All = true
+	}
+	if s, ok := c.GetQuery("published"); ok {
+		state, ok := publishedStates[s]
+		if !ok {
+			return filter, page, fmt.Errorf("invalid published state: %s", s)
 		}
-		var err error
-		filter.AuthorId, err = utils.GetUserID(c)
-		if err != nil {
-			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
-			return
+		filter.Published = state
+	}
+	filter.Statuses = c.QueryArray("status")
+	// date is the former exact day of creation
+	dates := map[string]*int64{
+		"date":         &filter.Date,
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"updated_from": &filter.UpdatedFrom,
+		"updated_to":   &filter.UpdatedTo,
+	}
+	for name, ms := range dates {
+		if date, ok := c.GetQuery(name); ok {
+			datetime, err := time.Parse(time.RFC3339Nano, date)
+			if err != nil {
+				return filter, page, err
+			}
+			*ms = datetime.UnixMilli()
 		}
-		adsList, err := client.Filter(c, filter)
-		errors.ProceedResult(c, responses.AdsSuccess(adsList), err)
 	}
+	// search is the former name of q
+	if q, ok := c.GetQuery("q"); ok {
+		filter.Query = q
+	} else if q, ok := c.GetQuery("search"); ok {
+		filter.Query = q
+	}
+	return filter, page, nil
 }
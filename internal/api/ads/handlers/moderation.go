@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"goads/internal/ads/proto"
+	"goads/internal/api/ads/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/api/paging"
+	"google.golang.org/grpc"
+	"net/http"
+	"strconv"
+)
+
+// Submit sends the ad to the moderation
+func Submit(client proto.AdServiceClient) gin.HandlerFunc {
+	return adAction(client.Submit)
+}
+
+// Archive hides the ad forever
+func Archive(client proto.AdServiceClient) gin.HandlerFunc {
+	return adAction(client.Archive)
+}
+
+// adAction calls the action with the ad from the path by the user
+func adAction(
+	action func(ctx context.Context, in *proto.AdActionRequest, opts ...grpc.CallOption) (*proto.AdResponse, error),
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		ad, err := action(c, &proto.AdActionRequest{AdId: int64(id), AuthorId: userID})
+		errors.ProceedResult(c, responses.AdSuccess(ad), err)
+	}
+}
+
+type reviewRequest struct {
+	Approve bool   `json:"approve"`
+	Reason  string `json:"reason"`
+}
+
+// Review approves or rejects the pending ad
+func Review(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req reviewRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		id, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		ad, err := client.Review(c, &proto.ReviewAdRequest{
+			AdId:        int64(id),
+			ModeratorId: userID,
+			Approve:     req.Approve,
+			Reason:      req.Reason,
+		})
+		errors.ProceedResult(c, responses.AdSuccess(ad), err)
+	}
+}
+
+// ModerationQueue returns the page of pending ads
+func ModerationQueue(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := paging.GetParams(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		list, err := client.GetModerationQueue(c, &proto.ModerationQueueRequest{
+			ModeratorId: userID,
+			PageSize:    page.Size,
+			PageToken:   page.Token,
+			OrderBy:     page.Sort,
+			WithTotal:   page.WithTotal,
+		})
+		res := responses.AdsSuccess(list)
+		if err == nil {
+			res = paging.Apply(c, page, res, list.GetNextPageToken(), list.GetTotal())
+		}
+		errors.ProceedResult(c, res, err)
+	}
+}
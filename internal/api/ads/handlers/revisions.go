@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"goads/internal/ads/proto"
+	"goads/internal/api/ads/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/api/paging"
+	"net/http"
+	"strconv"
+)
+
+// ListRevisions returns the page of revisions of the ad
+func ListRevisions(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		page, err := paging.GetParams(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		list, err := client.ListRevisions(c, &proto.ListRevisionsRequest{
+			AdId:      int64(id),
+			AuthorId:  userID,
+			PageSize:  page.Size,
+			PageToken: page.Token,
+			OrderBy:   page.Sort,
+			WithTotal: page.WithTotal,
+		})
+		res := responses.RevisionsSuccess(list)
+		if err == nil {
+			res = paging.Apply(c, page, res, list.GetNextPageToken(), list.GetTotal())
+		}
+		errors.ProceedResult(c, res, err)
+	}
+}
+
+// GetRevision returns the revision with the diff since the version from against parameter. It is the previous
+// version by default
+func GetRevision(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := revisionRequest(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		against := req.Version - 1
+		if s, ok := c.GetQuery("against"); ok {
+			v, err := strconv.ParseInt(s, 10, 32)
+			if err != nil || v < 0 {
+				c.JSON(http.StatusBadRequest, errors.Response(fmt.Errorf("invalid version: %s", s)))
+				return
+			}
+			against = int32(v)
+		}
+		revision, err := client.GetRevision(c, req)
+		if err != nil {
+			errors.ProceedResult(c, nil, err)
+			return
+		}
+		var previous *proto.RevisionResponse
+		if against > 0 {
+			previous, err = client.GetRevision(c, &proto.RevisionRequest{
+				AdId:     req.AdId,
+				AuthorId: req.AuthorId,
+				Version:  against,
+			})
+		}
+		res := responses.RevisionToResponse(revision)
+		res.Diff = responses.DiffToResponse(revision, previous)
+		errors.ProceedResult(c, responses.RevisionSuccess(res), err)
+	}
+}
+
+// RestoreRevision updates the ad with content of the revision
+func RestoreRevision(client proto.AdServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := revisionRequest(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		ad, err := client.RestoreRevision(c, req)
+		errors.ProceedResult(c, responses.AdSuccess(ad), err)
+	}
+}
+
+// revisionRequest reads the ad and the version from the path
+func revisionRequest(c *gin.Context) (*proto.RevisionRequest, error) {
+	id, err := strconv.Atoi(c.Param("ad_id"))
+	if err != nil {
+		return nil, err
+	}
+	version, err := strconv.ParseInt(c.Param("version"), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.RevisionRequest{AdId: int64(id), AuthorId: userID, Version: int32(version)}, nil
+}
@@ -14,6 +14,25 @@ type Ad struct {
 	CreateDate time.Time `json:"create_date"`
 	UpdateDate time.Time `json:"update_date"`
 	Published  bool      `json:"published"`
+	CampaignID int64     `json:"campaign_id"`
+	Snippet    string    `json:"snippet,omitempty"`
+	// Status is the status of the moderation, RejectReason is set only for rejected ads
+	Status       string `json:"status"`
+	RejectReason string `json:"reject_reason,omitempty"`
+}
+
+// Campaign is ads campaign. Nil dates and zero caps mean no limits
+type Campaign struct {
+	ID             int64      `json:"id"`
+	AuthorID       int64      `json:"author_id"`
+	Name           string     `json:"name"`
+	StartDate      *time.Time `json:"start_date"`
+	EndDate        *time.Time `json:"end_date"`
+	TotalCap       int64      `json:"total_cap"`
+	DailyCap       int64      `json:"daily_cap"`
+	Impressions    int64      `json:"impressions"`
+	DayImpressions int64      `json:"day_impressions"`
+	Running        bool       `json:"running"`
 }
 
 func AdToResponse(a *proto.AdResponse) Ad {
@@ -21,13 +40,51 @@ func AdToResponse(a *proto.AdResponse) Ad {
 		return Ad{}
 	}
 	return Ad{
-		ID:         a.Id,
-		Title:      a.Title,
-		Text:       a.Text,
-		AuthorID:   a.AuthorId,
-		CreateDate: time.UnixMilli(a.CreateDate).UTC(),
-		UpdateDate: time.UnixMilli(a.UpdateDate).UTC(),
-		Published:  a.Published,
+		ID:           a.Id,
+		Title:        a.Title,
+		Text:         a.Text,
+		AuthorID:     a.AuthorId,
+		CreateDate:   time.UnixMilli(a.CreateDate).UTC(),
+		UpdateDate:   time.UnixMilli(a.UpdateDate).UTC(),
+		Published:    a.Published,
+		CampaignID:   a.CampaignId,
+		Snippet:      a.Snippet,
+		Status:       a.Status,
+		RejectReason: a.RejectReason,
+	}
+}
+
+// millisToTime converts unix milliseconds to time. 0 means unset time
+func millisToTime(ms int64) *time.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := time.UnixMilli(ms).UTC()
+	return &t
+}
+
+func CampaignToResponse(c *proto.CampaignResponse) Campaign {
+	if c == nil {
+		return Campaign{}
+	}
+	return Campaign{
+		ID:             c.Id,
+		AuthorID:       c.AuthorId,
+		Name:           c.Name,
+		StartDate:      millisToTime(c.StartDate),
+		EndDate:        millisToTime(c.EndDate),
+		TotalCap:       c.TotalCap,
+		DailyCap:       c.DailyCap,
+		Impressions:    c.Impressions,
+		DayImpressions: c.DayImpressions,
+		Running:        c.Running,
+	}
+}
+
+func CampaignSuccess(c *proto.CampaignResponse) gin.H {
+	return gin.H{
+		"data":  CampaignToResponse(c),
+		"error": nil,
 	}
 }
 
@@ -39,8 +96,8 @@ func AdSuccess(a *proto.AdResponse) gin.H {
 }
 
 func AdsSuccess(l *proto.AdsResponse) gin.H {
-	responses := make([]Ad, len(l.List))
-	for i, ad := range l.List {
+	responses := make([]Ad, len(l.GetList()))
+	for i, ad := range l.GetList() {
 		responses[i] = AdToResponse(ad)
 	}
 	return gin.H{
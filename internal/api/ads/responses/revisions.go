@@ -0,0 +1,83 @@
+package responses
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/ads/proto"
+	"goads/internal/pkg/textdiff"
+	"time"
+)
+
+// Revision is a version of the ad's content. Diff is set only for a single revision
+type Revision struct {
+	AdID       int64         `json:"ad_id"`
+	Version    int32         `json:"version"`
+	EditorID   int64         `json:"editor_id"`
+	Title      string        `json:"title"`
+	Text       string        `json:"text"`
+	CreateDate time.Time     `json:"create_date"`
+	Diff       *RevisionDiff `json:"diff,omitempty"`
+}
+
+// RevisionDiff shows changes of the revision since the version Against. It is 0 for the first revision,
+// which is compared with empty content
+type RevisionDiff struct {
+	Against int32      `json:"against"`
+	Title   []DiffPart `json:"title"`
+	Text    []DiffPart `json:"text"`
+}
+
+// DiffPart is a fragment of the text with operation equal, insert or delete
+type DiffPart struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func RevisionToResponse(r *proto.RevisionResponse) Revision {
+	if r == nil {
+		return Revision{}
+	}
+	return Revision{
+		AdID:       r.AdId,
+		Version:    r.Version,
+		EditorID:   r.EditorId,
+		Title:      r.Title,
+		Text:       r.Text,
+		CreateDate: time.UnixMilli(r.CreateDate).UTC(),
+	}
+}
+
+// DiffToResponse returns changes of the revision since the previous one, which is nil for the first revision
+func DiffToResponse(revision *proto.RevisionResponse, previous *proto.RevisionResponse) *RevisionDiff {
+	diff := RevisionDiff{
+		Against: previous.GetVersion(),
+		Title:   diffPartsToResponse(textdiff.Words(previous.GetTitle(), revision.GetTitle())),
+		Text:    diffPartsToResponse(textdiff.Words(previous.GetText(), revision.GetText())),
+	}
+	return &diff
+}
+
+func diffPartsToResponse(parts []textdiff.Part) []DiffPart {
+	res := make([]DiffPart, len(parts))
+	for i, p := range parts {
+		res[i] = DiffPart{Op: p.Op, Text: p.Text}
+	}
+	return res
+}
+
+func RevisionSuccess(r Revision) gin.H {
+	return gin.H{
+		"data":  r,
+		"error": nil,
+	}
+}
+
+func RevisionsSuccess(l *proto.RevisionsResponse) gin.H {
+	res := make([]Revision, len(l.GetList()))
+	for i, r := range l.GetList() {
+		res[i] = RevisionToResponse(r)
+	}
+	return gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
@@ -6,14 +6,40 @@ import (
 	"goads/internal/api/ads/handlers"
 	"goads/internal/api/auth"
 	authProto "goads/internal/auth/proto"
+	"goads/internal/pkg/permissions"
 )
 
 func SetRoutes(r gin.IRouter, authSvc authProto.AuthServiceClient, client proto.AdServiceClient) {
+	r.GET("/ads/browse", handlers.Browse(client))
+
 	g := r.Group("/ads")
-	g.Use(auth.Middleware(authSvc))
+	g.Use(auth.Middleware(authSvc, permissions.ResourceAds))
 	g.GET("/", handlers.GetFiltered(client))
 	g.POST("/", handlers.Create(client))
 	g.PUT("/:ad_id/status", handlers.ChangeStatus(client))
 	g.PUT("/:ad_id", handlers.Update(client))
 	g.DELETE("/:ad_id", handlers.Delete(client))
+	g.PUT("/:ad_id/campaign", handlers.SetCampaign(client))
+	g.POST("/:ad_id/submit", handlers.Submit(client))
+	g.POST("/:ad_id/archive", handlers.Archive(client))
+	g.GET("/:ad_id/revisions", handlers.ListRevisions(client))
+	g.GET("/:ad_id/revisions/:version", handlers.GetRevision(client))
+	g.POST("/:ad_id/revisions/:version/restore", handlers.RestoreRevision(client))
+
+	campaigns := r.Group("/campaigns")
+	campaigns.Use(auth.Middleware(authSvc, permissions.ResourceAds))
+	campaigns.POST("/", handlers.CreateCampaign(client))
+	campaigns.GET("/:campaign_id", handlers.GetCampaign(client))
+	campaigns.DELETE("/:campaign_id", handlers.DeleteCampaign(client))
+}
+
+// SetAdminRoutes sets routes for moderation of ads created by any user. Group r must be guarded by auth.Middleware
+func SetAdminRoutes(r gin.IRouter, client proto.AdServiceClient) {
+	g := r.Group("/ads")
+	g.PUT("/:ad_id/status", auth.RoleOnly(permissions.RoleModerator, permissions.RoleAdmin), handlers.ChangeStatus(client))
+	g.PUT("/:ad_id", auth.RoleOnly(permissions.RoleAdmin), handlers.Update(client))
+	g.DELETE("/:ad_id", auth.RoleOnly(permissions.RoleAdmin), handlers.Delete(client))
+	moderators := auth.RoleOnly(permissions.RoleModerator, permissions.RoleAdmin)
+	g.GET("/queue", moderators, handlers.ModerationQueue(client))
+	g.POST("/:ad_id/review", moderators, handlers.Review(client))
 }
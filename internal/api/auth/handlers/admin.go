@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+	"net/http"
+	"strconv"
+)
+
+// GetByID returns any user by ID from the path
+func GetByID(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		user, err := app.GetByID(c, &proto.GetUserByIDRequest{Id: id})
+		errors.ProceedResult(c, responses.UserSuccess(user), err)
+	}
+}
+
+type setRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// SetRole changes role of the user by ID from the path
+func SetRole(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		var req setRoleRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		user, err := app.SetRole(c, &proto.SetUserRoleRequest{
+			Id:   id,
+			Role: req.Role,
+		})
+		errors.ProceedResult(c, responses.UserSuccess(user), err)
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"goads/internal/api/auth/utils"
 	"goads/internal/api/errors"
 	"goads/internal/auth/proto"
+	"net/http"
 )
 
+// Delete schedules deletion of the user. The account can be restored by the link from email during the grace period
+
 func Delete(a proto.AuthServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := utils.GetUserID(c)
@@ -19,3 +22,16 @@ func Delete(a proto.AuthServiceClient) gin.HandlerFunc {
 		errors.ProceedResult(c, responses.EmptySuccess(), err)
 	}
 }
+
+// RestoreUser cancels deletion of the user by token from the link sent on deletion
+func RestoreUser(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req proto.RestoreUserRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		user, err := a.RestoreUser(c, &req)
+		errors.ProceedResult(c, responses.UserSuccess(user), err)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"net/http"
+)
+
+// JWKS responds with public keys in JSON Web Key Set format, so it is not wrapped to data
+func JWKS(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := a.GetPublicKeys(c, &emptypb.Empty{})
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		c.JSON(http.StatusOK, responses.KeysToJWKS(keys))
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+	"net/http"
+	"strconv"
+)
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+func CreateAPIKey(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createAPIKeyRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		key, err := app.CreateAPIKey(c, &proto.CreateAPIKeyRequest{
+			UserId: id,
+			Name:   req.Name,
+			Scopes: req.Scopes,
+		})
+		errors.ProceedResult(c, responses.APIKeySuccess(key), err)
+	}
+}
+
+func GetAPIKeys(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		keys, err := app.GetAPIKeys(c, &proto.GetUserByIDRequest{Id: id})
+		errors.ProceedResult(c, responses.APIKeysSuccess(keys), err)
+	}
+}
+
+func RevokeAPIKey(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID, err := strconv.ParseInt(c.Param("key_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		_, err = app.RevokeAPIKey(c, &proto.RevokeAPIKeyRequest{
+			UserId: id,
+			Id:     keyID,
+		})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
@@ -15,7 +15,25 @@ func Login(a proto.AuthServiceClient) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, errors.Response(err))
 			return
 		}
+		// failed attempts are limited by address of the client, so it cannot be set by the request body
+		req.Ip = c.ClientIP()
+		req.UserAgent = c.Request.UserAgent()
 		token, err := a.Authenticate(c, &req)
 		errors.ProceedResult(c, responses.TokenSuccess(token), err)
 	}
 }
+
+// LoginTOTP finishes login of the user with enabled second factor by challenge token and TOTP or recovery code
+func LoginTOTP(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req proto.AuthenticateTOTPRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		req.Ip = c.ClientIP()
+		req.UserAgent = c.Request.UserAgent()
+		token, err := a.AuthenticateTOTP(c, &req)
+		errors.ProceedResult(c, responses.TokenSuccess(token), err)
+	}
+}
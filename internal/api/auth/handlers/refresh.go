@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+	"net/http"
+)
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func Refresh(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req refreshRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		token, err := a.Refresh(c, &proto.RefreshRequest{RefreshToken: req.RefreshToken})
+		errors.ProceedResult(c, responses.TokenSuccess(token), err)
+	}
+}
+
+func Logout(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req refreshRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		_, err := a.Logout(c, &proto.RefreshRequest{
+			RefreshToken: req.RefreshToken,
+			AccessToken:  utils.ExtractToken(c),
+		})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+)
+
+func ListSessions(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		sessions, err := app.ListSessions(c, &proto.GetUserByIDRequest{Id: id})
+		errors.ProceedResult(c, responses.SessionsSuccess(sessions, c.GetString("session")), err)
+	}
+}
+
+func RevokeSession(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		_, err = app.RevokeSession(c, &proto.UserSessionRequest{
+			UserId:    id,
+			SessionId: c.Param("session_id"),
+		})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
+
+// RevokeAllOtherSessions logs the user out everywhere except the session of the request
+func RevokeAllOtherSessions(app proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		_, err = app.RevokeAllOtherSessions(c, &proto.UserSessionRequest{
+			UserId:    id,
+			SessionId: c.GetString("session"),
+		})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+	"net/http"
+)
+
+type totpCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// EnrollTOTP responds with otpauth:// URI and recovery codes. They are shown only once
+func EnrollTOTP(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		enrollment, err := a.EnrollTOTP(c, &proto.GetUserByIDRequest{Id: id})
+		errors.ProceedResult(c, responses.TOTPSuccess(enrollment), err)
+	}
+}
+
+// updateTOTP handles requests changing the second factor by a code
+func updateTOTP(update func(c *gin.Context, req *proto.TOTPCodeRequest) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req totpCodeRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		err = update(c, &proto.TOTPCodeRequest{UserId: id, Code: req.Code})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
+
+func ConfirmTOTP(a proto.AuthServiceClient) gin.HandlerFunc {
+	return updateTOTP(func(c *gin.Context, req *proto.TOTPCodeRequest) error {
+		_, err := a.ConfirmTOTP(c, req)
+		return err
+	})
+}
+
+func DisableTOTP(a proto.AuthServiceClient) gin.HandlerFunc {
+	return updateTOTP(func(c *gin.Context, req *proto.TOTPCodeRequest) error {
+		_, err := a.DisableTOTP(c, req)
+		return err
+	})
+}
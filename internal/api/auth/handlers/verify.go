@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/responses"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/auth/proto"
+	"net/http"
+)
+
+// VerifyEmail confirms email by token from the link sent to the user
+func VerifyEmail(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := a.VerifyEmail(c, &proto.VerifyEmailRequest{Token: c.Query("token")})
+		errors.ProceedResult(c, responses.UserSuccess(user), err)
+	}
+}
+
+func SendVerification(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		_, err = a.SendVerification(c, &proto.GetUserByIDRequest{Id: id})
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
+
+func RequestPasswordReset(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req proto.PasswordResetRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		_, err := a.RequestPasswordReset(c, &req)
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
+
+func ResetPassword(a proto.AuthServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req proto.ResetPasswordRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		_, err := a.ResetPassword(c, &req)
+		errors.ProceedResult(c, responses.EmptySuccess(), err)
+	}
+}
@@ -6,17 +6,75 @@ import (
 	"goads/internal/api/auth/utils"
 	"goads/internal/api/errors"
 	"goads/internal/auth/proto"
+	"goads/internal/pkg/permissions"
 	"net/http"
 )
 
-func Middleware(client proto.AuthServiceClient) func(c *gin.Context) {
+// APIKeyHeader is a header with API key used instead of bearer token
+const APIKeyHeader = "X-API-Key"
+
+// Middleware authenticates user by bearer token or API key. API keys are accepted only by routes
+// of given resources and only if the key has scope for the request
+func Middleware(client proto.AuthServiceClient, resources ...string) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		user, err := client.Validate(c, &proto.ValidateRequest{Token: utils.ExtractToken(c)})
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			user, err := client.Validate(c, &proto.ValidateRequest{Token: utils.ExtractToken(c)})
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, errors.Response(fmt.Errorf("invalid token")))
+				return
+			}
+			authorize(c, user)
+			return
+		}
+
+		user, err := client.ValidateAPIKey(c, &proto.ValidateRequest{Token: key})
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errors.Response(fmt.Errorf("invalid token")))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errors.Response(fmt.Errorf("invalid API key")))
+			return
+		}
+		for _, resource := range resources {
+			if permissions.HasScope(user.Scopes, resource, c.Request.Method) {
+				authorize(c, user)
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, errors.Response(fmt.Errorf("API key has no scope for the request")))
+	}
+}
+
+// authorize saves authenticated user to the context and proceeds the request
+func authorize(c *gin.Context, user *proto.UserIDResponse) {
+	c.Set("userID", user.Id)
+	c.Set("verified", user.Verified)
+	c.Set("role", user.Role)
+	c.Set("session", user.Session)
+	// role is passed to services by permissions.UnaryClientInterceptor from the request context
+	c.Request = c.Request.WithContext(permissions.WithRole(c.Request.Context(), user.Role))
+	c.Next()
+}
+
+// VerifiedOnly rejects users with unverified email. It must be used after Middleware
+func VerifiedOnly() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if !c.GetBool("verified") {
+			c.AbortWithStatusJSON(http.StatusForbidden, errors.Response(fmt.Errorf("email is not verified")))
 			return
 		}
-		c.Set("userID", user.Id)
 		c.Next()
 	}
 }
+
+// RoleOnly rejects users whose role is not one of roles. It must be used after Middleware
+func RoleOnly(roles ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, errors.Response(fmt.Errorf("role %s is not allowed", role)))
+	}
+}
@@ -3,30 +3,138 @@ package responses
 import (
 	"github.com/gin-gonic/gin"
 	"goads/internal/auth/proto"
+	"time"
 )
 
 type User struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Verified  bool   `json:"verified"`
+	Role      string `json:"role"`
+	TwoFactor bool   `json:"two_factor"`
+	// DeleteDate is set if the user is scheduled for deletion
+	DeleteDate *time.Time `json:"delete_date,omitempty"`
 }
 
 func UserToResponse(u *proto.UserInfoResponse) User {
 	if u == nil {
 		return User{}
 	}
-	return User{
-		ID:    u.Id,
-		Name:  u.Name,
-		Email: u.Email,
+	user := User{
+		ID:        u.Id,
+		Name:      u.Name,
+		Email:     u.Email,
+		Verified:  u.Verified,
+		Role:      u.Role,
+		TwoFactor: u.TwoFactor,
 	}
+	if u.DeleteDate != 0 {
+		date := time.UnixMilli(u.DeleteDate).UTC()
+		user.DeleteDate = &date
+	}
+	return user
+}
+
+// Token is short-lived access token with refresh token used to get the next one.
+// If the second factor is required, only challenge token is set
+type Token struct {
+	Token          string `json:"token"`
+	RefreshToken   string `json:"refresh_token"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
 }
 
-func TokenToResponse(t *proto.TokenResponse) string {
+func TokenToResponse(t *proto.TokenResponse) Token {
 	if t == nil {
-		return ""
+		return Token{}
+	}
+	return Token{
+		Token:          t.Token,
+		RefreshToken:   t.RefreshToken,
+		ChallengeToken: t.ChallengeToken,
+	}
+}
+
+type APIKey struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	Scopes     []string  `json:"scopes"`
+	CreateDate time.Time `json:"create_date"`
+}
+
+func APIKeyToResponse(k *proto.APIKey) APIKey {
+	if k == nil {
+		return APIKey{}
+	}
+	return APIKey{
+		ID:         k.Id,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreateDate: time.UnixMilli(k.CreateDate).UTC(),
+	}
+}
+
+// Session is a login of the user. Current is true for the session of the request
+type Session struct {
+	ID           string    `json:"id"`
+	Device       string    `json:"device"`
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent"`
+	CreateDate   time.Time `json:"create_date"`
+	LastUsedDate time.Time `json:"last_used_date"`
+	Current      bool      `json:"current"`
+}
+
+func SessionToResponse(s *proto.Session, current string) Session {
+	if s == nil {
+		return Session{}
 	}
-	return t.Token
+	return Session{
+		ID:           s.Id,
+		Device:       s.Device,
+		IP:           s.Ip,
+		UserAgent:    s.UserAgent,
+		CreateDate:   time.UnixMilli(s.CreateDate).UTC(),
+		LastUsedDate: time.UnixMilli(s.LastUsedDate).UTC(),
+		Current:      s.Id == current,
+	}
+}
+
+// CreatedAPIKey contains the raw key which is shown only once
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// JWK is RSA public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	ID        string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func KeysToJWKS(keys *proto.PublicKeysResponse) JWKS {
+	res := JWKS{Keys: make([]JWK, 0, len(keys.GetKeys()))}
+	for _, k := range keys.GetKeys() {
+		res.Keys = append(res.Keys, JWK{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: k.Alg,
+			ID:        k.Kid,
+			Modulus:   k.N,
+			Exponent:  k.E,
+		})
+	}
+	return res
 }
 
 func UserSuccess(u *proto.UserInfoResponse) gin.H {
@@ -59,6 +167,53 @@ func UserWithTokenSuccess(reg *proto.RegisterResponse) gin.H {
 	}
 }
 
+func APIKeySuccess(k *proto.CreateAPIKeyResponse) gin.H {
+	return gin.H{
+		"data": CreatedAPIKey{
+			APIKey: APIKeyToResponse(k.GetKey()),
+			Key:    k.GetSecret(),
+		},
+		"error": nil,
+	}
+}
+
+func APIKeysSuccess(keys *proto.APIKeysResponse) gin.H {
+	res := make([]APIKey, len(keys.GetKeys()))
+	for i, k := range keys.GetKeys() {
+		res[i] = APIKeyToResponse(k)
+	}
+	return gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+type TOTPEnrollment struct {
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func TOTPSuccess(e *proto.EnrollTOTPResponse) gin.H {
+	return gin.H{
+		"data": TOTPEnrollment{
+			URI:           e.GetUri(),
+			RecoveryCodes: e.GetRecoveryCodes(),
+		},
+		"error": nil,
+	}
+}
+
+func SessionsSuccess(sessions *proto.SessionsResponse, current string) gin.H {
+	res := make([]Session, len(sessions.GetSessions()))
+	for i, s := range sessions.GetSessions() {
+		res[i] = SessionToResponse(s, current)
+	}
+	return gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
 func EmptySuccess() gin.H {
 	return gin.H{
 		"data":  "",
@@ -4,11 +4,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"goads/internal/api/auth/handlers"
 	"goads/internal/auth/proto"
+	"goads/internal/pkg/permissions"
 )
 
+// SetPublicRoutes sets routes which should be placed in the root
+func SetPublicRoutes(r gin.IRouter, client proto.AuthServiceClient) {
+	r.GET("/.well-known/jwks.json", handlers.JWKS(client))
+}
+
 func SetRoutes(r gin.IRouter, client proto.AuthServiceClient) {
 	r.POST("/register", handlers.Register(client))
 	r.POST("/login", handlers.Login(client))
+	r.POST("/login/2fa", handlers.LoginTOTP(client))
+	r.POST("/refresh", handlers.Refresh(client))
+	r.POST("/logout", handlers.Logout(client))
+	r.GET("/verify", handlers.VerifyEmail(client))
+	r.POST("/password/reset/request", handlers.RequestPasswordReset(client))
+	r.POST("/password/reset", handlers.ResetPassword(client))
+	r.POST("/restore", handlers.RestoreUser(client))
 
 	auth := r.Group("/user")
 	auth.Use(Middleware(client))
@@ -16,5 +29,23 @@ func SetRoutes(r gin.IRouter, client proto.AuthServiceClient) {
 	auth.PUT("/name", handlers.ChangeName(client))
 	auth.PUT("/email", handlers.ChangeEmail(client))
 	auth.PUT("/password", handlers.ChangePassword(client))
+	auth.POST("/verify", handlers.SendVerification(client))
 	auth.DELETE("/", handlers.Delete(client))
+	auth.GET("/keys", handlers.GetAPIKeys(client))
+	auth.POST("/keys", handlers.CreateAPIKey(client))
+	auth.DELETE("/keys/:key_id", handlers.RevokeAPIKey(client))
+	auth.POST("/2fa", handlers.EnrollTOTP(client))
+	auth.POST("/2fa/confirm", handlers.ConfirmTOTP(client))
+	auth.DELETE("/2fa", handlers.DisableTOTP(client))
+	auth.GET("/sessions", handlers.ListSessions(client))
+	auth.DELETE("/sessions", handlers.RevokeAllOtherSessions(client))
+	auth.DELETE("/sessions/:session_id", handlers.RevokeSession(client))
+}
+
+// SetAdminRoutes sets routes for managing users. Group r must be guarded by Middleware
+func SetAdminRoutes(r gin.IRouter, client proto.AuthServiceClient) {
+	users := r.Group("/users")
+	users.Use(RoleOnly(permissions.RoleAdmin))
+	users.GET("/:user_id", handlers.GetByID(client))
+	users.PUT("/:user_id/role", handlers.SetRole(client))
 }
@@ -0,0 +1,233 @@
+package verifier
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"goads/internal/auth/proto"
+	"goads/internal/auth/tokens"
+	"goads/internal/pkg/permissions"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"log"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+var errUnknownKey = errors.New("unknown key")
+
+// staleRevocations is how many revocation fetches can fail before tokens are validated by the auth service
+const staleRevocations = 3
+
+// Client is AuthServiceClient which verifies RS256 tokens locally by public keys of the auth service
+// and rejects tokens from its revocation list. Validate falls back to the auth service if the key of the token
+// is unknown or the revocation list has not been fetched for several revocation intervals
+type Client struct {
+	proto.AuthServiceClient
+	mu       *sync.RWMutex
+	keys     map[string]*rsa.PublicKey
+	fetched  *time.Time
+	interval time.Duration
+	minFetch time.Duration
+
+	revoked         *tokens.Revocations
+	revokedFetched  *time.Time
+	revokedInterval time.Duration
+}
+
+func decodeKey(k *proto.PublicKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// Fetch replaces cached keys and revocations with ones of the auth service
+func (c Client) Fetch(ctx context.Context) error {
+	return errors.Join(c.FetchKeys(ctx), c.FetchRevocations(ctx))
+}
+
+// FetchKeys replaces cached keys with public keys of the auth service
+func (c Client) FetchKeys(ctx context.Context) error {
+	res, err := c.GetPublicKeys(ctx, &emptypb.Empty{})
+	if err != nil {
+		return fmt.Errorf("cannot fetch public keys: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(res.Keys))
+	for _, k := range res.Keys {
+		if k.Alg != jwt.SigningMethodRS256.Alg() {
+			continue
+		}
+		key, err := decodeKey(k)
+		if err != nil {
+			return fmt.Errorf("cannot decode public key %s: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for kid := range c.keys {
+		delete(c.keys, kid)
+	}
+	for kid, key := range keys {
+		c.keys[kid] = key
+	}
+	*c.fetched = time.Now()
+	return nil
+}
+
+// FetchRevocations replaces cached revocations with the revocation list of the auth service
+func (c Client) FetchRevocations(ctx context.Context) error {
+	res, err := c.GetRevocations(ctx, &emptypb.Empty{})
+	if err != nil {
+		return fmt.Errorf("cannot fetch revocations: %w", err)
+	}
+	list := tokens.Revocations{
+		Tokens:   make(map[string]time.Time, len(res.Tokens)),
+		Sessions: make(map[string]time.Time, len(res.Sessions)),
+		Users:    make(map[int64]time.Time, len(res.Users)),
+	}
+	for jti, expiresAt := range res.Tokens {
+		list.Tokens[jti] = time.UnixMilli(expiresAt).UTC()
+	}
+	for id, expiresAt := range res.Sessions {
+		list.Sessions[id] = time.UnixMilli(expiresAt).UTC()
+	}
+	for id, before := range res.Users {
+		list.Users[id] = time.UnixMilli(before).UTC()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.revoked = list
+	*c.revokedFetched = time.Now()
+	return nil
+}
+
+// isRevoked returns true if the token is revoked. The second value is false if cached revocations are too old
+// to be trusted
+func (c Client) isRevoked(jti string, session string, userID int64, issuedAt time.Time) (bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if time.Since(*c.revokedFetched) > staleRevocations*c.revokedInterval {
+		return false, false
+	}
+	return c.revoked.IsRevoked(jti, session, userID, issuedAt), true
+}
+
+// key returns cached public key. Keys are fetched again if the key is unknown,
+// but not more often than once per minFetch to protect the auth service from tokens with random kid
+func (c Client) key(ctx context.Context, kid string) (*rsa.PublicKey, bool) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	stale := time.Since(*c.fetched) >= c.minFetch
+	c.mu.RUnlock()
+	if ok || !stale {
+		return key, ok
+	}
+	if err := c.FetchKeys(ctx); err != nil {
+		log.Println(err)
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	return key, ok
+}
+
+func (c Client) Validate(ctx context.Context, in *proto.ValidateRequest, opts ...grpc.CallOption) (*proto.UserIDResponse, error) {
+	tok, err := jwt.Parse(in.Token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := c.key(ctx, kid)
+		if !ok {
+			return nil, errUnknownKey
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	if errors.Is(err, errUnknownKey) {
+		return c.AuthServiceClient.Validate(ctx, in, opts...)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	claims, ok := tok.Claims.(jwt.MapClaims)
+	if !ok || !tok.Valid {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	id, ok := claims["dat"].(float64)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	verified, _ := claims["ver"].(bool)
+	role, ok := claims["rol"].(string)
+	if !ok {
+		role = permissions.RoleUser
+	}
+	session, _ := claims["sid"].(string)
+	jti, _ := claims["jti"].(string)
+	iat, _ := claims["iat"].(float64)
+	revoked, ok := c.isRevoked(jti, session, int64(id), time.UnixMilli(int64(math.Round(iat*1000))).UTC())
+	if !ok {
+		return c.AuthServiceClient.Validate(ctx, in, opts...)
+	}
+	if revoked {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return &proto.UserIDResponse{Id: int64(id), Verified: verified, Role: role, Session: session}, nil
+}
+
+// Listen fetches keys every interval and revocations every revocations interval until ctx is done
+func (c Client) Listen(ctx context.Context) error {
+	keysTicker := time.NewTicker(c.interval)
+	defer keysTicker.Stop()
+	revokedTicker := time.NewTicker(c.revokedInterval)
+	defer revokedTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-keysTicker.C:
+			if err := c.FetchKeys(ctx); err != nil {
+				log.Println(err)
+			}
+		case <-revokedTicker.C:
+			if err := c.FetchRevocations(ctx); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// New wraps the client. Keys are fetched every interval and on unknown kid not more often than minFetch.
+// Revocations are fetched every revokedInterval, so revoked tokens can be accepted during this interval
+func New(client proto.AuthServiceClient, interval time.Duration, minFetch time.Duration, revokedInterval time.Duration) Client {
+	return Client{
+		AuthServiceClient: client,
+		mu:                &sync.RWMutex{},
+		keys:              make(map[string]*rsa.PublicKey),
+		fetched:           new(time.Time),
+		interval:          interval,
+		minFetch:          minFetch,
+		revoked:           &tokens.Revocations{},
+		revokedFetched:    new(time.Time),
+		revokedInterval:   revokedInterval,
+	}
+}
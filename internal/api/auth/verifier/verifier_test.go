@@ -0,0 +1,130 @@
+package verifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// authClient serves a single key and the revocation list and counts remote validations
+type authClient struct {
+	proto.AuthServiceClient
+	key       *rsa.PrivateKey
+	revoked   *proto.RevocationsResponse
+	validated *int
+}
+
+func (a authClient) GetRevocations(context.Context, *emptypb.Empty, ...grpc.CallOption) (*proto.RevocationsResponse, error) {
+	if a.revoked == nil {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	return a.revoked, nil
+}
+
+func (a authClient) GetPublicKeys(context.Context, *emptypb.Empty, ...grpc.CallOption) (*proto.PublicKeysResponse, error) {
+	return &proto.PublicKeysResponse{Keys: []*proto.PublicKey{{
+		Kid: "known",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(a.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(a.key.E)).Bytes()),
+	}}}, nil
+}
+
+func (a authClient) Validate(context.Context, *proto.ValidateRequest, ...grpc.CallOption) (*proto.UserIDResponse, error) {
+	*a.validated++
+	return &proto.UserIDResponse{Id: 2}, nil
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, expires time.Time) string {
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"dat": 1, "exp": expires.Unix()})
+	tok.Header["kid"] = kid
+	token, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestClient_Validate(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	validated := 0
+	c := New(authClient{key: key, revoked: &proto.RevocationsResponse{}, validated: &validated}, time.Minute, time.Minute, time.Minute)
+	require.NoError(t, c.Fetch(ctx))
+
+	res, err := c.Validate(ctx, &proto.ValidateRequest{Token: sign(t, key, "known", time.Now().Add(time.Hour))})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Id)
+	assert.Zero(t, validated, "token with known key must be verified locally")
+
+	_, err = c.Validate(ctx, &proto.ValidateRequest{Token: sign(t, key, "known", time.Now().Add(-time.Hour))})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = c.Validate(ctx, &proto.ValidateRequest{Token: sign(t, other, "known", time.Now().Add(time.Hour))})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "token signed by other key must be rejected")
+
+	res, err = c.Validate(ctx, &proto.ValidateRequest{Token: sign(t, other, "unknown", time.Now().Add(time.Hour))})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Id)
+	assert.Equal(t, 1, validated, "token with unknown key must be validated by the auth service")
+}
+
+func signClaims(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "known"
+	token, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestClient_Validate_Revoked(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	now := time.Now()
+	expires := now.Add(time.Hour).Unix()
+	issued := float64(now.UnixMilli()) / 1000
+	revoked := &proto.RevocationsResponse{
+		Tokens:   map[string]int64{"revoked": now.Add(time.Hour).UnixMilli()},
+		Sessions: map[string]int64{"logout": now.Add(time.Hour).UnixMilli()},
+		Users:    map[int64]int64{3: now.Add(time.Second).UnixMilli()},
+	}
+	validated := 0
+	c := New(authClient{key: key, revoked: revoked, validated: &validated}, time.Minute, time.Minute, time.Minute)
+	require.NoError(t, c.Fetch(ctx))
+
+	for name, claims := range map[string]jwt.MapClaims{
+		"by jti":     {"dat": 1, "exp": expires, "iat": issued, "jti": "revoked"},
+		"by session": {"dat": 1, "exp": expires, "iat": issued, "jti": "a", "sid": "logout"},
+		"by user":    {"dat": 3, "exp": expires, "iat": issued, "jti": "b"},
+	} {
+		_, err = c.Validate(ctx, &proto.ValidateRequest{Token: signClaims(t, key, claims)})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), name)
+	}
+	res, err := c.Validate(ctx, &proto.ValidateRequest{Token: signClaims(t, key, jwt.MapClaims{
+		"dat": 3, "exp": expires, "iat": float64(now.Add(2*time.Second).UnixMilli()) / 1000, "jti": "c",
+	})})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Id, "tokens issued after revocation of the user are valid")
+	assert.Zero(t, validated)
+
+	stale := New(authClient{key: key, validated: &validated}, time.Minute, time.Minute, time.Minute)
+	require.Error(t, stale.Fetch(ctx))
+	res, err = stale.Validate(ctx, &proto.ValidateRequest{Token: signClaims(t, key, jwt.MapClaims{"dat": 1, "exp": expires})})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Id)
+	assert.Equal(t, 1, validated, "token must be validated by the auth service without revocation list")
+}
@@ -3,9 +3,12 @@ package errors
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 func GetHTTPStatus(err error) int {
@@ -22,10 +25,14 @@ func GetHTTPStatus(err error) int {
 		return http.StatusUnauthorized
 	case codes.Unimplemented:
 		return http.StatusNotImplemented
-	case codes.AlreadyExists:
+	case codes.AlreadyExists, codes.FailedPrecondition:
 		return http.StatusConflict
 	case codes.NotFound:
 		return http.StatusNotFound
+	case codes.OutOfRange:
+		return http.StatusGone
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
 	}
 	if err != nil {
 		return http.StatusInternalServerError
@@ -47,8 +54,23 @@ func Response(err error) gin.H {
 	}
 }
 
+// retryAfter returns seconds from RetryInfo details of the status. It is false if there are no such details
+func retryAfter(err error) (int, bool) {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			return int(math.Ceil(info.RetryDelay.AsDuration().Seconds())), true
+		}
+	}
+	return 0, false
+}
+
 func ProceedResult(c *gin.Context, response gin.H, err error) {
 	code := GetHTTPStatus(err)
+	if code == http.StatusTooManyRequests {
+		if seconds, ok := retryAfter(err); ok {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+	}
 	if code == http.StatusOK {
 		c.JSON(code, response)
 	} else {
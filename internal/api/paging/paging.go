@@ -0,0 +1,61 @@
+package paging
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// Params are query parameters of the list page. Empty parameters are replaced by defaults of the service
+type Params struct {
+	Size      int32
+	Token     string
+	Sort      string
+	WithTotal bool
+}
+
+// GetParams reads page_size, page_token, sort and with_total query parameters. with_total without a value
+// requests total
+func GetParams(c *gin.Context) (Params, error) {
+	p := Params{
+		Token: c.Query("page_token"),
+		Sort:  c.Query("sort"),
+	}
+	if size, ok := c.GetQuery("page_size"); ok {
+		n, err := strconv.ParseInt(size, 10, 32)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid page size: %s", size)
+		}
+		p.Size = int32(n)
+	}
+	if withTotal, ok := c.GetQuery("with_total"); ok {
+		if withTotal == "" {
+			p.WithTotal = true
+		} else {
+			b, err := strconv.ParseBool(withTotal)
+			if err != nil {
+				return p, fmt.Errorf("invalid with_total: %s", withTotal)
+			}
+			p.WithTotal = b
+		}
+	}
+	return p, nil
+}
+
+// Apply sets Link header with the URL of the next page and X-Total-Count header if total is requested.
+// Both are also added to the response
+func Apply(c *gin.Context, p Params, response gin.H, nextToken string, total int64) gin.H {
+	response["next_page_token"] = nextToken
+	if nextToken != "" {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set("page_token", nextToken)
+		next.RawQuery = query.Encode()
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+	}
+	if p.WithTotal {
+		response["total"] = total
+		c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	}
+	return response
+}
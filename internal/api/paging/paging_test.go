@@ -0,0 +1,37 @@
+package paging
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetParams_WithTotal(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    bool
+		wantErr bool
+	}{
+		{query: "", want: false},
+		{query: "with_total", want: true},
+		{query: "with_total=true", want: true},
+		{query: "with_total=1", want: true},
+		{query: "with_total=false", want: false},
+		{query: "with_total=0", want: false},
+		{query: "with_total=maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/?"+tt.query, nil)
+			p, err := GetParams(c)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.WithTotal)
+		})
+	}
+}
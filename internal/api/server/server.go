@@ -21,9 +21,11 @@ type Server struct {
 	http.Server
 }
 
-func New(addr string, authSvc authProto.AuthServiceClient, shSvc shProto.ShortenerServiceClient, adsSvc adProto.AdServiceClient) *Server {
+func New(addr string, redirectCode int, authSvc authProto.AuthServiceClient, shSvc shProto.ShortenerServiceClient, adsSvc adProto.AdServiceClient) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
+	// handlers pass gin.Context to services, so values of request context (e.g. user's role) must be available
+	r.ContextWithFallback = true
 	s := Server{http.Server{
 		Addr:    addr,
 		Handler: r,
@@ -35,6 +37,13 @@ func New(addr string, authSvc authProto.AuthServiceClient, shSvc shProto.Shorten
 	auth.SetRoutes(api, authSvc)
 	ads.SetRoutes(api, authSvc, adsSvc)
 	urlshortener.SetRoutes(api, authSvc, shSvc)
+	admin := api.Group("/admin")
+	admin.Use(auth.Middleware(authSvc))
+	auth.SetAdminRoutes(admin, authSvc)
+	ads.SetAdminRoutes(admin, adsSvc)
+	urlshortener.SetAdminRoutes(admin, shSvc)
+	auth.SetPublicRoutes(r, authSvc)
+	urlshortener.SetPublicRoutes(r, shSvc, redirectCode)
 	return &s
 }
 
@@ -7,12 +7,26 @@ import (
 	"goads/internal/api/urlshortener/responses"
 	"goads/internal/urlshortener/proto"
 	"net/http"
+	"time"
 )
 
 type createRequest struct {
-	URL   string  `json:"url" binding:"required"`
-	Alias string  `json:"alias"`
-	Ads   []int64 `json:"ads"`
+	URL        string    `json:"url" binding:"required"`
+	Alias      string    `json:"alias"`
+	Ads        []int64   `json:"ads"`
+	AdDelay    *int32    `json:"ad_delay"`
+	ActiveFrom time.Time `json:"active_from"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Password   string    `json:"password"`
+	Strategy   string    `json:"strategy"`
+}
+
+// timeToMillis converts time to unix milliseconds. Zero time is converted to 0
+func timeToMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
 }
 
 func Create(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
@@ -29,10 +43,15 @@ func Create(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
 			return
 		}
 		link, err := shortener.Create(c, &proto.CreateRequest{
-			Url:      req.URL,
-			Alias:    req.Alias,
-			AuthorId: userID,
-			Ads:      req.Ads,
+			Url:        req.URL,
+			Alias:      req.Alias,
+			AuthorId:   userID,
+			Ads:        req.Ads,
+			AdDelay:    req.AdDelay,
+			ActiveFrom: timeToMillis(req.ActiveFrom),
+			ExpiresAt:  timeToMillis(req.ExpiresAt),
+			Password:   req.Password,
+			Strategy:   req.Strategy,
 		})
 		errors.ProceedResult(c, responses.LinkSuccess(link), err)
 	}
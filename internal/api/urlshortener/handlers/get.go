@@ -3,22 +3,142 @@ package handlers
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
 	"goads/internal/api/auth/utils"
 	"goads/internal/api/errors"
+	"goads/internal/api/paging"
+	"goads/internal/api/urlshortener/pages"
 	"goads/internal/api/urlshortener/responses"
 	"goads/internal/urlshortener/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net/http"
+	"net/url"
 	"strconv"
 )
 
+// PasswordHeader is the header with the password of the protected link
+const PasswordHeader = "X-Link-Password"
+
+// getRedirect requests the redirect. Password is taken from the submitted form for POST requests
+// and from PasswordHeader otherwise
+func getRedirect(c *gin.Context, shortener proto.ShortenerServiceClient) (*proto.RedirectResponse, error) {
+	password := c.GetHeader(PasswordHeader)
+	if c.Request.Method == http.MethodPost {
+		password = c.PostForm("password")
+	}
+	return shortener.GetRedirect(c, &proto.RedirectRequest{
+		Alias:     c.Param("alias"),
+		Referrer:  c.Request.Referer(),
+		UserAgent: c.Request.UserAgent(),
+		Ip:        c.ClientIP(),
+		Password:  password,
+	})
+}
+
 func GetRedirect(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		alias := c.Param("alias")
-		redirect, err := shortener.GetRedirect(c, &proto.GetByAliasRequest{Alias: alias})
+		redirect, err := getRedirect(c, shortener)
 		errors.ProceedResult(c, responses.RedirectSuccess(redirect), err)
 	}
 }
 
+// isWebURL checks that the URL can be safely opened by the browser from the interstitial page
+func isWebURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// passwordError returns the message shown on the password form and true if err is caused by the link protection
+func passwordError(err error) (string, bool) {
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return "", true
+	case codes.PermissionDenied:
+		return "Wrong password", true
+	case codes.ResourceExhausted:
+		return "Too many attempts, try again later", true
+	}
+	return "", false
+}
+
+// Redirect sends the visitor to the link's URL with the code if no ad is attached to the link,
+// otherwise renders the ad's interstitial page. Protected links render the password form, which is submitted
+// by POST to the same path. Clients preferring application/json receive the same response as from GetRedirect
+func Redirect(shortener proto.ShortenerServiceClient, code int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		redirect, err := getRedirect(c, shortener)
+		res := responses.RedirectToResponse(redirect)
+		if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+			errors.ProceedResult(c, responses.RedirectSuccess(redirect), err)
+			return
+		}
+		c.Header("Cache-Control", "no-store")
+		if msg, ok := passwordError(err); ok {
+			c.Render(errors.GetHTTPStatus(err), render.HTML{
+				Template: pages.Templates,
+				Name:     pages.PasswordName,
+				Data:     pages.Password{Error: msg},
+			})
+			return
+		}
+		if err != nil {
+			errors.ProceedResult(c, responses.RedirectSuccess(redirect), err)
+			return
+		}
+		redirectCode := code
+		if c.Request.Method == http.MethodPost {
+			redirectCode = http.StatusSeeOther
+		}
+		if res.Ad == nil {
+			c.Redirect(redirectCode, res.URL)
+			return
+		}
+		if !isWebURL(res.URL) {
+			errors.ProceedResult(c, responses.RedirectSuccess(redirect), err)
+			return
+		}
+		clickURL := fmt.Sprintf(
+			"/%s/ads/%d/click?impression=%s",
+			url.PathEscape(c.Param("alias")), res.Ad.ID, url.QueryEscape(res.Impression),
+		)
+		c.Render(http.StatusOK, render.HTML{
+			Template: pages.Templates,
+			Name:     pages.InterstitialName,
+			Data: pages.Interstitial{
+				URL:      res.URL,
+				Title:    res.Ad.Title,
+				Text:     res.Ad.Text,
+				Delay:    res.AdDelay,
+				ClickURL: clickURL,
+			},
+		})
+	}
+}
+
+// ClickAd records the click on the ad shown on the interstitial page. The impression query parameter
+// is the token of the shown ad
+func ClickAd(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.ParseInt(c.Param("ad_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		_, err = shortener.ClickAd(c, &proto.ClickAdRequest{
+			Alias:      c.Param("alias"),
+			AdId:       adID,
+			Impression: c.Query("impression"),
+			Ip:         c.ClientIP(),
+		})
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
 func GetByID(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("link_id"))
@@ -45,7 +165,22 @@ func GetByAuthor(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
 			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
 			return
 		}
-		links, err := shortener.GetByAuthor(c, &proto.GetByAuthorRequest{AuthorId: id})
-		errors.ProceedResult(c, responses.LinksSuccess(links), err)
+		page, err := paging.GetParams(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		links, err := shortener.GetByAuthor(c, &proto.GetByAuthorRequest{
+			AuthorId:  id,
+			PageSize:  page.Size,
+			PageToken: page.Token,
+			OrderBy:   page.Sort,
+			WithTotal: page.WithTotal,
+		})
+		res := responses.LinksSuccess(links)
+		if err == nil {
+			res = paging.Apply(c, page, res, links.GetNextPageToken(), links.GetTotal())
+		}
+		errors.ProceedResult(c, res, err)
 	}
 }
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"goads/internal/urlshortener/proto"
+	"google.golang.org/grpc"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// shortenerClient redirects every alias to the same URL
+type shortenerClient struct {
+	proto.ShortenerServiceClient
+}
+
+func (shortenerClient) GetRedirect(context.Context, *proto.RedirectRequest, ...grpc.CallOption) (*proto.RedirectResponse, error) {
+	return &proto.RedirectResponse{Link: &proto.LinkResponse{Url: "https://example.com"}}, nil
+}
+
+func TestRedirect_Code(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	redirect := Redirect(shortenerClient{}, http.StatusMovedPermanently)
+	r.GET("/:alias", redirect)
+	r.POST("/:alias", redirect)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(url.Values{"password": {"secret"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusSeeOther, w.Code, "submitted form is answered with See Other")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code, "configured code is kept after the form")
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"goads/internal/api/auth/utils"
+	"goads/internal/api/errors"
+	"goads/internal/api/urlshortener/responses"
+	"goads/internal/urlshortener/proto"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func GetStats(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("link_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		req := &proto.GetStatsRequest{LinkId: int64(id)}
+		if from, ok := c.GetQuery("from"); ok {
+			datetime, err := time.Parse(time.RFC3339Nano, from)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, errors.Response(err))
+				return
+			}
+			req.From = datetime.UnixMilli()
+		}
+		if to, ok := c.GetQuery("to"); ok {
+			datetime, err := time.Parse(time.RFC3339Nano, to)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, errors.Response(err))
+				return
+			}
+			req.To = datetime.UnixMilli()
+		}
+		req.AuthorId, err = utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		stats, err := shortener.GetStats(c, req)
+		errors.ProceedResult(c, responses.StatsSuccess(stats), err)
+	}
+}
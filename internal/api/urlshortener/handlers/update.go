@@ -12,8 +12,11 @@ import (
 	"strconv"
 )
 
+// updateAdRequest is data of adding or deleting the ad. Weight and Strategy are used only on adding
 type updateAdRequest struct {
-	Ad int64 `json:"ad" binding:"required"`
+	Ad       int64  `json:"ad" binding:"required"`
+	Weight   int32  `json:"weight"`
+	Strategy string `json:"strategy"`
 }
 
 func UpdateAdData(method func(context.Context, *proto.LinkAdRequest, ...grpc.CallOption) (*proto.LinkResponse, error)) gin.HandlerFunc {
@@ -36,6 +39,99 @@ func UpdateAdData(method func(context.Context, *proto.LinkAdRequest, ...grpc.Cal
 			LinkId:   int64(id),
 			AdId:     req.Ad,
 			AuthorId: userID,
+			Weight:   req.Weight,
+			Strategy: req.Strategy,
+		})
+		errors.ProceedResult(c, responses.LinkSuccess(link), err)
+	}
+}
+
+type updateURLRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+func UpdateURL(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("link_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		var req updateURLRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		link, err := shortener.UpdateURL(c, &proto.UpdateURLRequest{
+			Id:       int64(id),
+			AuthorId: userID,
+			Url:      req.URL,
+		})
+		errors.ProceedResult(c, responses.LinkSuccess(link), err)
+	}
+}
+
+type updateAdDelayRequest struct {
+	AdDelay *int32 `json:"ad_delay" binding:"required"`
+}
+
+func UpdateAdDelay(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("link_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		var req updateAdDelayRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		link, err := shortener.UpdateAdDelay(c, &proto.UpdateAdDelayRequest{
+			Id:       int64(id),
+			AuthorId: userID,
+			AdDelay:  *req.AdDelay,
+		})
+		errors.ProceedResult(c, responses.LinkSuccess(link), err)
+	}
+}
+
+type setPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// SetPassword protects the link by password. Empty password removes the protection
+func SetPassword(shortener proto.ShortenerServiceClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("link_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		var req setPasswordRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.Response(err))
+			return
+		}
+		userID, err := utils.GetUserID(c)
+		if err != nil {
+			c.JSON(errors.GetHTTPStatus(err), errors.HiddenResponse(err))
+			return
+		}
+		link, err := shortener.SetPassword(c, &proto.SetPasswordRequest{
+			Id:       int64(id),
+			AuthorId: userID,
+			Password: req.Password,
 		})
 		errors.ProceedResult(c, responses.LinkSuccess(link), err)
 	}
@@ -0,0 +1,32 @@
+package pages
+
+import (
+	"embed"
+	"html/template"
+)
+
+//go:embed templates/*.html
+var files embed.FS
+
+// Templates are HTML pages shown to visitors of short links
+var Templates = template.Must(template.ParseFS(files, "templates/*.html"))
+
+const (
+	InterstitialName = "interstitial.html"
+	PasswordName     = "password.html"
+)
+
+// Interstitial is data of the page with an ad shown for Delay seconds before redirecting to URL.
+// Clicks on the ad are reported to ClickURL
+type Interstitial struct {
+	URL      string
+	Title    string
+	Text     string
+	Delay    int
+	ClickURL string
+}
+
+// Password is data of the form asking the password of the protected link. Error is shown after the failed attempt
+type Password struct {
+	Error string
+}
@@ -3,23 +3,60 @@ package responses
 import (
 	"github.com/gin-gonic/gin"
 	"goads/internal/urlshortener/proto"
+	"time"
 )
 
 type Ad struct {
+	ID    int64  `json:"id"`
 	Title string `json:"title"`
 	Text  string `json:"text"`
 }
 
+// Redirect is the link's URL with the ad shown before redirecting. Impression is the token required
+// to report a click on the ad
 type Redirect struct {
-	URL string `json:"url"`
-	Ad  *Ad    `json:"ad"`
+	URL        string `json:"url"`
+	Ad         *Ad    `json:"ad"`
+	AdDelay    int    `json:"ad_delay,omitempty"`
+	Impression string `json:"impression,omitempty"`
 }
 
 type Link struct {
-	URL      string  `json:"url"`
-	Alias    string  `json:"alias"`
-	AuthorID int64   `json:"author_id"`
-	Ads      []int64 `json:"ads"`
+	URL        string          `json:"url"`
+	Alias      string          `json:"alias"`
+	AuthorID   int64           `json:"author_id"`
+	Ads        []int64         `json:"ads"`
+	AdDelay    int             `json:"ad_delay"`
+	ActiveFrom *time.Time      `json:"active_from"`
+	ExpiresAt  *time.Time      `json:"expires_at"`
+	Archived   bool            `json:"archived"`
+	Protected  bool            `json:"protected"`
+	Strategy   string          `json:"strategy"`
+	AdWeights  map[int64]int32 `json:"ad_weights"`
+	CreateDate time.Time       `json:"create_date"`
+	UpdateDate time.Time       `json:"update_date"`
+}
+
+type DayStat struct {
+	Day   time.Time `json:"day"`
+	Count int64     `json:"count"`
+}
+
+type ReferrerStat struct {
+	Referrer string `json:"referrer"`
+	Count    int64  `json:"count"`
+}
+
+type AdStat struct {
+	AdID  int64 `json:"ad_id"`
+	Count int64 `json:"count"`
+}
+
+type Stats struct {
+	Total      int64          `json:"total"`
+	ByDay      []DayStat      `json:"by_day"`
+	ByReferrer []ReferrerStat `json:"by_referrer"`
+	ByAd       []AdStat       `json:"by_ad"`
 }
 
 func RedirectToResponse(r *proto.RedirectResponse) Redirect {
@@ -30,13 +67,25 @@ func RedirectToResponse(r *proto.RedirectResponse) Redirect {
 		return Redirect{URL: r.Link.Url}
 	}
 	resAd := Ad{
+		ID:    r.Ad.Id,
 		Title: r.Ad.Title,
 		Text:  r.Ad.Text,
 	}
 	return Redirect{
-		URL: r.Link.Url,
-		Ad:  &resAd,
+		URL:        r.Link.Url,
+		Ad:         &resAd,
+		AdDelay:    int(r.Link.AdDelay),
+		Impression: r.Impression,
+	}
+}
+
+// millisToTime converts unix milliseconds to time. 0 means unset time
+func millisToTime(ms int64) *time.Time {
+	if ms == 0 {
+		return nil
 	}
+	t := time.UnixMilli(ms).UTC()
+	return &t
 }
 
 func LinkToResponse(l *proto.LinkResponse) Link {
@@ -44,10 +93,19 @@ func LinkToResponse(l *proto.LinkResponse) Link {
 		return Link{}
 	}
 	return Link{
-		URL:      l.Url,
-		Alias:    l.Alias,
-		AuthorID: l.AuthorId,
-		Ads:      l.Ads,
+		URL:        l.Url,
+		Alias:      l.Alias,
+		AuthorID:   l.AuthorId,
+		Ads:        l.Ads,
+		AdDelay:    int(l.AdDelay),
+		ActiveFrom: millisToTime(l.ActiveFrom),
+		ExpiresAt:  millisToTime(l.ExpiresAt),
+		Archived:   l.Archived,
+		Protected:  l.Protected,
+		Strategy:   l.Strategy,
+		AdWeights:  l.AdWeights,
+		CreateDate: time.UnixMilli(l.CreateDate).UTC(),
+		UpdateDate: time.UnixMilli(l.UpdateDate).UTC(),
 	}
 }
 
@@ -62,6 +120,28 @@ func LinksToResponse(l *proto.LinksResponse) []Link {
 	return res
 }
 
+func StatsToResponse(s *proto.StatsResponse) Stats {
+	if s == nil {
+		return Stats{}
+	}
+	res := Stats{
+		Total:      s.Total,
+		ByDay:      make([]DayStat, len(s.ByDay)),
+		ByReferrer: make([]ReferrerStat, len(s.ByReferrer)),
+		ByAd:       make([]AdStat, len(s.ByAd)),
+	}
+	for i, v := range s.ByDay {
+		res.ByDay[i] = DayStat{Day: time.UnixMilli(v.Day).UTC(), Count: v.Count}
+	}
+	for i, v := range s.ByReferrer {
+		res.ByReferrer[i] = ReferrerStat{Referrer: v.Referrer, Count: v.Count}
+	}
+	for i, v := range s.ByAd {
+		res.ByAd[i] = AdStat{AdID: v.AdId, Count: v.Count}
+	}
+	return res
+}
+
 func RedirectSuccess(r *proto.RedirectResponse) gin.H {
 	return gin.H{
 		"data":  RedirectToResponse(r),
@@ -83,6 +163,13 @@ func LinkSuccess(l *proto.LinkResponse) gin.H {
 	}
 }
 
+func StatsSuccess(s *proto.StatsResponse) gin.H {
+	return gin.H{
+		"data":  StatsToResponse(s),
+		"error": nil,
+	}
+}
+
 func EmptySuccess() gin.H {
 	return gin.H{
 		"data":  "",
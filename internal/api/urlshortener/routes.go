@@ -5,19 +5,40 @@ import (
 	"goads/internal/api/auth"
 	"goads/internal/api/urlshortener/handlers"
 	authProto "goads/internal/auth/proto"
+	"goads/internal/pkg/permissions"
 	shProto "goads/internal/urlshortener/proto"
 )
 
+// SetPublicRoutes sets routes for visitors of short links, which should be placed in the root
+func SetPublicRoutes(r gin.IRouter, shortener shProto.ShortenerServiceClient, redirectCode int) {
+	r.GET("/:alias", handlers.Redirect(shortener, redirectCode))
+	r.POST("/:alias", handlers.Redirect(shortener, redirectCode))
+	r.POST("/:alias/ads/:ad_id/click", handlers.ClickAd(shortener))
+}
+
 func SetRoutes(r gin.IRouter, authSvc authProto.AuthServiceClient, shortener shProto.ShortenerServiceClient) {
 	r.GET("link/:alias", handlers.GetRedirect(shortener))
 
 	links := r.Group("/links")
-	links.Use(auth.Middleware(authSvc))
+	links.Use(auth.Middleware(authSvc, permissions.ResourceLinks))
 	links.GET("/", handlers.GetByAuthor(shortener))
-	links.POST("/", handlers.Create(shortener))
+	links.POST("/", auth.VerifiedOnly(), handlers.Create(shortener))
 	links.GET("/:link_id", handlers.GetByID(shortener))
 	links.PUT("/:link_id", handlers.UpdateAlias(shortener))
+	links.PUT("/:link_id/url", handlers.UpdateURL(shortener))
+	links.PUT("/:link_id/delay", handlers.UpdateAdDelay(shortener))
+	links.PUT("/:link_id/password", handlers.SetPassword(shortener))
 	links.DELETE("/:link_id", handlers.Delete(shortener))
 	links.PUT("/:link_id/ads", handlers.UpdateAdData(shortener.AddAd))
 	links.DELETE("/:link_id/ads", handlers.UpdateAdData(shortener.DeleteAd))
+	links.GET("/:link_id/stats", handlers.GetStats(shortener))
+}
+
+// SetAdminRoutes sets routes for managing links created by any user. Group r must be guarded by auth.Middleware
+func SetAdminRoutes(r gin.IRouter, shortener shProto.ShortenerServiceClient) {
+	links := r.Group("/links")
+	links.Use(auth.RoleOnly(permissions.RoleAdmin))
+	links.PUT("/:link_id/url", handlers.UpdateURL(shortener))
+	links.DELETE("/:link_id", handlers.Delete(shortener))
+	links.GET("/:link_id/stats", handlers.GetStats(shortener))
 }
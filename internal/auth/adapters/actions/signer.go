@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
+	"goads/internal/pkg/errwrap"
+	"strings"
+	"time"
+)
+
+// payload is signed content of action token
+type payload struct {
+	ID      string `json:"jti"`
+	UserID  int64  `json:"uid"`
+	Email   string `json:"eml"`
+	Purpose string `json:"pur"`
+	Expires int64  `json:"exp"`
+}
+
+// Signer signs action tokens by HMAC-SHA256. Token is base64url-encoded payload and its signature separated by dot
+type Signer struct {
+	secret []byte
+}
+
+func (s Signer) sign(data string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s Signer) Sign(ctx context.Context, action tokens.Action) (string, error) {
+	const op = "actions.Sign"
+	if ctx.Err() != nil {
+		return "", errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+
+	data, err := json.Marshal(payload{
+		ID:      action.ID,
+		UserID:  action.UserID,
+		Email:   action.Email,
+		Purpose: action.Purpose,
+		Expires: action.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", errwrap.New(err, app.ServiceName, op).OnObject("user", action.UserID)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + s.sign(encoded), nil
+}
+
+// Parse checks signature of the token and returns its action. Expiration is not checked
+func (s Signer) Parse(ctx context.Context, token string) (tokens.Action, error) {
+	const op = "actions.Parse"
+	if ctx.Err() != nil {
+		return tokens.Action{}, errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return tokens.Action{}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails("wrong signature")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return tokens.Action{}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails(err.Error())
+	}
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return tokens.Action{}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails(err.Error())
+	}
+	return tokens.Action{
+		ID:        p.ID,
+		UserID:    p.UserID,
+		Email:     p.Email,
+		Purpose:   p.Purpose,
+		ExpiresAt: time.Unix(p.Expires, 0).UTC(),
+	}, nil
+}
+
+func New(secret []byte) Signer {
+	return Signer{secret: secret}
+}
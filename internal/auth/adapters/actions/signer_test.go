@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	ctx := context.Background()
+	s := New([]byte("secret"))
+	action, err := tokens.NewAction(1, "test@test.com", tokens.PurposeReset, time.Now().UTC().Truncate(time.Second))
+	require.NoError(t, err)
+
+	token, err := s.Sign(ctx, action)
+	require.NoError(t, err)
+	got, err := s.Parse(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, action, got)
+
+	_, err = New([]byte("other")).Parse(ctx, token)
+	assert.ErrorIs(t, err, app.ErrInvalidToken, "token signed by other secret must be rejected")
+	_, err = s.Parse(ctx, "e30."+token[len(token)-43:])
+	assert.ErrorIs(t, err, app.ErrInvalidToken, "changed payload must be rejected")
+}
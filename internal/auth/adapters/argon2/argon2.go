@@ -0,0 +1,120 @@
+package argon2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"goads/internal/auth/app"
+	"goads/internal/pkg/errwrap"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	prefix  = "$argon2id$"
+	saltLen = 16
+	keyLen  = 32
+)
+
+// Params are costs of Argon2id. Memory is in KiB
+type Params struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+// Argon2 hashes passwords by Argon2id. Hashes are encoded in PHC string format:
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>
+type Argon2 struct {
+	params Params
+}
+
+func encode(params Params, salt []byte, key []byte) string {
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		prefix,
+		argon2.Version,
+		params.Memory,
+		params.Time,
+		params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decode(hash string) (params Params, salt []byte, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("hash is not in PHC format of argon2id")
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, err
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil {
+		return params, nil, nil, err
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, err
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	return params, salt, key, err
+}
+
+func (a Argon2) Generate(ctx context.Context, password string) (string, error) {
+	const op = "argon2.Generate"
+
+	if ctx.Err() != nil {
+		return "", errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+	if len(password) == 0 {
+		return "", errwrap.New(app.ErrPasswordToShort, app.ServiceName, op)
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errwrap.New(err, app.ServiceName, op)
+	}
+	key := argon2.IDKey([]byte(password), salt, a.params.Time, a.params.Memory, a.params.Threads, keyLen)
+	return encode(a.params, salt, key), nil
+}
+
+func (a Argon2) Compare(ctx context.Context, hash string, password string) error {
+	const op = "argon2.Compare"
+
+	if ctx.Err() != nil {
+		return errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+	params, salt, key, err := decode(hash)
+	if err != nil {
+		return errwrap.New(app.ErrInvalidContent, app.ServiceName, op).WithDetails(err.Error())
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return errwrap.New(app.ErrIncorrectCredentials, app.ServiceName, op).WithDetails("hash mismatch")
+	}
+	return nil
+}
+
+// Identifies reports whether the hash is generated by Argon2id
+func (a Argon2) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, prefix)
+}
+
+// NeedsRehash reports whether the hash is generated with other params
+func (a Argon2) NeedsRehash(hash string) bool {
+	params, _, key, err := decode(hash)
+	return err != nil || params != a.params || len(key) != keyLen
+}
+
+func New(params Params) Argon2 {
+	return Argon2{
+		params: params,
+	}
+}
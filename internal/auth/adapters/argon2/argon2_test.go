@@ -0,0 +1,32 @@
+package argon2
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/app"
+	"strings"
+	"testing"
+)
+
+func TestArgon2(t *testing.T) {
+	ctx := context.Background()
+	a := New(Params{Memory: 1024, Time: 1, Threads: 1})
+
+	hash, err := a.Generate(ctx, "qwe123!!~....утф😊")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+	assert.True(t, a.Identifies(hash))
+	assert.False(t, a.NeedsRehash(hash))
+
+	assert.NoError(t, a.Compare(ctx, hash, "qwe123!!~....утф😊"))
+	assert.ErrorIs(t, a.Compare(ctx, hash, "qwe123!!~....ут😊ф"), app.ErrIncorrectCredentials)
+	assert.ErrorIs(t, a.Compare(ctx, "$2a$10$invalid", "qwe"), app.ErrInvalidContent)
+
+	stronger := New(Params{Memory: 2048, Time: 1, Threads: 1})
+	assert.True(t, stronger.NeedsRehash(hash), "hash of other params must be rehashed")
+	assert.NoError(t, stronger.Compare(ctx, hash, "qwe123!!~....утф😊"), "params must be read from the hash")
+
+	_, err = a.Generate(ctx, "")
+	assert.ErrorIs(t, err, app.ErrPasswordToShort)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"goads/internal/auth/app"
 	"goads/internal/pkg/errwrap"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -44,6 +45,17 @@ func (b BCrypt) Compare(ctx context.Context, hash string, password string) error
 	return err
 }
 
+// Identifies reports whether the hash is generated by bcrypt
+func (b BCrypt) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// NeedsRehash reports whether the hash is generated with other cost
+func (b BCrypt) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost
+}
+
 func New(cost int) BCrypt {
 	return BCrypt{
 		cost: cost,
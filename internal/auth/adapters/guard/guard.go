@@ -0,0 +1,120 @@
+package guard
+
+import (
+	"context"
+	"goads/internal/auth/users"
+	"goads/internal/pkg/errwrap"
+	"strings"
+	"time"
+
+	"goads/internal/auth/app"
+)
+
+type Repository interface {
+	StoreLoginFailure(ctx context.Context, failure users.LoginFailure) error
+	// IncrementFailures increases failures counter of the key. Counter is started again
+	// if the last failure is older than reset
+	IncrementFailures(ctx context.Context, key string, moment time.Time, reset time.Duration) error
+	// GetFailures returns number of failures of the key and time of the last one. It is 0 for unknown key
+	GetFailures(ctx context.Context, key string) (int, time.Time, error)
+	ResetFailures(ctx context.Context, key string) error
+}
+
+// Policy defines delays after failures. First Free failures are not delayed, then the delay is doubled
+// from Backoff on each failure. After Max failures attempts are locked out for Lockout.
+// Counter is reset if there are no failures during Lockout
+type Policy struct {
+	Free    int
+	Max     int
+	Backoff time.Duration
+	Lockout time.Duration
+}
+
+// delay returns how long attempts are rejected after the last of failures
+func (p Policy) delay(failures int) time.Duration {
+	if failures >= p.Max {
+		return p.Lockout
+	}
+	if failures <= p.Free {
+		return 0
+	}
+	d := p.Backoff << (failures - p.Free - 1)
+	if d <= 0 || d > p.Lockout {
+		d = p.Lockout
+	}
+	return d
+}
+
+// Guard throttles failed logins by account and by IP. Its state is stored in the repository,
+// so it is shared by all instances of the service
+type Guard struct {
+	Repo    Repository
+	account Policy
+	ip      Policy
+}
+
+type limit struct {
+	key    string
+	policy Policy
+}
+
+// limits returns keys of the login attempt with their policies. IP is not limited if it is unknown
+func (g Guard) limits(email string, ip string) []limit {
+	limits := []limit{{key: "account:" + strings.ToLower(email), policy: g.account}}
+	if ip != "" {
+		limits = append(limits, limit{key: "ip:" + ip, policy: g.ip})
+	}
+	return limits
+}
+
+// Wait returns how long attempts by the email or from the IP must be rejected
+func (g Guard) Wait(ctx context.Context, email string, ip string) (time.Duration, error) {
+	const op = "guard.Wait"
+
+	now := time.Now().UTC()
+	var wait time.Duration
+	for _, l := range g.limits(email, ip) {
+		failures, last, err := g.Repo.GetFailures(ctx, l.key)
+		if err != nil {
+			return 0, errwrap.JoinWithCaller(err, op)
+		}
+		if w := last.Add(l.policy.delay(failures)).Sub(now); failures > 0 && w > wait {
+			wait = w
+		}
+	}
+	return wait, nil
+}
+
+// Failed stores the audit entry and increases delays of the account and the IP
+func (g Guard) Failed(ctx context.Context, failure users.LoginFailure) error {
+	const op = "guard.Failed"
+
+	if err := g.Repo.StoreLoginFailure(ctx, failure); err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	for _, l := range g.limits(failure.Email, failure.IP) {
+		err := g.Repo.IncrementFailures(ctx, l.key, failure.CreatedAt, l.policy.Lockout)
+		if err != nil {
+			return errwrap.JoinWithCaller(err, op)
+		}
+	}
+	return nil
+}
+
+// Succeeded resets failures of the account. Failures of the IP are kept,
+// because a successful login to own account must not allow guessing passwords of others
+func (g Guard) Succeeded(ctx context.Context, email string) error {
+	const op = "guard.Succeeded"
+	err := g.Repo.ResetFailures(ctx, g.limits(email, "")[0].key)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+func New(repo Repository, account Policy, ip Policy) Guard {
+	return Guard{
+		Repo:    repo,
+		account: account,
+		ip:      ip,
+	}
+}
+
+var _ app.LoginGuard = Guard{}
@@ -0,0 +1,91 @@
+package guard
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/users"
+	"testing"
+	"time"
+)
+
+type counter struct {
+	failures int
+	last     time.Time
+}
+
+type memoryRepo struct {
+	audit    *[]users.LoginFailure
+	counters map[string]counter
+}
+
+func (r memoryRepo) StoreLoginFailure(_ context.Context, failure users.LoginFailure) error {
+	*r.audit = append(*r.audit, failure)
+	return nil
+}
+
+func (r memoryRepo) IncrementFailures(_ context.Context, key string, moment time.Time, reset time.Duration) error {
+	c := r.counters[key]
+	if c.last.Before(moment.Add(-reset)) {
+		c.failures = 0
+	}
+	r.counters[key] = counter{failures: c.failures + 1, last: moment}
+	return nil
+}
+
+func (r memoryRepo) GetFailures(_ context.Context, key string) (int, time.Time, error) {
+	c := r.counters[key]
+	return c.failures, c.last, nil
+}
+
+func (r memoryRepo) ResetFailures(_ context.Context, key string) error {
+	delete(r.counters, key)
+	return nil
+}
+
+func TestPolicy_delay(t *testing.T) {
+	p := Policy{Free: 2, Max: 6, Backoff: time.Second, Lockout: 5 * time.Second}
+	want := []time.Duration{0, 0, 0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for failures, d := range want {
+		assert.Equalf(t, d, p.delay(failures), "delay(%d)", failures)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryRepo{audit: new([]users.LoginFailure), counters: make(map[string]counter)}
+	g := New(
+		repo,
+		Policy{Free: 1, Max: 3, Backoff: time.Minute, Lockout: time.Hour},
+		Policy{Free: 10, Max: 20, Backoff: time.Minute, Lockout: time.Hour},
+	)
+	fail := func(email string, ip string) {
+		failure := users.LoginFailure{Email: email, IP: ip, Reason: "wrong password", CreatedAt: time.Now().UTC()}
+		require.NoError(t, g.Failed(ctx, failure))
+	}
+
+	fail("Test@test.com", "127.0.0.1")
+	wait, err := g.Wait(ctx, "test@test.com", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "free failures must not be delayed")
+
+	fail("test@test.com", "127.0.0.1")
+	wait, err = g.Wait(ctx, "test@test.com", "10.0.0.1")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, wait, float64(time.Second), "account must be delayed from any IP")
+	wait, err = g.Wait(ctx, "other@test.com", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "other accounts must not be delayed")
+
+	fail("test@test.com", "127.0.0.1")
+	wait, err = g.Wait(ctx, "test@test.com", "")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, wait, float64(time.Second), "account must be locked out after max failures")
+	assert.Len(t, *repo.audit, 3)
+
+	require.NoError(t, g.Succeeded(ctx, "test@test.com"))
+	wait, err = g.Wait(ctx, "test@test.com", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Equal(t, 3, repo.counters["ip:127.0.0.1"].failures, "failures of the IP must be kept after success")
+}
@@ -0,0 +1,49 @@
+package hashers
+
+import (
+	"context"
+	"goads/internal/auth/app"
+	"goads/internal/pkg/errwrap"
+)
+
+// Algorithm is a hasher which recognizes its own hashes
+type Algorithm interface {
+	app.Hasher
+	// Identifies reports whether the hash is generated by the algorithm
+	Identifies(hash string) bool
+}
+
+// Chain generates hashes by the current algorithm and compares passwords by the algorithm of the hash,
+// so hashes of previously used algorithms stay valid until they are rehashed
+type Chain struct {
+	current Algorithm
+	known   []Algorithm
+}
+
+func (c Chain) Generate(ctx context.Context, password string) (string, error) {
+	return c.current.Generate(ctx, password)
+}
+
+func (c Chain) Compare(ctx context.Context, hash string, password string) error {
+	const op = "hashers.Compare"
+
+	for _, a := range c.known {
+		if a.Identifies(hash) {
+			return a.Compare(ctx, hash, password)
+		}
+	}
+	return errwrap.New(app.ErrInvalidContent, app.ServiceName, op).WithDetails("unknown hash algorithm")
+}
+
+// NeedsRehash reports whether the hash is generated by another algorithm or with other params
+func (c Chain) NeedsRehash(hash string) bool {
+	return !c.current.Identifies(hash) || c.current.NeedsRehash(hash)
+}
+
+// New creates chain of the current algorithm and legacy ones whose hashes can be compared
+func New(current Algorithm, legacy ...Algorithm) Chain {
+	return Chain{
+		current: current,
+		known:   append([]Algorithm{current}, legacy...),
+	}
+}
@@ -0,0 +1,31 @@
+package hashers
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/adapters/argon2"
+	"goads/internal/auth/adapters/bcrypt"
+	"goads/internal/auth/app"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	legacy := bcrypt.New(4)
+	chain := New(argon2.New(argon2.Params{Memory: 1024, Time: 1, Threads: 1}), legacy)
+
+	old, err := legacy.Generate(ctx, "password")
+	require.NoError(t, err)
+	assert.NoError(t, chain.Compare(ctx, old, "password"), "legacy hashes must be accepted")
+	assert.ErrorIs(t, chain.Compare(ctx, old, "wrong"), app.ErrIncorrectCredentials)
+	assert.True(t, chain.NeedsRehash(old))
+
+	hash, err := chain.Generate(ctx, "password")
+	require.NoError(t, err)
+	assert.NoError(t, chain.Compare(ctx, hash, "password"))
+	assert.False(t, chain.NeedsRehash(hash))
+
+	assert.ErrorIs(t, chain.Compare(ctx, "plain", "plain"), app.ErrInvalidContent)
+	assert.True(t, New(bcrypt.New(5)).NeedsRehash(old), "hashes of other cost must be rehashed")
+}
@@ -4,30 +4,118 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
 	"testing"
 	"time"
 )
 
+type singleKey struct {
+	id      string
+	private *rsa.PrivateKey
+}
+
+func (k singleKey) Signing() (string, *rsa.PrivateKey) {
+	return k.id, k.private
+}
+
+func (k singleKey) Public(id string) (*rsa.PublicKey, bool) {
+	return &k.private.PublicKey, id == k.id
+}
+
 func setup(t require.TestingT, expires time.Duration) (Tokenizer, Validator) {
 	private, err := rsa.GenerateKey(rand.Reader, 4096)
 	require.NoError(t, err)
 
-	require.NoError(t, err)
+	keys := singleKey{id: "key", private: private}
 	return Tokenizer{
-		privateKey: private,
-		expires:    expires,
-	}, Validator{publicKey: private.Public().(*rsa.PublicKey)}
+		keys:    keys,
+		expires: expires,
+	}, Validator{keys: keys}
 }
 
 func FuzzJWT(f *testing.F) {
 	tok, val := setup(f, time.Hour)
 	f.Add(int64(0))
 	f.Fuzz(func(t *testing.T, id int64) {
-		token, err := tok.Generate(context.Background(), id)
+		token, err := tok.Generate(context.Background(), tokens.Claims{UserID: id})
 		require.NoError(t, err)
 		got, err := val.Validate(context.Background(), token)
 		require.NoError(t, err)
-		require.Equal(t, id, got)
+		require.Equal(t, id, got.UserID)
 	})
 }
+
+type revocationsList struct {
+	tokens   map[string]bool
+	users    map[int64]time.Time
+	sessions map[string]bool
+}
+
+func (r revocationsList) IsRevoked(jti string, session string, userID int64, issuedAt time.Time) bool {
+	before, ok := r.users[userID]
+	return r.tokens[jti] || r.sessions[session] || ok && issuedAt.Before(before)
+}
+
+func (r revocationsList) RevokeToken(_ context.Context, jti string, _ time.Time) error {
+	r.tokens[jti] = true
+	return nil
+}
+
+func (r revocationsList) RevokeUser(_ context.Context, userID int64, before time.Time) error {
+	r.users[userID] = before
+	return nil
+}
+
+func (r revocationsList) RevokeSession(_ context.Context, id string) error {
+	r.sessions[id] = true
+	return nil
+}
+
+func (r revocationsList) List() tokens.Revocations {
+	return tokens.Revocations{}
+}
+
+func TestValidator_Revocations(t *testing.T) {
+	ctx := context.Background()
+	tok, val := setup(t, time.Hour)
+	val.revocations = revocationsList{
+		tokens:   make(map[string]bool),
+		users:    make(map[int64]time.Time),
+		sessions: make(map[string]bool),
+	}
+
+	first, err := tok.Generate(ctx, tokens.Claims{UserID: 1, Verified: true, Role: "admin"})
+	require.NoError(t, err)
+	second, err := tok.Generate(ctx, tokens.Claims{UserID: 1, Verified: true, Role: "admin"})
+	require.NoError(t, err)
+
+	require.NoError(t, val.RevokeToken(ctx, first))
+	_, err = val.Validate(ctx, first)
+	assert.ErrorIs(t, err, app.ErrInvalidToken)
+	_, err = val.Validate(ctx, second)
+	assert.NoError(t, err, "other tokens must be valid")
+
+	require.NoError(t, val.RevokeUser(ctx, 1, time.Now().UTC()))
+	_, err = val.Validate(ctx, second)
+	assert.ErrorIs(t, err, app.ErrInvalidToken)
+
+	third, err := tok.Generate(ctx, tokens.Claims{UserID: 1, Verified: true, Role: "admin"})
+	require.NoError(t, err)
+	claims, err := val.Validate(ctx, third)
+	assert.NoError(t, err, "tokens issued after revocation must be valid")
+	assert.Equal(t, tokens.Claims{UserID: 1, Verified: true, Role: "admin"}, claims)
+
+	current, err := tok.Generate(ctx, tokens.Claims{UserID: 1, Session: "current"})
+	require.NoError(t, err)
+	other, err := tok.Generate(ctx, tokens.Claims{UserID: 1, Session: "other"})
+	require.NoError(t, err)
+	require.NoError(t, val.RevokeSession(ctx, "other"))
+	_, err = val.Validate(ctx, other)
+	assert.ErrorIs(t, err, app.ErrInvalidToken, "tokens of revoked session must be rejected")
+	claims, err = val.Validate(ctx, current)
+	assert.NoError(t, err)
+	assert.Equal(t, "current", claims.Session)
+}
@@ -2,52 +2,63 @@ package jwt
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
+	"encoding/base64"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
 	"goads/internal/pkg/errwrap"
 	"time"
 )
 
+type Signer interface {
+	// Signing returns ID and private key used to sign new tokens
+	Signing() (string, *rsa.PrivateKey)
+}
+
 type Tokenizer struct {
-	privateKey *rsa.PrivateKey
-	expires    time.Duration
+	keys    Signer
+	expires time.Duration
 }
 
-func (t Tokenizer) Generate(ctx context.Context, id int64) (string, error) {
+func (t Tokenizer) Generate(ctx context.Context, user tokens.Claims) (string, error) {
 	const op = "jwt.Generate"
 
 	if ctx.Err() != nil {
 		return "", errwrap.New(ctx.Err(), app.ServiceName, op)
 	}
 
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", errwrap.New(err, app.ServiceName, op)
+	}
+
 	now := time.Now().UTC()
 	claims := make(jwt.MapClaims)
-	claims["dat"] = id                        // user data - id
-	claims["exp"] = now.Add(t.expires).Unix() // expires
-	claims["iat"] = now.Unix()                // issued at
-	claims["nbf"] = now.Unix()                // not before
+	claims["dat"] = user.UserID                               // user data - id
+	claims["ver"] = user.Verified                             // email is verified
+	claims["rol"] = user.Role                                 // role of the user
+	claims["sid"] = user.Session                              // session ID
+	claims["jti"] = base64.RawURLEncoding.EncodeToString(jti) // token ID
+	claims["exp"] = now.Add(t.expires).Unix()                 // expires
+	claims["iat"] = float64(now.UnixMilli()) / 1000           // issued at with milliseconds for revocations
+	claims["nbf"] = now.Unix()                                // not before
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(t.privateKey)
+	kid, key := t.keys.Signing()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	token, err := tok.SignedString(key)
 	if err != nil {
 		err = errwrap.New(errors.New("cannot sign token"), app.ServiceName, op).WithDetails(err.Error())
 	}
 	return token, err
 }
 
-func NewTokenizer(expires time.Duration, privateKey []byte) (Tokenizer, error) {
-	const op = "jwt.NewTokenizer"
-
-	block, _ := pem.Decode(privateKey)
-	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-	if err != nil {
-		return Tokenizer{}, errwrap.New(err, app.ServiceName, op)
-	}
+func NewTokenizer(expires time.Duration, keys Signer) Tokenizer {
 	return Tokenizer{
-		expires:    expires,
-		privateKey: k.(*rsa.PrivateKey),
-	}, nil
+		expires: expires,
+		keys:    keys,
+	}
 }
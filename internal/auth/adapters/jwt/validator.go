@@ -3,54 +3,160 @@ package jwt
 import (
 	"context"
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
 	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/permissions"
+	"math"
+	"time"
 )
 
+type Revocations interface {
+	IsRevoked(jti string, session string, userID int64, issuedAt time.Time) bool
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeUser(ctx context.Context, userID int64, before time.Time) error
+	RevokeSession(ctx context.Context, id string) error
+	List() tokens.Revocations
+}
+
+type Verifier interface {
+	// Public returns public key by its ID
+	Public(id string) (*rsa.PublicKey, bool)
+}
+
+// Validator validates tokens and revokes them. Revocations can be nil, then no token is revoked
 type Validator struct {
-	publicKey *rsa.PublicKey
+	keys        Verifier
+	revocations Revocations
 }
 
-// Validate receives JWT token and returns user's ID extracted from there
-func (v Validator) Validate(ctx context.Context, token string) (int64, error) {
-	const op = "jwt.Validate"
+// claims is data of valid token
+type claims struct {
+	userID    int64
+	verified  bool
+	role      string
+	session   string
+	jti       string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+// parse checks signature and expiration of the token and extracts its claims
+func (v Validator) parse(ctx context.Context, token string) (claims, error) {
+	const op = "jwt.parse"
 	if ctx.Err() != nil {
-		return -1, errwrap.New(ctx.Err(), app.ServiceName, op)
+		return claims{}, errwrap.New(ctx.Err(), app.ServiceName, op)
 	}
 
 	tok, err := jwt.Parse(token, func(jwtToken *jwt.Token) (interface{}, error) {
 		if _, ok := jwtToken.Method.(*jwt.SigningMethodRSA); !ok {
 			return "", fmt.Errorf("unexpected method: %s", jwtToken.Header["alg"])
 		}
-
-		return v.publicKey, nil
+		kid, _ := jwtToken.Header["kid"].(string)
+		key, ok := v.keys.Public(kid)
+		if !ok {
+			return "", fmt.Errorf("unknown key: %s", kid)
+		}
+		return key, nil
 	})
 	if err != nil {
-		return -1, errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails(err.Error())
+		return claims{}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails(err.Error())
 	}
 
-	claims, ok := tok.Claims.(jwt.MapClaims)
+	mapClaims, ok := tok.Claims.(jwt.MapClaims)
 	if !ok || !tok.Valid {
-		return -1, errwrap.New(app.ErrInvalidToken, app.ServiceName, op)
+		return claims{}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op)
+	}
+	id, okID := mapClaims["dat"].(float64)
+	jti, okJTI := mapClaims["jti"].(string)
+	iat, okIAT := mapClaims["iat"].(float64)
+	exp, okEXP := mapClaims["exp"].(float64)
+	verified, _ := mapClaims["ver"].(bool)
+	role, okRole := mapClaims["rol"].(string)
+	if !okRole {
+		role = permissions.RoleUser
+	}
+	// tokens issued before sessions have no session ID
+	session, _ := mapClaims["sid"].(string)
+	if !okID || !okJTI || !okIAT || !okEXP {
+		return claims{}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op)
+	}
+	return claims{
+		userID:    int64(id),
+		verified:  verified,
+		role:      role,
+		session:   session,
+		jti:       jti,
+		issuedAt:  time.UnixMilli(int64(math.Round(iat * 1000))).UTC(),
+		expiresAt: time.Unix(int64(exp), 0).UTC(),
+	}, nil
+}
+
+// Validate receives JWT token and returns user's claims extracted from there
+func (v Validator) Validate(ctx context.Context, token string) (tokens.Claims, error) {
+	const op = "jwt.Validate"
+
+	c, err := v.parse(ctx, token)
+	if err != nil {
+		return tokens.Claims{UserID: -1}, errwrap.JoinWithCaller(err, op)
 	}
-	id, ok := claims["dat"].(float64)
-	if !ok {
-		return -1, errwrap.New(app.ErrInvalidToken, app.ServiceName, op)
+	if v.revocations != nil && v.revocations.IsRevoked(c.jti, c.session, c.userID, c.issuedAt) {
+		return tokens.Claims{UserID: -1}, errwrap.New(app.ErrInvalidToken, app.ServiceName, op).
+			WithDetails("token is revoked").
+			OnObject("user", c.userID)
 	}
-	return int64(id), nil
+	return tokens.Claims{UserID: c.userID, Verified: c.verified, Role: c.role, Session: c.session}, nil
 }
 
-func NewValidator(publicKey []byte) (Validator, error) {
-	const op = "jwt.NewValidator"
+// RevokeToken revokes the valid token until it expires
+func (v Validator) RevokeToken(ctx context.Context, token string) error {
+	const op = "jwt.RevokeToken"
 
-	block, _ := pem.Decode(publicKey)
-	k, err := x509.ParsePKIXPublicKey(block.Bytes)
+	c, err := v.parse(ctx, token)
 	if err != nil {
-		return Validator{}, errwrap.New(err, app.ServiceName, op)
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if v.revocations == nil {
+		return nil
+	}
+	err = v.revocations.RevokeToken(ctx, c.jti, c.expiresAt)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// RevokeUser revokes all tokens of the user issued before the moment.
+// The moment is truncated to precision of iat claim, so tokens issued right after it stay valid
+func (v Validator) RevokeUser(ctx context.Context, userID int64, before time.Time) error {
+	const op = "jwt.RevokeUser"
+	if v.revocations == nil {
+		return nil
+	}
+	err := v.revocations.RevokeUser(ctx, userID, before.Truncate(time.Millisecond))
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// RevokeSession revokes all access tokens issued by the session
+func (v Validator) RevokeSession(ctx context.Context, id string) error {
+	const op = "jwt.RevokeSession"
+	if v.revocations == nil {
+		return nil
+	}
+	err := v.revocations.RevokeSession(ctx, id)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// Revocations returns the list of revoked tokens, so they can be rejected by services verifying tokens locally
+func (v Validator) Revocations(context.Context) (tokens.Revocations, error) {
+	if v.revocations == nil {
+		return tokens.Revocations{}, nil
+	}
+	return v.revocations.List(), nil
+}
+
+func NewValidator(keys Verifier, revocations Revocations) Validator {
+	return Validator{
+		keys:        keys,
+		revocations: revocations,
 	}
-	return Validator{k.(*rsa.PublicKey)}, nil
 }
@@ -0,0 +1,224 @@
+package keyring
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
+	"goads/internal/pkg/errwrap"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Repository interface {
+	StoreSigningKey(ctx context.Context, key tokens.SigningKey) error
+	GetSigningKeys(ctx context.Context) ([]tokens.SigningKey, error)
+	DeleteSigningKeys(ctx context.Context, ids []string) error
+}
+
+type key struct {
+	id        string
+	private   *rsa.PrivateKey
+	createdAt time.Time
+}
+
+// KeyRing is a set of signing keys shared by instances through the repository.
+// A new key is generated every rotation period. It is published for interval before signing,
+// so all instances know it when the first token is signed by the key. Old keys are kept
+// for verification during the grace period after they have been replaced
+type KeyRing struct {
+	Repo     Repository
+	mu       *sync.RWMutex
+	keys     *[]key
+	initial  []byte
+	bits     int
+	rotation time.Duration
+	grace    time.Duration
+	interval time.Duration
+}
+
+// Signing returns ID and private key used to sign new tokens
+func (r KeyRing) Signing() (string, *rsa.PrivateKey) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k := r.active(time.Now().UTC())
+	return k.id, k.private
+}
+
+// Public returns public key by its ID
+func (r KeyRing) Public(id string) (*rsa.PublicKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, k := range *r.keys {
+		if k.id == id {
+			return &k.private.PublicKey, true
+		}
+	}
+	return nil, false
+}
+
+// PublicKeys returns all keys which can be used for verification including published ones
+func (r KeyRing) PublicKeys(context.Context) ([]tokens.PublicKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]tokens.PublicKey, len(*r.keys))
+	for i, k := range *r.keys {
+		keys[i] = tokens.PublicKey{ID: k.id, Key: &k.private.PublicKey}
+	}
+	return keys, nil
+}
+
+// active returns the newest key published at least interval ago. If there is no such key, the newest one is used
+func (r KeyRing) active(moment time.Time) key {
+	keys := *r.keys
+	for i := len(keys) - 1; i >= 0; i-- {
+		if !keys[i].createdAt.Add(r.interval).After(moment) {
+			return keys[i]
+		}
+	}
+	return keys[len(keys)-1]
+}
+
+// outdated returns IDs of keys replaced by active key more than grace period ago
+func (r KeyRing) outdated(keys []key, moment time.Time) []string {
+	var ids []string
+	for i := 0; i < len(keys)-1; i++ {
+		retired := keys[i+1].createdAt.Add(r.interval)
+		if retired.Add(r.grace).Before(moment) {
+			ids = append(ids, keys[i].id)
+		}
+	}
+	return ids
+}
+
+func parse(stored tokens.SigningKey) (key, error) {
+	const op = "keyring.parse"
+
+	details := fmt.Sprintf("key: %s", stored.ID)
+	block, _ := pem.Decode(stored.Private)
+	if block == nil {
+		return key{}, errwrap.New(app.ErrInvalidContent, app.ServiceName, op).
+			WithDetails(details + " | cannot decode PEM block")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return key{}, errwrap.New(err, app.ServiceName, op).WithDetails(details)
+	}
+	rsaKey, ok := private.(*rsa.PrivateKey)
+	if !ok {
+		return key{}, errwrap.New(app.ErrInvalidContent, app.ServiceName, op).
+			WithDetails(details + " | key is not RSA")
+	}
+	return key{id: stored.ID, private: rsaKey, createdAt: stored.CreatedAt}, nil
+}
+
+// generate creates a new signing key. The initial key is used if it is configured
+func (r KeyRing) generate(moment time.Time, initial bool) (tokens.SigningKey, error) {
+	const op = "keyring.generate"
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return tokens.SigningKey{}, errwrap.New(err, app.ServiceName, op)
+	}
+	stored := tokens.SigningKey{
+		ID:        base64.RawURLEncoding.EncodeToString(id),
+		CreatedAt: moment,
+	}
+	if initial && r.initial != nil {
+		stored.Private = r.initial
+		return stored, nil
+	}
+	private, err := rsa.GenerateKey(rand.Reader, r.bits)
+	if err != nil {
+		return tokens.SigningKey{}, errwrap.New(err, app.ServiceName, op)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return tokens.SigningKey{}, errwrap.New(err, app.ServiceName, op)
+	}
+	stored.Private = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return stored, nil
+}
+
+// Load reloads keys from the repository, rotates the newest key if it is older than rotation period
+// and deletes outdated keys
+func (r KeyRing) Load(ctx context.Context) error {
+	const op = "keyring.Load"
+
+	now := time.Now().UTC()
+	stored, err := r.Repo.GetSigningKeys(ctx)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].CreatedAt.Before(stored[j].CreatedAt)
+	})
+	if len(stored) == 0 || !stored[len(stored)-1].CreatedAt.Add(r.rotation).After(now) {
+		next, err := r.generate(now, len(stored) == 0)
+		if err != nil {
+			return errwrap.JoinWithCaller(err, op)
+		}
+		if err := r.Repo.StoreSigningKey(ctx, next); err != nil {
+			return errwrap.JoinWithCaller(err, op)
+		}
+		stored = append(stored, next)
+	}
+
+	keys := make([]key, len(stored))
+	for i := range stored {
+		keys[i], err = parse(stored[i])
+		if err != nil {
+			return errwrap.JoinWithCaller(err, op)
+		}
+	}
+	if ids := r.outdated(keys, now); len(ids) > 0 {
+		if err := r.Repo.DeleteSigningKeys(ctx, ids); err != nil {
+			return errwrap.JoinWithCaller(err, op)
+		}
+		keys = keys[len(ids):]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.keys = keys
+	return nil
+}
+
+// Listen reloads and rotates keys every interval until ctx is done
+func (r KeyRing) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := r.Load(ctx); err != nil {
+				log.Printf("cannot reload signing keys: %v\n", err)
+			}
+		}
+	}
+}
+
+// New creates empty key ring, it must be loaded before usage. Initial is PEM-encoded PKCS #8 private key
+// used as the first key of the ring, new keys are generated if it is nil
+func New(repo Repository, initial []byte, rotation time.Duration, grace time.Duration, interval time.Duration) KeyRing {
+	return KeyRing{
+		Repo:     repo,
+		mu:       &sync.RWMutex{},
+		keys:     new([]key),
+		initial:  initial,
+		bits:     2048,
+		rotation: rotation,
+		grace:    grace,
+		interval: interval,
+	}
+}
@@ -0,0 +1,77 @@
+package keyring
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/tokens"
+	"testing"
+	"time"
+)
+
+type memoryRepo struct {
+	keys map[string]tokens.SigningKey
+}
+
+func (r memoryRepo) StoreSigningKey(_ context.Context, key tokens.SigningKey) error {
+	r.keys[key.ID] = key
+	return nil
+}
+
+func (r memoryRepo) GetSigningKeys(context.Context) ([]tokens.SigningKey, error) {
+	keys := make([]tokens.SigningKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (r memoryRepo) DeleteSigningKeys(_ context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(r.keys, id)
+	}
+	return nil
+}
+
+// age moves creation time of all stored keys to the past
+func (r memoryRepo) age(d time.Duration) {
+	for id, k := range r.keys {
+		k.CreatedAt = k.CreatedAt.Add(-d)
+		r.keys[id] = k
+	}
+}
+
+func TestKeyRing(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryRepo{keys: make(map[string]tokens.SigningKey)}
+	ring := New(repo, nil, 10*time.Hour, 2*time.Hour, time.Minute)
+	ring.bits = 1024
+
+	require.NoError(t, ring.Load(ctx))
+	require.Len(t, repo.keys, 1)
+	first, _ := ring.Signing()
+	_, ok := ring.Public(first)
+	assert.True(t, ok)
+
+	repo.age(10 * time.Hour)
+	require.NoError(t, ring.Load(ctx))
+	require.Len(t, repo.keys, 2, "key must be rotated after rotation period")
+	current, _ := ring.Signing()
+	assert.Equal(t, first, current, "new key must be published before signing")
+	keys, err := ring.PublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	repo.age(time.Hour)
+	require.NoError(t, ring.Load(ctx))
+	current, _ = ring.Signing()
+	assert.NotEqual(t, first, current, "published key must be used for signing")
+	_, ok = ring.Public(first)
+	assert.True(t, ok, "old key must be kept during grace period")
+
+	repo.age(2 * time.Hour)
+	require.NoError(t, ring.Load(ctx))
+	_, ok = ring.Public(first)
+	assert.False(t, ok, "old key must be deleted after grace period")
+	assert.Len(t, repo.keys, 1)
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"goads/internal/auth/app"
+	"goads/internal/pkg/errwrap"
+	"io"
+	"sync"
+)
+
+// Log writes emails to the writer instead of sending them. It is used for local development
+type Log struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l Log) Send(ctx context.Context, to string, subject string, body string) error {
+	const op = "mailer.Send"
+	if ctx.Err() != nil {
+		return errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := fmt.Fprintf(l.w, "%s\n\n", message("goads", to, subject, body))
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("to: %s", to))
+	}
+	return err
+}
+
+func NewLog(w io.Writer) Log {
+	return Log{
+		mu: &sync.Mutex{},
+		w:  w,
+	}
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"goads/internal/auth/app"
+	"goads/internal/pkg/errwrap"
+	"net/smtp"
+	"strings"
+)
+
+// SMTP sends emails through SMTP server with PLAIN authentication
+type SMTP struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// message builds plain text email with headers
+func message(from string, to string, subject string, body string) []byte {
+	return []byte(strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n"))
+}
+
+func (s SMTP) Send(ctx context.Context, to string, subject string, body string) error {
+	const op = "mailer.Send"
+	if ctx.Err() != nil {
+		return errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+
+	err := smtp.SendMail(s.addr, s.auth, s.from, []string{to}, message(s.from, to, subject, body))
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("to: %s", to))
+	}
+	return err
+}
+
+// NewSMTP creates SMTP mailer. Authentication is disabled if username is empty
+func NewSMTP(addr string, username string, password string, from string) SMTP {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return SMTP{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}
+}
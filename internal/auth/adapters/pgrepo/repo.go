@@ -6,19 +6,30 @@ import (
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"goads/internal/auth/app"
+	"goads/internal/auth/tokens"
 	"goads/internal/auth/users"
 	"goads/internal/pkg/errwrap"
+	"time"
 )
 
 type Repo struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
 const (
-	constrEmail = "users_email_key"
+	constrEmail         = "users_email_key"
+	constrRefreshUserID = "refresh_tokens_user_id_key"
+	constrAPIKeyUserID  = "api_keys_user_id_key"
+	constrTOTPUserID    = "user_totp_user_id_key"
+	constrSessionUserID = "sessions_user_id_key"
 )
 
+// twoFactorColumn selects whether the user has enabled TOTP
+const twoFactorColumn = `EXISTS (SELECT 1 FROM user_totp t WHERE t.user_id = users.id AND t.enabled)`
+
 func (r Repo) Store(ctx context.Context, user users.User) (int64, error) {
 	const query = `INSERT INTO users (email, name, password) VALUES ($1, $2, $3) RETURNING id`
 	const op = "pgrepo.Store"
@@ -37,11 +48,16 @@ func (r Repo) Store(ctx context.Context, user users.User) (int64, error) {
 }
 
 func (r Repo) GetByEmail(ctx context.Context, email string) (users.User, error) {
-	const query = `SELECT id, email, password FROM users WHERE email=$1`
+	const query = `SELECT id, email, name, password, verified, role, delete_after, ` + twoFactorColumn + `
+		FROM users WHERE email=$1`
 	const op = "pgrepo.GetByEmail"
 
 	var usr users.User
-	err := r.db.QueryRow(ctx, query, email).Scan(&usr.ID, &usr.Email, &usr.Password)
+	var deleteAfter pgtype.Timestamp
+	err := r.db.QueryRow(ctx, query, email).Scan(
+		&usr.ID, &usr.Email, &usr.Name, &usr.Password, &usr.Verified, &usr.Role, &deleteAfter, &usr.TwoFactor,
+	)
+	usr.DeleteAfter = deleteAfter.Time
 	if errors.Is(err, pgx.ErrNoRows) {
 		err = errwrap.New(app.ErrIncorrectCredentials, app.ServiceName, op).
 			WithDetails(fmt.Sprintf("%v | email: %s", err.Error(), email)).
@@ -55,11 +71,16 @@ func (r Repo) GetByEmail(ctx context.Context, email string) (users.User, error)
 }
 
 func (r Repo) GetByID(ctx context.Context, id int64) (users.User, error) {
-	const query = `SELECT id, email, name FROM users WHERE id=$1`
+	const query = `SELECT id, email, name, password, verified, role, delete_after, ` + twoFactorColumn + `
+		FROM users WHERE id=$1`
 	const op = "pgrepo.GetByID"
 
 	var user users.User
-	err := r.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.Name)
+	var deleteAfter pgtype.Timestamp
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&user.ID, &user.Email, &user.Name, &user.Password, &user.Verified, &user.Role, &deleteAfter, &user.TwoFactor,
+	)
+	user.DeleteAfter = deleteAfter.Time
 	if errors.Is(err, pgx.ErrNoRows) {
 		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", id)
 	} else if err != nil {
@@ -69,10 +90,10 @@ func (r Repo) GetByID(ctx context.Context, id int64) (users.User, error) {
 }
 
 func (r Repo) Update(ctx context.Context, user users.User) error {
-	const query = `UPDATE users SET email=$1, name=$2, password=$3 WHERE id=$4`
+	const query = `UPDATE users SET email=$1, name=$2, password=$3, verified=$4, role=$5 WHERE id=$6`
 	const op = "pgrepo.Update"
 
-	_, err := r.db.Exec(ctx, query, user.Email, user.Name, user.Password, user.ID)
+	_, err := r.db.Exec(ctx, query, user.Email, user.Name, user.Password, user.Verified, user.Role, user.ID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", user.ID)
 	} else if err != nil {
@@ -81,19 +102,676 @@ func (r Repo) Update(ctx context.Context, user users.User) error {
 	return err
 }
 
-func (r Repo) Delete(ctx context.Context, id int64) error {
-	const query = `DELETE FROM users WHERE id=$1`
-	const op = "pgrepo.Delete"
+func (r Repo) RehashPassword(ctx context.Context, id int64, old string, hash string) error {
+	const query = `UPDATE users SET password=$3 WHERE id=$1 AND password=$2`
+	const op = "pgrepo.RehashPassword"
+
+	_, err := r.db.Exec(ctx, query, id, old, hash)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("user", id)
+	}
+	return err
+}
+
+func (r Repo) ScheduleDeletion(ctx context.Context, id int64, after time.Time) error {
+	const query = `UPDATE users SET delete_after=$2 WHERE id=$1 AND delete_after IS NULL`
+	const op = "pgrepo.ScheduleDeletion"
+
+	tag, err := r.db.Exec(ctx, query, id, after)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).OnObject("user", id)
+	}
+	if tag.RowsAffected() == 0 {
+		return errwrap.New(app.ErrNotFound, app.ServiceName, op).
+			WithDetails("user does not exist or is already scheduled for deletion").
+			OnObject("user", id)
+	}
+	return nil
+}
+
+func (r Repo) CancelDeletion(ctx context.Context, id int64) error {
+	const query = `UPDATE users SET delete_after=NULL WHERE id=$1 AND delete_after IS NOT NULL`
+	const op = "pgrepo.CancelDeletion"
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).OnObject("user", id)
+	}
+	if tag.RowsAffected() == 0 {
+		return errwrap.New(app.ErrNotFound, app.ServiceName, op).
+			WithDetails("user has been purged or is not scheduled for deletion").
+			OnObject("user", id)
+	}
+	return nil
+}
+
+// PurgeDeleted deletes users and publishes their events by one statement, so an event cannot be lost
+// or published for a user which still exists
+func (r Repo) PurgeDeleted(ctx context.Context, moment time.Time) (int64, error) {
+	const query = `WITH d AS (DELETE FROM users WHERE delete_after <= $1 RETURNING id)
+		INSERT INTO user_events (type, user_id, created_at) SELECT $2, id, $1 FROM d ORDER BY id`
+	const op = "pgrepo.PurgeDeleted"
+
+	tag, err := r.db.Exec(ctx, query, moment, users.EventDeleted)
+	if err != nil {
+		return 0, errwrap.New(err, app.ServiceName, op)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r Repo) GetUserEvents(ctx context.Context, after int64, limit int) ([]users.Event, error) {
+	const query = `SELECT id, type, user_id, created_at FROM user_events WHERE id > $1 ORDER BY id LIMIT $2`
+	const op = "pgrepo.GetUserEvents"
+
+	rows, err := r.db.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("after: %d", after))
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (users.Event, error) {
+		var e users.Event
+		err := row.Scan(&e.ID, &e.Type, &e.UserID, &e.CreatedAt)
+		return e, err
+	})
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("after: %d", after))
+	}
+	return events, nil
+}
+
+func (r Repo) StoreRefresh(ctx context.Context, token tokens.Refresh) error {
+	const query = `INSERT INTO refresh_tokens (user_id, family, hash, expires_at) VALUES ($1, $2, $3, $4)`
+	const op = "pgrepo.StoreRefresh"
+
+	_, err := r.db.Exec(ctx, query, token.UserID, token.Family, token.Hash, token.ExpiresAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == constrRefreshUserID {
+			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", token.UserID)
+		} else {
+			err = errwrap.New(err, app.ServiceName, op).OnObject("user", token.UserID)
+		}
+	}
+	return err
+}
+
+func (r Repo) GetRefresh(ctx context.Context, hash string) (tokens.Refresh, error) {
+	const query = `SELECT id, user_id, family, hash, expires_at, used, revoked FROM refresh_tokens WHERE hash=$1`
+	const op = "pgrepo.GetRefresh"
 
-	_, err := r.db.Exec(ctx, query, id)
+	var token tokens.Refresh
+	err := r.db.QueryRow(ctx, query, hash).Scan(
+		&token.ID, &token.UserID, &token.Family, &token.Hash, &token.ExpiresAt, &token.Used, &token.Revoked,
+	)
 	if errors.Is(err, pgx.ErrNoRows) {
-		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", id)
+		err = errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails(err.Error())
 	} else if err != nil {
-		err = errwrap.New(err, app.ServiceName, op).OnObject("user", id)
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return token, err
+}
+
+func (r Repo) UseRefresh(ctx context.Context, id int64) (bool, error) {
+	const query = `UPDATE refresh_tokens SET used=TRUE WHERE id=$1 AND NOT used AND NOT revoked`
+	const op = "pgrepo.UseRefresh"
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, errwrap.New(err, app.ServiceName, op).OnObject("refresh token", id)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r Repo) RevokeFamily(ctx context.Context, family string) error {
+	const query = `WITH s AS (UPDATE sessions SET revoked=TRUE WHERE id=$1)
+		UPDATE refresh_tokens SET revoked=TRUE WHERE family=$1`
+	const op = "pgrepo.RevokeFamily"
+
+	_, err := r.db.Exec(ctx, query, family)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("family: %s", family))
+	}
+	return err
+}
+
+func (r Repo) RevokeUserRefresh(ctx context.Context, userID int64) error {
+	const query = `WITH s AS (UPDATE sessions SET revoked=TRUE WHERE user_id=$1)
+		UPDATE refresh_tokens SET revoked=TRUE WHERE user_id=$1`
+	const op = "pgrepo.RevokeUserRefresh"
+
+	_, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return err
+}
+
+func (r Repo) StoreSession(ctx context.Context, session tokens.Session) error {
+	const query = `INSERT INTO sessions (id, user_id, device, ip, user_agent, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	const op = "pgrepo.StoreSession"
+
+	_, err := r.db.Exec(ctx, query, session.ID, session.UserID, session.Client.Device, session.Client.IP,
+		session.Client.UserAgent, session.CreatedAt, session.LastUsedAt, session.ExpiresAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == constrSessionUserID {
+			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", session.UserID)
+		} else {
+			err = errwrap.New(err, app.ServiceName, op).OnObject("user", session.UserID)
+		}
+	}
+	return err
+}
+
+func (r Repo) TouchSession(ctx context.Context, id string, moment time.Time, expiresAt time.Time) error {
+	const query = `UPDATE sessions SET last_used_at=$2, expires_at=$3 WHERE id=$1`
+	const op = "pgrepo.TouchSession"
+
+	_, err := r.db.Exec(ctx, query, id, moment, expiresAt)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("session: %s", id))
 	}
 	return err
 }
 
-func New(conn *pgx.Conn) Repo {
+func (r Repo) GetSessions(ctx context.Context, userID int64, moment time.Time) ([]tokens.Session, error) {
+	const query = `SELECT id, user_id, device, ip, user_agent, created_at, last_used_at, expires_at FROM sessions
+		WHERE user_id=$1 AND NOT revoked AND expires_at > $2 ORDER BY last_used_at DESC`
+	const op = "pgrepo.GetSessions"
+
+	rows, err := r.db.Query(ctx, query, userID, moment)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	defer rows.Close()
+
+	sessions := make([]tokens.Session, 0)
+	for rows.Next() {
+		var s tokens.Session
+		err := rows.Scan(&s.ID, &s.UserID, &s.Client.Device, &s.Client.IP, &s.Client.UserAgent,
+			&s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+		}
+		sessions = append(sessions, s)
+	}
+	if rows.Err() != nil {
+		return nil, errwrap.New(rows.Err(), app.ServiceName, op).OnObject("user", userID)
+	}
+	return sessions, nil
+}
+
+func (r Repo) RevokeSession(ctx context.Context, id string, userID int64) error {
+	const sessionQuery = `UPDATE sessions SET revoked=TRUE WHERE id=$1 AND user_id=$2 AND NOT revoked`
+	const refreshQuery = `UPDATE refresh_tokens SET revoked=TRUE WHERE family=$1`
+	const op = "pgrepo.RevokeSession"
+
+	var found bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, sessionQuery, id, userID)
+		if err != nil {
+			return err
+		}
+		found = tag.RowsAffected() > 0
+		if !found {
+			return nil
+		}
+		_, err = tx.Exec(ctx, refreshQuery, id)
+		return err
+	})
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("session: %s", id)).OnObject("user", userID)
+	}
+	if !found {
+		return errwrap.New(app.ErrNotFound, app.ServiceName, op).
+			WithDetails(fmt.Sprintf("session: %s", id)).
+			OnObject("user", userID)
+	}
+	return nil
+}
+
+func (r Repo) RevokeOtherSessions(ctx context.Context, userID int64, current string) ([]string, error) {
+	const query = `WITH s AS (UPDATE sessions SET revoked=TRUE WHERE user_id=$1 AND id<>$2 AND NOT revoked RETURNING id),
+		t AS (UPDATE refresh_tokens SET revoked=TRUE WHERE user_id=$1 AND family IN (SELECT id FROM s))
+		SELECT id FROM s`
+	const op = "pgrepo.RevokeOtherSessions"
+
+	rows, err := r.db.Query(ctx, query, userID, current)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return ids, nil
+}
+
+func (r Repo) UseAction(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	const query = `INSERT INTO used_action_tokens (id, expires_at) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`
+	const op = "pgrepo.UseAction"
+
+	tag, err := r.db.Exec(ctx, query, id, expiresAt)
+	if err != nil {
+		return false, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("action: %s", id))
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r Repo) StoreAPIKey(ctx context.Context, key tokens.APIKey) (int64, error) {
+	const query = `INSERT INTO api_keys (user_id, name, prefix, hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	const op = "pgrepo.StoreAPIKey"
+
+	var id int64 = -1
+	err := r.db.QueryRow(ctx, query, key.UserID, key.Name, key.Prefix, key.Hash, key.Scopes, key.CreatedAt).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == constrAPIKeyUserID {
+			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", key.UserID)
+		} else {
+			err = errwrap.New(err, app.ServiceName, op).OnObject("user", key.UserID)
+		}
+	}
+	return id, err
+}
+
+func (r Repo) GetAPIKey(ctx context.Context, hash string) (tokens.APIKey, error) {
+	const query = `SELECT k.id, k.user_id, k.name, k.prefix, k.hash, k.scopes, k.created_at
+		FROM api_keys k JOIN users u ON u.id = k.user_id
+		WHERE k.hash=$1 AND u.delete_after IS NULL`
+	const op = "pgrepo.GetAPIKey"
+
+	var key tokens.APIKey
+	err := r.db.QueryRow(ctx, query, hash).Scan(
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &key.Scopes, &key.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errwrap.New(app.ErrInvalidToken, app.ServiceName, op).WithDetails(err.Error())
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return key, err
+}
+
+func (r Repo) GetAPIKeys(ctx context.Context, userID int64) ([]tokens.APIKey, error) {
+	const query = `SELECT id, user_id, name, prefix, hash, scopes, created_at FROM api_keys
+		WHERE user_id=$1 ORDER BY id`
+	const op = "pgrepo.GetAPIKeys"
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	defer rows.Close()
+
+	keys := make([]tokens.APIKey, 0)
+	for rows.Next() {
+		var key tokens.APIKey
+		err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &key.Scopes, &key.CreatedAt)
+		if err != nil {
+			return nil, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+		}
+		keys = append(keys, key)
+	}
+	if rows.Err() != nil {
+		return nil, errwrap.New(rows.Err(), app.ServiceName, op).OnObject("user", userID)
+	}
+	return keys, nil
+}
+
+func (r Repo) DeleteAPIKey(ctx context.Context, id int64, userID int64) error {
+	const query = `DELETE FROM api_keys WHERE id=$1 AND user_id=$2`
+	const op = "pgrepo.DeleteAPIKey"
+
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).OnObject("api key", id)
+	}
+	if tag.RowsAffected() == 0 {
+		return errwrap.New(app.ErrNotFound, app.ServiceName, op).
+			WithDetails(fmt.Sprintf("user: %d", userID)).
+			OnObject("api key", id)
+	}
+	return nil
+}
+
+func (r Repo) StoreTOTP(ctx context.Context, totp users.TOTP, recoveryCodes []string) error {
+	const deleteQuery = `DELETE FROM user_totp WHERE user_id=$1`
+	const insertQuery = `INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)`
+	const codesQuery = `INSERT INTO totp_recovery_codes (user_id, hash) SELECT $1, unnest($2::TEXT[])`
+	const op = "pgrepo.StoreTOTP"
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteQuery, totp.UserID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, insertQuery, totp.UserID, totp.Secret); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, codesQuery, totp.UserID, recoveryCodes)
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == constrTOTPUserID {
+			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).WithDetails(err.Error()).OnObject("user", totp.UserID)
+		} else {
+			err = errwrap.New(err, app.ServiceName, op).OnObject("user", totp.UserID)
+		}
+	}
+	return err
+}
+
+func (r Repo) GetTOTP(ctx context.Context, userID int64) (users.TOTP, error) {
+	const query = `SELECT user_id, secret, enabled, last_step FROM user_totp WHERE user_id=$1`
+	const op = "pgrepo.GetTOTP"
+
+	var totp users.TOTP
+	err := r.db.QueryRow(ctx, query, userID).Scan(&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastStep)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).
+			WithDetails("two-factor authentication is not enrolled").
+			OnObject("user", userID)
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return totp, err
+}
+
+func (r Repo) EnableTOTP(ctx context.Context, userID int64, step int64) error {
+	const query = `UPDATE user_totp SET enabled=TRUE, last_step=$2 WHERE user_id=$1`
+	const op = "pgrepo.EnableTOTP"
+
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	if tag.RowsAffected() == 0 {
+		return errwrap.New(app.ErrNotFound, app.ServiceName, op).OnObject("user", userID)
+	}
+	return nil
+}
+
+func (r Repo) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	const query = `UPDATE user_totp SET last_step=$2 WHERE user_id=$1 AND last_step < $2`
+	const op = "pgrepo.UseTOTPStep"
+
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r Repo) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	const query = `DELETE FROM totp_recovery_codes WHERE user_id=$1 AND hash=$2`
+	const op = "pgrepo.UseRecoveryCode"
+
+	tag, err := r.db.Exec(ctx, query, userID, hash)
+	if err != nil {
+		return false, errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r Repo) DeleteTOTP(ctx context.Context, userID int64) error {
+	const query = `DELETE FROM user_totp WHERE user_id=$1`
+	const op = "pgrepo.DeleteTOTP"
+
+	_, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return err
+}
+
+func (r Repo) StoreRevokedToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const query = `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	const op = "pgrepo.StoreRevokedToken"
+
+	_, err := r.db.Exec(ctx, query, jti, expiresAt)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("jti: %s", jti))
+	}
+	return err
+}
+
+func (r Repo) StoreRevokedUser(ctx context.Context, userID int64, before time.Time) error {
+	const query = `INSERT INTO revoked_users (user_id, revoked_before) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before=GREATEST(revoked_users.revoked_before, EXCLUDED.revoked_before)`
+	const op = "pgrepo.StoreRevokedUser"
+
+	_, err := r.db.Exec(ctx, query, userID, before)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("user", userID)
+	}
+	return err
+}
+
+func (r Repo) StoreRevokedSession(ctx context.Context, id string, expiresAt time.Time) error {
+	const query = `INSERT INTO revoked_sessions (id, expires_at) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET expires_at=GREATEST(revoked_sessions.expires_at, EXCLUDED.expires_at)`
+	const op = "pgrepo.StoreRevokedSession"
+
+	_, err := r.db.Exec(ctx, query, id, expiresAt)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("session: %s", id))
+	}
+	return err
+}
+
+func (r Repo) GetRevokedSessions(ctx context.Context) (map[string]time.Time, error) {
+	const query = `SELECT id, expires_at FROM revoked_sessions`
+	const op = "pgrepo.GetRevokedSessions"
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op)
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var expiresAt time.Time
+		if err := rows.Scan(&id, &expiresAt); err != nil {
+			return nil, errwrap.New(err, app.ServiceName, op)
+		}
+		revoked[id] = expiresAt
+	}
+	if rows.Err() != nil {
+		return nil, errwrap.New(rows.Err(), app.ServiceName, op)
+	}
+	return revoked, nil
+}
+
+func (r Repo) DeleteRevokedSessions(ctx context.Context, moment time.Time) error {
+	const query = `DELETE FROM revoked_sessions WHERE expires_at <= $1`
+	const op = "pgrepo.DeleteRevokedSessions"
+
+	_, err := r.db.Exec(ctx, query, moment)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return err
+}
+
+func (r Repo) GetRevokedTokens(ctx context.Context) (map[string]time.Time, error) {
+	const query = `SELECT jti, expires_at FROM revoked_tokens`
+	const op = "pgrepo.GetRevokedTokens"
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op)
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]time.Time)
+	for rows.Next() {
+		var jti string
+		var expiresAt time.Time
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, errwrap.New(err, app.ServiceName, op)
+		}
+		revoked[jti] = expiresAt
+	}
+	if rows.Err() != nil {
+		return nil, errwrap.New(rows.Err(), app.ServiceName, op)
+	}
+	return revoked, nil
+}
+
+func (r Repo) GetRevokedUsers(ctx context.Context) (map[int64]time.Time, error) {
+	const query = `SELECT user_id, revoked_before FROM revoked_users`
+	const op = "pgrepo.GetRevokedUsers"
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op)
+	}
+	defer rows.Close()
+
+	revoked := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var before time.Time
+		if err := rows.Scan(&id, &before); err != nil {
+			return nil, errwrap.New(err, app.ServiceName, op)
+		}
+		revoked[id] = before
+	}
+	if rows.Err() != nil {
+		return nil, errwrap.New(rows.Err(), app.ServiceName, op)
+	}
+	return revoked, nil
+}
+
+func (r Repo) DeleteRevokedTokens(ctx context.Context, moment time.Time) error {
+	const query = `DELETE FROM revoked_tokens WHERE expires_at <= $1`
+	const op = "pgrepo.DeleteRevokedTokens"
+
+	_, err := r.db.Exec(ctx, query, moment)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return err
+}
+
+func (r Repo) DeleteRevokedUsers(ctx context.Context, moment time.Time) error {
+	const query = `DELETE FROM revoked_users WHERE revoked_before <= $1`
+	const op = "pgrepo.DeleteRevokedUsers"
+
+	_, err := r.db.Exec(ctx, query, moment)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return err
+}
+
+func (r Repo) StoreSigningKey(ctx context.Context, key tokens.SigningKey) error {
+	const query = `INSERT INTO signing_keys (id, private_key, created_at) VALUES ($1, $2, $3)`
+	const op = "pgrepo.StoreSigningKey"
+
+	_, err := r.db.Exec(ctx, query, key.ID, string(key.Private), key.CreatedAt)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("key: %s", key.ID))
+	}
+	return err
+}
+
+func (r Repo) GetSigningKeys(ctx context.Context) ([]tokens.SigningKey, error) {
+	const query = `SELECT id, private_key, created_at FROM signing_keys ORDER BY created_at`
+	const op = "pgrepo.GetSigningKeys"
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op)
+	}
+	defer rows.Close()
+
+	var keys []tokens.SigningKey
+	for rows.Next() {
+		var key tokens.SigningKey
+		var private string
+		if err := rows.Scan(&key.ID, &private, &key.CreatedAt); err != nil {
+			return nil, errwrap.New(err, app.ServiceName, op)
+		}
+		key.Private = []byte(private)
+		keys = append(keys, key)
+	}
+	if rows.Err() != nil {
+		return nil, errwrap.New(rows.Err(), app.ServiceName, op)
+	}
+	return keys, nil
+}
+
+func (r Repo) DeleteSigningKeys(ctx context.Context, ids []string) error {
+	const query = `DELETE FROM signing_keys WHERE id = ANY($1)`
+	const op = "pgrepo.DeleteSigningKeys"
+
+	_, err := r.db.Exec(ctx, query, ids)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("keys: %v", ids))
+	}
+	return err
+}
+
+func (r Repo) StoreLoginFailure(ctx context.Context, failure users.LoginFailure) error {
+	const query = `INSERT INTO login_failures (user_id, email, ip, reason, created_at)
+		VALUES (NULLIF($1, 0), $2, NULLIF($3, ''), $4, $5)`
+	const op = "pgrepo.StoreLoginFailure"
+
+	_, err := r.db.Exec(ctx, query, failure.UserID, failure.Email, failure.IP, failure.Reason, failure.CreatedAt)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).
+			WithDetails(fmt.Sprintf("email: %s, ip: %s", failure.Email, failure.IP)).
+			OnObject("user", failure.UserID)
+	}
+	return nil
+}
+
+func (r Repo) IncrementFailures(ctx context.Context, key string, moment time.Time, reset time.Duration) error {
+	const query = `INSERT INTO login_throttle (key, failures, last_failure) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttle.last_failure < $2 - $3::INTERVAL THEN 1 ELSE login_throttle.failures + 1 END,
+			last_failure = $2`
+	const op = "pgrepo.IncrementFailures"
+
+	_, err := r.db.Exec(ctx, query, key, moment, reset)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("key: %s", key))
+	}
+	return nil
+}
+
+func (r Repo) GetFailures(ctx context.Context, key string) (int, time.Time, error) {
+	const query = `SELECT failures, last_failure FROM login_throttle WHERE key=$1`
+	const op = "pgrepo.GetFailures"
+
+	var failures int
+	var last time.Time
+	err := r.db.QueryRow(ctx, query, key).Scan(&failures, &last)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("key: %s", key))
+	}
+	return failures, last, nil
+}
+
+func (r Repo) ResetFailures(ctx context.Context, key string) error {
+	const query = `DELETE FROM login_throttle WHERE key=$1`
+	const op = "pgrepo.ResetFailures"
+
+	_, err := r.db.Exec(ctx, query, key)
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("key: %s", key))
+	}
+	return nil
+}
+
+func New(conn *pgxpool.Pool) Repo {
 	return Repo{db: conn}
 }
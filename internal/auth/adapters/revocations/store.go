@@ -0,0 +1,197 @@
+package revocations
+
+import (
+	"context"
+	"goads/internal/auth/tokens"
+	"goads/internal/pkg/errwrap"
+	"log"
+	"sync"
+	"time"
+)
+
+type Repository interface {
+	StoreRevokedToken(ctx context.Context, jti string, expiresAt time.Time) error
+	StoreRevokedUser(ctx context.Context, userID int64, before time.Time) error
+	StoreRevokedSession(ctx context.Context, id string, expiresAt time.Time) error
+	GetRevokedTokens(ctx context.Context) (map[string]time.Time, error)
+	GetRevokedUsers(ctx context.Context) (map[int64]time.Time, error)
+	GetRevokedSessions(ctx context.Context) (map[string]time.Time, error)
+	// DeleteRevokedTokens deletes revocations of tokens expired before the moment
+	DeleteRevokedTokens(ctx context.Context, moment time.Time) error
+	// DeleteRevokedSessions deletes revocations of sessions whose tokens expired before the moment
+	DeleteRevokedSessions(ctx context.Context, moment time.Time) error
+	// DeleteRevokedUsers deletes users revocations made before the moment
+	DeleteRevokedUsers(ctx context.Context, moment time.Time) error
+}
+
+// Store is revocation list persisted in the repository and cached in memory.
+// Revocations are checked using the cache only, it is reloaded from the repository every interval
+// to receive revocations made by other instances
+type Store struct {
+	Repo     Repository
+	mu       *sync.RWMutex
+	tokens   map[string]time.Time
+	users    map[int64]time.Time
+	sessions map[string]time.Time
+	interval time.Duration
+	lifetime time.Duration
+}
+
+// IsRevoked returns true if the token was revoked by its jti, by its session or as one of the user's tokens
+func (s Store) IsRevoked(jti string, session string, userID int64, issuedAt time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return tokens.Revocations{Tokens: s.tokens, Sessions: s.sessions, Users: s.users}.
+		IsRevoked(jti, session, userID, issuedAt)
+}
+
+// List returns a copy of the cached revocations
+func (s Store) List() tokens.Revocations {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := tokens.Revocations{
+		Tokens:   make(map[string]time.Time, len(s.tokens)),
+		Sessions: make(map[string]time.Time, len(s.sessions)),
+		Users:    make(map[int64]time.Time, len(s.users)),
+	}
+	for jti, expiresAt := range s.tokens {
+		list.Tokens[jti] = expiresAt
+	}
+	for id, expiresAt := range s.sessions {
+		list.Sessions[id] = expiresAt
+	}
+	for id, before := range s.users {
+		list.Users[id] = before
+	}
+	return list
+}
+
+// RevokeToken revokes the single token until it expires
+func (s Store) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const op = "revocations.RevokeToken"
+
+	err := s.Repo.StoreRevokedToken(ctx, jti, expiresAt)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+// RevokeUser revokes all tokens of the user issued before the moment
+func (s Store) RevokeUser(ctx context.Context, userID int64, before time.Time) error {
+	const op = "revocations.RevokeUser"
+
+	err := s.Repo.StoreRevokedUser(ctx, userID, before)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if before.After(s.users[userID]) {
+		s.users[userID] = before
+	}
+	return nil
+}
+
+// RevokeSession revokes all tokens of the session. The revocation is kept for tokens lifetime,
+// later the session cannot issue tokens anyway
+func (s Store) RevokeSession(ctx context.Context, id string) error {
+	const op = "revocations.RevokeSession"
+
+	expiresAt := time.Now().UTC().Add(s.lifetime)
+	err := s.Repo.StoreRevokedSession(ctx, id, expiresAt)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = expiresAt
+	return nil
+}
+
+// Load deletes outdated revocations and replaces the cache with revocations from the repository.
+// Users revocations are outdated after tokens lifetime because all affected tokens are expired
+func (s Store) Load(ctx context.Context) error {
+	const op = "revocations.Load"
+
+	now := time.Now().UTC()
+	err := s.Repo.DeleteRevokedTokens(ctx, now)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = s.Repo.DeleteRevokedUsers(ctx, now.Add(-s.lifetime))
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = s.Repo.DeleteRevokedSessions(ctx, now)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	tokens, err := s.Repo.GetRevokedTokens(ctx)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	users, err := s.Repo.GetRevokedUsers(ctx)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	sessions, err := s.Repo.GetRevokedSessions(ctx)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti := range s.tokens {
+		delete(s.tokens, jti)
+	}
+	for jti, expiresAt := range tokens {
+		s.tokens[jti] = expiresAt
+	}
+	for id := range s.users {
+		delete(s.users, id)
+	}
+	for id, before := range users {
+		s.users[id] = before
+	}
+	for id := range s.sessions {
+		delete(s.sessions, id)
+	}
+	for id, expiresAt := range sessions {
+		s.sessions[id] = expiresAt
+	}
+	return nil
+}
+
+// Listen reloads the cache every interval until ctx is done
+func (s Store) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Load(ctx); err != nil {
+				log.Printf("cannot reload revocations: %v\n", err)
+			}
+		}
+	}
+}
+
+// New creates the store with empty cache. Lifetime is the maximal lifetime of tokens
+func New(repo Repository, interval time.Duration, lifetime time.Duration) Store {
+	return Store{
+		Repo:     repo,
+		mu:       &sync.RWMutex{},
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int64]time.Time),
+		sessions: make(map[string]time.Time),
+		interval: interval,
+		lifetime: lifetime,
+	}
+}
@@ -0,0 +1,116 @@
+package revocations
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type memoryRepo struct {
+	tokens   map[string]time.Time
+	users    map[int64]time.Time
+	sessions map[string]time.Time
+}
+
+func (r memoryRepo) StoreRevokedToken(_ context.Context, jti string, expiresAt time.Time) error {
+	r.tokens[jti] = expiresAt
+	return nil
+}
+
+func (r memoryRepo) StoreRevokedUser(_ context.Context, userID int64, before time.Time) error {
+	r.users[userID] = before
+	return nil
+}
+
+func (r memoryRepo) StoreRevokedSession(_ context.Context, id string, expiresAt time.Time) error {
+	r.sessions[id] = expiresAt
+	return nil
+}
+
+func (r memoryRepo) GetRevokedSessions(context.Context) (map[string]time.Time, error) {
+	sessions := make(map[string]time.Time)
+	for id, expiresAt := range r.sessions {
+		sessions[id] = expiresAt
+	}
+	return sessions, nil
+}
+
+func (r memoryRepo) DeleteRevokedSessions(_ context.Context, moment time.Time) error {
+	for id, expiresAt := range r.sessions {
+		if !expiresAt.After(moment) {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (r memoryRepo) GetRevokedTokens(context.Context) (map[string]time.Time, error) {
+	tokens := make(map[string]time.Time)
+	for jti, expiresAt := range r.tokens {
+		tokens[jti] = expiresAt
+	}
+	return tokens, nil
+}
+
+func (r memoryRepo) GetRevokedUsers(context.Context) (map[int64]time.Time, error) {
+	users := make(map[int64]time.Time)
+	for id, before := range r.users {
+		users[id] = before
+	}
+	return users, nil
+}
+
+func (r memoryRepo) DeleteRevokedTokens(_ context.Context, moment time.Time) error {
+	for jti, expiresAt := range r.tokens {
+		if !expiresAt.After(moment) {
+			delete(r.tokens, jti)
+		}
+	}
+	return nil
+}
+
+func (r memoryRepo) DeleteRevokedUsers(_ context.Context, moment time.Time) error {
+	for id, before := range r.users {
+		if !before.After(moment) {
+			delete(r.users, id)
+		}
+	}
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	repo := memoryRepo{
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int64]time.Time),
+		sessions: make(map[string]time.Time),
+	}
+	s := New(repo, time.Minute, time.Hour)
+
+	require.NoError(t, s.RevokeToken(ctx, "jti", now.Add(time.Hour)))
+	require.NoError(t, s.RevokeUser(ctx, 1, now))
+	assert.True(t, s.IsRevoked("jti", "", 2, now))
+	assert.True(t, s.IsRevoked("other", "", 1, now.Add(-time.Second)))
+	assert.False(t, s.IsRevoked("other", "", 1, now.Add(time.Second)))
+	assert.False(t, s.IsRevoked("other", "", 2, now))
+
+	require.NoError(t, s.RevokeSession(ctx, "session"))
+	assert.True(t, s.IsRevoked("other", "session", 2, now.Add(time.Second)))
+	assert.False(t, s.IsRevoked("other", "current", 2, now))
+
+	// revocations made by other instances and outdated ones
+	repo.tokens["remote"] = now.Add(time.Hour)
+	repo.tokens["expired"] = now.Add(-time.Second)
+	repo.users[3] = now.Add(-2 * time.Hour)
+	repo.sessions["expired"] = now.Add(-time.Second)
+	require.NoError(t, s.Load(ctx))
+	assert.True(t, s.IsRevoked("remote", "", 2, now))
+	assert.True(t, s.IsRevoked("jti", "", 2, now), "revocations must be kept after reloading")
+	assert.NotContains(t, repo.tokens, "expired")
+	assert.NotContains(t, repo.users, int64(3))
+	assert.True(t, s.IsRevoked("other", "session", 2, now))
+	assert.NotContains(t, repo.sessions, "expired")
+}
@@ -0,0 +1,86 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	digits = 6
+	modulo = 1_000_000 // 10^digits
+	period = 30
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP generates and validates RFC 6238 codes with SHA-1, 6 digits and 30 seconds period,
+// which are supported by all authenticator apps
+type TOTP struct {
+	issuer string
+	skew   int64
+}
+
+// GenerateSecret returns a new base32-encoded 160-bit secret
+func (t TOTP) GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns otpauth:// URI of the secret for authenticator apps
+func (t TOTP) URI(secret string, account string) string {
+	label := url.PathEscape(t.issuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", t.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(period))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// hotp returns HOTP value of the counter as defined in RFC 4226
+func hotp(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
+
+// Validate checks the code at the moment. Codes of skew steps before and after the moment are accepted
+// to tolerate clock difference. It returns the time step of the matched code
+func (t TOTP) Validate(secret string, code string, moment time.Time) (int64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+	current := moment.Unix() / period
+	for step := current - t.skew; step <= current+t.skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// New creates TOTP with issuer shown in authenticator apps. Skew is number of accepted steps around current one
+func New(issuer string, skew int) TOTP {
+	return TOTP{
+		issuer: issuer,
+		skew:   int64(skew),
+	}
+}
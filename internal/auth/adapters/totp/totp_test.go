@@ -0,0 +1,57 @@
+package totp
+
+import (
+	"encoding/base32"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// secret of RFC 6238 test vectors for SHA-1
+var secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTP_Validate(t *testing.T) {
+	otp := New("goads", 1)
+	// last 6 digits of RFC 6238 test vectors
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, code := range vectors {
+		step, ok := otp.Validate(secret, code, time.Unix(unix, 0))
+		assert.True(t, ok, "code at %d", unix)
+		assert.Equal(t, unix/period, step)
+	}
+
+	moment := time.Unix(1234567890, 0)
+	_, ok := otp.Validate(secret, "005924", moment.Add(period*time.Second))
+	assert.True(t, ok, "previous step is accepted")
+	_, ok = otp.Validate(secret, "005924", moment.Add(-period*time.Second))
+	assert.True(t, ok, "next step is accepted")
+	_, ok = otp.Validate(secret, "005924", moment.Add(2*period*time.Second))
+	assert.False(t, ok, "steps out of skew are rejected")
+	_, ok = otp.Validate(secret, "005925", moment)
+	assert.False(t, ok)
+	_, ok = otp.Validate(secret, "5924", moment)
+	assert.False(t, ok)
+}
+
+func TestTOTP_GenerateSecret(t *testing.T) {
+	otp := New("goads", 1)
+	s, err := otp.GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, s, 32)
+
+	uri, err := url.Parse(otp.URI(s, "test@test.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/goads:test@test.com", uri.Path)
+	assert.Equal(t, s, uri.Query().Get("secret"))
+	assert.Equal(t, "goads", uri.Query().Get("issuer"))
+	assert.False(t, strings.Contains(s, "="))
+}
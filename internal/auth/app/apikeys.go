@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ormequ/validator"
+	"goads/internal/auth/tokens"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/permissions"
+	"time"
+)
+
+// CreateAPIKey creates API key of the user with given scopes. Raw key is returned only once
+func (a App) CreateAPIKey(ctx context.Context, userID int64, name string, scopes []string) (string, tokens.APIKey, error) {
+	const op = "app.CreateAPIKey"
+
+	if len(scopes) == 0 {
+		return "", tokens.APIKey{}, errwrap.New(ErrInvalidContent, ServiceName, op).
+			WithDetails("no scopes").
+			OnObject("user", userID)
+	}
+	for _, s := range scopes {
+		if !permissions.IsValidScope(s) {
+			return "", tokens.APIKey{}, errwrap.New(ErrInvalidContent, ServiceName, op).
+				WithDetails(fmt.Sprintf("unknown scope: %s", s)).
+				OnObject("user", userID)
+		}
+	}
+	raw, key, err := tokens.NewAPIKey(userID, name, scopes, time.Now().UTC())
+	if err != nil {
+		return "", key, errwrap.New(err, ServiceName, op).OnObject("user", userID)
+	}
+	if err = govalid.Validate(key); err != nil {
+		err = errors.Join(ErrInvalidContent, err)
+		return "", key, errwrap.New(err, ServiceName, op).OnObject("user", userID)
+	}
+	key.ID, err = a.Repo.StoreAPIKey(ctx, key)
+	if err != nil {
+		return "", key, errwrap.JoinWithCaller(err, op)
+	}
+	return raw, key, nil
+}
+
+func (a App) GetAPIKeys(ctx context.Context, userID int64) ([]tokens.APIKey, error) {
+	const op = "app.GetAPIKeys"
+	keys, err := a.Repo.GetAPIKeys(ctx, userID)
+	return keys, errwrap.JoinWithCaller(err, op)
+}
+
+func (a App) RevokeAPIKey(ctx context.Context, userID int64, id int64) error {
+	const op = "app.RevokeAPIKey"
+	err := a.Repo.DeleteAPIKey(ctx, id, userID)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// ValidateAPIKey returns claims of the key owner. Requests by API keys are never privileged,
+// so the role is always user. Keys of users waiting for deletion are not accepted
+func (a App) ValidateAPIKey(ctx context.Context, raw string) (tokens.Claims, error) {
+	const op = "app.ValidateAPIKey"
+
+	key, err := a.Repo.GetAPIKey(ctx, tokens.Hash(raw))
+	if err != nil {
+		return tokens.Claims{UserID: -1}, errwrap.JoinWithCaller(err, op)
+	}
+	user, err := a.Repo.GetByID(ctx, key.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return tokens.Claims{UserID: -1}, errwrap.New(ErrInvalidToken, ServiceName, op).
+			WithDetails("owner of API key is not found").
+			OnObject("user", key.UserID)
+	} else if err != nil {
+		return tokens.Claims{UserID: -1}, errwrap.JoinWithCaller(err, op)
+	}
+	if !user.DeleteAfter.IsZero() {
+		return tokens.Claims{UserID: -1}, errwrap.New(ErrInvalidToken, ServiceName, op).
+			WithDetails("owner of API key is deleted").
+			OnObject("user", user.ID)
+	}
+	return tokens.Claims{
+		UserID:   user.ID,
+		Verified: user.Verified,
+		Role:     permissions.RoleUser,
+		Scopes:   key.Scopes,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"goads/internal/auth/app/mocks"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"goads/internal/pkg/permissions"
+	"strings"
+	"testing"
+	"time"
+)
+
+// apiKeysRepo stores API keys in memory, user is the owner of all keys
+func apiKeysRepo(t *testing.T, user users.User) *mocks.Repository {
+	stored := make(map[string]tokens.APIKey)
+	r := mocks.NewRepository(t)
+	r.
+		On("StoreAPIKey", mock.Anything, mock.AnythingOfType("tokens.APIKey")).
+		Return(func(ctx context.Context, key tokens.APIKey) (int64, error) {
+			key.ID = int64(len(stored) + 1)
+			stored[key.Hash] = key
+			return key.ID, nil
+		}).
+		Maybe()
+	r.
+		On("GetAPIKey", mock.Anything, mock.AnythingOfType("string")).
+		Return(func(ctx context.Context, hash string) (tokens.APIKey, error) {
+			key, ok := stored[hash]
+			if !ok {
+				return key, ErrInvalidToken
+			}
+			return key, nil
+		}).
+		Maybe()
+	r.On("GetByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+	return r
+}
+
+func TestApp_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test", Verified: true, Role: permissions.RoleAdmin}
+	a := App{Repo: apiKeysRepo(t, user)}
+
+	raw, key, err := a.CreateAPIKey(ctx, user.ID, "ci", []string{permissions.ScopeLinksWrite})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, key.Prefix), "prefix must be the beginning of the key")
+	assert.NotContains(t, key.Hash, raw)
+
+	claims, err := a.ValidateAPIKey(ctx, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, tokens.Claims{
+		UserID:   user.ID,
+		Verified: true,
+		Role:     permissions.RoleUser,
+		Scopes:   []string{permissions.ScopeLinksWrite},
+	}, claims, "API key must not grant privileges of the role")
+
+	_, err = a.ValidateAPIKey(ctx, raw+"x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, _, err = a.CreateAPIKey(ctx, user.ID, "ci", []string{"users:write"})
+	assert.ErrorIs(t, err, ErrInvalidContent, "unknown scope")
+	_, _, err = a.CreateAPIKey(ctx, user.ID, "ci", nil)
+	assert.ErrorIs(t, err, ErrInvalidContent, "no scopes")
+	_, _, err = a.CreateAPIKey(ctx, user.ID, "", []string{permissions.ScopeAdsRead})
+	assert.ErrorIs(t, err, ErrInvalidContent, "empty name")
+}
+
+func TestApp_ValidateAPIKey_DeletedOwner(t *testing.T) {
+	ctx := context.Background()
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test", DeleteAfter: time.Now().Add(time.Hour)}
+	a := App{Repo: apiKeysRepo(t, user)}
+
+	raw, _, err := a.CreateAPIKey(ctx, user.ID, "ci", []string{permissions.ScopeLinksWrite})
+	assert.NoError(t, err)
+	_, err = a.ValidateAPIKey(ctx, raw)
+	assert.ErrorIs(t, err, ErrInvalidToken, "keys must not work during the grace period")
+}
@@ -3,9 +3,14 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ormequ/validator"
+	"goads/internal/auth/tokens"
 	"goads/internal/auth/users"
 	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/permissions"
+	"log"
+	"time"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Repository
@@ -14,29 +19,108 @@ type Repository interface {
 	GetByID(ctx context.Context, id int64) (users.User, error)
 	GetByEmail(ctx context.Context, email string) (users.User, error)
 	Update(ctx context.Context, user users.User) error
-	Delete(ctx context.Context, id int64) error
+	// RehashPassword replaces hash of the password if it has not been changed since it was read
+	RehashPassword(ctx context.Context, id int64, old string, hash string) error
+	// ScheduleDeletion marks the active user to be purged after the moment
+	ScheduleDeletion(ctx context.Context, id int64, after time.Time) error
+	// CancelDeletion makes the user scheduled for deletion active again
+	CancelDeletion(ctx context.Context, id int64) error
+	// GetUserEvents returns at most limit events with ID greater than after in ascending order
+	GetUserEvents(ctx context.Context, after int64, limit int) ([]users.Event, error)
+
+	StoreRefresh(ctx context.Context, token tokens.Refresh) error
+	// GetRefresh returns refresh token by its hash
+	GetRefresh(ctx context.Context, hash string) (tokens.Refresh, error)
+	// UseRefresh marks the token as used. It returns false if the token has been already used or revoked
+	UseRefresh(ctx context.Context, id int64) (bool, error)
+	RevokeFamily(ctx context.Context, family string) error
+	RevokeUserRefresh(ctx context.Context, userID int64) error
+
+	StoreSession(ctx context.Context, session tokens.Session) error
+	// TouchSession updates time of the last usage and expiration of the session
+	TouchSession(ctx context.Context, id string, moment time.Time, expiresAt time.Time) error
+	// GetSessions returns sessions of the user which are not revoked or expired at the moment
+	GetSessions(ctx context.Context, userID int64, moment time.Time) ([]tokens.Session, error)
+	// RevokeSession revokes the session of the user with its refresh tokens
+	RevokeSession(ctx context.Context, id string, userID int64) error
+	// RevokeOtherSessions revokes all sessions of the user except the current one and returns their IDs
+	RevokeOtherSessions(ctx context.Context, userID int64, current string) ([]string, error)
+
+	// UseAction marks action token as used. It returns false if the token has been already used
+	UseAction(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+
+	StoreAPIKey(ctx context.Context, key tokens.APIKey) (int64, error)
+	// GetAPIKey returns API key by its hash
+	GetAPIKey(ctx context.Context, hash string) (tokens.APIKey, error)
+	GetAPIKeys(ctx context.Context, userID int64) ([]tokens.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id int64, userID int64) error
+
+	// StoreTOTP replaces the second factor of the user and its recovery codes by given hashes
+	StoreTOTP(ctx context.Context, totp users.TOTP, recoveryCodes []string) error
+	GetTOTP(ctx context.Context, userID int64) (users.TOTP, error)
+	EnableTOTP(ctx context.Context, userID int64, step int64) error
+	// UseTOTPStep saves step of accepted code. It returns false if a code of the same or later step has been used
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	// UseRecoveryCode deletes recovery code by its hash. It returns false if there is no such code
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error)
+	DeleteTOTP(ctx context.Context, userID int64) error
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Tokenizer
 type Tokenizer interface {
-	Generate(ctx context.Context, id int64) (string, error)
+	Generate(ctx context.Context, claims tokens.Claims) (string, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Hasher
 type Hasher interface {
 	Generate(ctx context.Context, password string) (string, error)
 	Compare(ctx context.Context, hash string, password string) error
+	// NeedsRehash reports whether the hash is generated by another algorithm or with other cost than configured
+	NeedsRehash(hash string) bool
+}
+
+type KeyRing interface {
+	// PublicKeys returns keys used to verify tokens
+	PublicKeys(ctx context.Context) ([]tokens.PublicKey, error)
 }
 
 type Validator interface {
-	Validate(ctx context.Context, token string) (int64, error)
+	Validate(ctx context.Context, token string) (tokens.Claims, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Revoker
+type Revoker interface {
+	// RevokeToken revokes the single access token
+	RevokeToken(ctx context.Context, token string) error
+	// RevokeUser revokes all access tokens of the user issued before the moment
+	RevokeUser(ctx context.Context, userID int64, before time.Time) error
+	// RevokeSession revokes all access tokens issued by the session
+	RevokeSession(ctx context.Context, id string) error
+	// Revocations returns the list of revoked access tokens
+	Revocations(ctx context.Context) (tokens.Revocations, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=LoginGuard
+type LoginGuard interface {
+	// Wait returns how long login attempts by the email or from the IP must be rejected
+	Wait(ctx context.Context, email string, ip string) (time.Duration, error)
+	Failed(ctx context.Context, failure users.LoginFailure) error
+	Succeeded(ctx context.Context, email string) error
 }
 
 type App struct {
-	Repo      Repository
-	Tokenizer Tokenizer
-	Hasher    Hasher
-	Validator Validator
+	Repo           Repository
+	Tokenizer      Tokenizer
+	Hasher         Hasher
+	Validator      Validator
+	Revoker        Revoker
+	Keys           KeyRing
+	Mailing        Mailing
+	TwoFactor      TwoFactor
+	Guard          LoginGuard
+	RefreshExpires time.Duration
+	// DeletionGrace is the period after deletion during which the user can restore the account
+	DeletionGrace time.Duration
 }
 
 func (a App) Register(ctx context.Context, email string, name string, password string) (users.User, error) {
@@ -53,28 +137,194 @@ func (a App) Register(ctx context.Context, email string, name string, password s
 		return user, errwrap.New(err, ServiceName, op)
 	}
 	user.ID, err = a.Repo.Store(ctx, user)
+	if err != nil {
+		return user, errwrap.JoinWithCaller(err, op)
+	}
+	err = a.sendVerification(ctx, user)
 	return user, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) Authenticate(ctx context.Context, email string, password string) (string, error) {
+// Authenticate checks the password and starts a new session of the client. Attempts by the email
+// or from IP of the client are rejected with TooManyAttemptsError after several failures.
+// Failures are reset only after the second factor if it is enabled
+func (a App) Authenticate(ctx context.Context, email string, password string, client tokens.Client) (tokens.Pair, error) {
 	const op = "app.Authenticate"
 
+	ip := client.IP
+	wait, err := a.Guard.Wait(ctx, email, ip)
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	if wait > 0 {
+		return tokens.Pair{}, errwrap.New(TooManyAttemptsError{RetryAfter: wait}, ServiceName, op).
+			WithDetails(fmt.Sprintf("email: %s, ip: %s", email, ip))
+	}
 	user, err := a.Repo.GetByEmail(ctx, email)
+	if errors.Is(err, ErrIncorrectCredentials) {
+		err = a.failed(ctx, err, users.LoginFailure{Email: email, IP: ip, Reason: "unknown email"})
+	}
 	if err != nil {
-		return "", errwrap.JoinWithCaller(err, op)
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
 	}
 	err = a.Hasher.Compare(ctx, user.Password, password)
+	if errors.Is(err, ErrIncorrectCredentials) {
+		err = a.failed(ctx, err, users.LoginFailure{UserID: user.ID, Email: email, IP: ip, Reason: "wrong password"})
+	}
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	if !user.DeleteAfter.IsZero() {
+		return tokens.Pair{}, errwrap.New(ErrUserDeleted, ServiceName, op).OnObject("user", user.ID)
+	}
+	if a.Hasher.NeedsRehash(user.Password) {
+		a.rehash(ctx, user, password)
+	}
+	if user.TwoFactor {
+		challenge, err := a.challenge(ctx, user)
+		return tokens.Pair{Challenge: challenge}, errwrap.JoinWithCaller(err, op)
+	}
+	if err := a.Guard.Succeeded(ctx, email); err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	pair, err := a.startSession(ctx, user, client)
+	return pair, errwrap.JoinWithCaller(err, op)
+}
+
+// rehash upgrades hash of the password to the configured algorithm and cost. Errors are only logged,
+// because the user is already authenticated by the old hash
+func (a App) rehash(ctx context.Context, user users.User, password string) {
+	const op = "app.rehash"
+
+	hash, err := a.Hasher.Generate(ctx, password)
+	if err == nil {
+		err = a.Repo.RehashPassword(ctx, user.ID, user.Password, hash)
+	}
 	if err != nil {
-		return "", errwrap.JoinWithCaller(err, op)
+		log.Printf("cannot rehash password: %v\n", errwrap.JoinWithCaller(err, op))
+	}
+}
+
+// failed records the failure of login and returns the cause
+func (a App) failed(ctx context.Context, cause error, failure users.LoginFailure) error {
+	const op = "app.failed"
+
+	failure.CreatedAt = time.Now().UTC()
+	if err := a.Guard.Failed(ctx, failure); err != nil {
+		return errwrap.JoinWithCaller(err, op)
 	}
-	token, err := a.Tokenizer.Generate(ctx, user.ID)
-	return token, errwrap.JoinWithCaller(err, op)
+	return cause
 }
 
-func (a App) Validate(ctx context.Context, token string) (int64, error) {
+// issue generates access token and the next refresh token of the family. The family is ID of the session
+func (a App) issue(ctx context.Context, user users.User, family string) (tokens.Pair, error) {
+	const op = "app.issue"
+
+	access, err := a.Tokenizer.Generate(ctx, tokens.Claims{
+		UserID:   user.ID,
+		Verified: user.Verified,
+		Role:     user.Role,
+		Session:  family,
+	})
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	raw, refresh, err := tokens.New(user.ID, family, time.Now().UTC().Add(a.RefreshExpires))
+	if err != nil {
+		return tokens.Pair{}, errwrap.New(err, ServiceName, op).OnObject("user", user.ID)
+	}
+	err = a.Repo.StoreRefresh(ctx, refresh)
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	return tokens.Pair{Access: access, Refresh: raw}, nil
+}
+
+// Refresh rotates refresh token: the token becomes used and a new pair of the same family is returned.
+// Reusing of a rotated token revokes the whole session, because the token may have been stolen
+func (a App) Refresh(ctx context.Context, token string) (tokens.Pair, error) {
+	const op = "app.Refresh"
+
+	refresh, err := a.Repo.GetRefresh(ctx, tokens.Hash(token))
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	if refresh.Revoked || refresh.IsExpired(time.Now().UTC()) {
+		return tokens.Pair{}, errwrap.New(ErrInvalidToken, ServiceName, op).OnObject("refresh token", refresh.ID)
+	}
+	used := refresh.Used
+	if !used {
+		ok, err := a.Repo.UseRefresh(ctx, refresh.ID)
+		if err != nil {
+			return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+		}
+		used = !ok
+	}
+	if used {
+		err = errwrap.New(ErrTokenReused, ServiceName, op).OnObject("refresh token", refresh.ID)
+		return tokens.Pair{}, errors.Join(
+			err,
+			a.Repo.RevokeFamily(ctx, refresh.Family),
+			a.Revoker.RevokeSession(ctx, refresh.Family),
+		)
+	}
+	user, err := a.Repo.GetByID(ctx, refresh.UserID)
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	now := time.Now().UTC()
+	err = a.Repo.TouchSession(ctx, refresh.Family, now, now.Add(a.RefreshExpires))
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	pair, err := a.issue(ctx, user, refresh.Family)
+	return pair, errwrap.JoinWithCaller(err, op)
+}
+
+// Logout revokes the whole family of refresh token and the access token if it is passed
+func (a App) Logout(ctx context.Context, token string, access string) error {
+	const op = "app.Logout"
+
+	refresh, err := a.Repo.GetRefresh(ctx, tokens.Hash(token))
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Repo.RevokeFamily(ctx, refresh.Family)
+	if err != nil || access == "" {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Revoker.RevokeToken(ctx, access)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// revokeUser revokes all access and refresh tokens of the user issued before now
+func (a App) revokeUser(ctx context.Context, id int64) error {
+	const op = "app.revokeUser"
+
+	err := a.Revoker.RevokeUser(ctx, id, time.Now().UTC())
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Repo.RevokeUserRefresh(ctx, id)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+func (a App) Validate(ctx context.Context, token string) (tokens.Claims, error) {
 	const op = "app.Validate"
-	id, err := a.Validator.Validate(ctx, token)
-	return id, errwrap.JoinWithCaller(err, op)
+	claims, err := a.Validator.Validate(ctx, token)
+	return claims, errwrap.JoinWithCaller(err, op)
+}
+
+func (a App) GetPublicKeys(ctx context.Context) ([]tokens.PublicKey, error) {
+	const op = "app.GetPublicKeys"
+	keys, err := a.Keys.PublicKeys(ctx)
+	return keys, errwrap.JoinWithCaller(err, op)
+}
+
+// GetRevocations returns revoked access tokens. Services verifying tokens locally must reject them
+func (a App) GetRevocations(ctx context.Context) (tokens.Revocations, error) {
+	const op = "app.GetRevocations"
+	list, err := a.Revoker.Revocations(ctx)
+	return list, errwrap.JoinWithCaller(err, op)
 }
 
 func (a App) GetByID(ctx context.Context, id int64) (users.User, error) {
@@ -105,10 +355,19 @@ func (a App) change(ctx context.Context, id int64, changer func(users.User) user
 
 func (a App) ChangeEmail(ctx context.Context, id int64, email string) (users.User, error) {
 	const op = "app.ChangeEmail"
+	var changed bool
 	user, err := a.change(ctx, id, func(user users.User) users.User {
+		changed = user.Email != email
+		if changed {
+			user.Verified = false
+		}
 		user.Email = email
 		return user
 	})
+	if err != nil || !changed {
+		return user, errwrap.JoinWithCaller(err, op)
+	}
+	err = a.sendVerification(ctx, user)
 	return user, errwrap.JoinWithCaller(err, op)
 }
 
@@ -127,24 +386,71 @@ func (a App) ChangePassword(ctx context.Context, id int64, password string) (use
 	if err != nil {
 		return users.User{}, errwrap.JoinWithCaller(err, op)
 	}
+	user, err := a.setPassword(ctx, id, hash)
+	return user, errwrap.JoinWithCaller(err, op)
+}
+
+// setPassword updates hash of user's password and revokes all tokens issued before
+func (a App) setPassword(ctx context.Context, id int64, hash string) (users.User, error) {
+	const op = "app.setPassword"
 	user, err := a.change(ctx, id, func(user users.User) users.User {
 		user.Password = hash
 		return user
 	})
+	if err != nil {
+		return user, errwrap.JoinWithCaller(err, op)
+	}
+	err = a.revokeUser(ctx, id)
 	return user, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) Delete(ctx context.Context, id int64) error {
-	const op = "app.Delete"
-	err := a.Repo.Delete(ctx, id)
-	return errwrap.JoinWithCaller(err, op)
+// SetRole changes role of the user. It is allowed only for users who can manage users.
+// Access tokens of the user are revoked, so the new role is applied after refresh
+func (a App) SetRole(ctx context.Context, id int64, role string) (users.User, error) {
+	const op = "app.SetRole"
+	if !permissions.Has(permissions.FromContext(ctx), permissions.ManageUsers) {
+		return users.User{}, errwrap.New(ErrPermissionDenied, ServiceName, op).OnObject("user", id)
+	}
+	if !permissions.IsValid(role) {
+		return users.User{}, errwrap.New(ErrInvalidContent, ServiceName, op).
+			WithDetails(fmt.Sprintf("unknown role: %s", role)).
+			OnObject("user", id)
+	}
+	user, err := a.change(ctx, id, func(user users.User) users.User {
+		user.Role = role
+		return user
+	})
+	if err != nil {
+		return user, errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Revoker.RevokeUser(ctx, id, time.Now().UTC())
+	return user, errwrap.JoinWithCaller(err, op)
 }
 
-func New(repository Repository, tokenizer Tokenizer, hasher Hasher, validator Validator) App {
+func New(
+	repository Repository,
+	tokenizer Tokenizer,
+	hasher Hasher,
+	validator Validator,
+	revoker Revoker,
+	keys KeyRing,
+	mailing Mailing,
+	twoFactor TwoFactor,
+	guard LoginGuard,
+	refreshExpires time.Duration,
+	deletionGrace time.Duration,
+) App {
 	return App{
-		Repo:      repository,
-		Tokenizer: tokenizer,
-		Hasher:    hasher,
-		Validator: validator,
+		Repo:           repository,
+		Tokenizer:      tokenizer,
+		Hasher:         hasher,
+		Validator:      validator,
+		Revoker:        revoker,
+		Keys:           keys,
+		Mailing:        mailing,
+		TwoFactor:      twoFactor,
+		Guard:          guard,
+		RefreshExpires: refreshExpires,
+		DeletionGrace:  deletionGrace,
 	}
 }
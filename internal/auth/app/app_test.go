@@ -2,12 +2,16 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"goads/internal/auth/app/mocks"
+	"goads/internal/auth/tokens"
 	"goads/internal/auth/users"
+	"goads/internal/pkg/permissions"
 	"testing"
+	"time"
 )
 
 func storeRepo(t *testing.T) Repository {
@@ -37,8 +41,39 @@ func getByEmailRepo(t *testing.T) Repository {
 			if email == "incorrect@credentials.com" {
 				return users.User{}, ErrIncorrectCredentials
 			}
+			if email == "deleted@test.com" {
+				return users.User{Email: email, DeleteAfter: time.Now().Add(time.Hour)}, nil
+			}
 			return users.User{Email: email}, nil
 		})
+	r.
+		On("StoreSession", mock.Anything, mock.AnythingOfType("tokens.Session")).
+		Return(nil).
+		Maybe()
+	r.
+		On("StoreRefresh", mock.Anything, mock.AnythingOfType("tokens.Refresh")).
+		Return(nil).
+		Maybe()
+	return r
+}
+
+// refreshRepo stores refresh tokens by their raw values as in a database
+func refreshRepo(t *testing.T, stored map[string]tokens.Refresh) *mocks.Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetRefresh", mock.Anything, mock.AnythingOfType("string")).
+		Return(func(ctx context.Context, hash string) (tokens.Refresh, error) {
+			for raw, token := range stored {
+				if tokens.Hash(raw) == hash {
+					return token, nil
+				}
+			}
+			return tokens.Refresh{}, ErrInvalidToken
+		})
+	r.
+		On("StoreRefresh", mock.Anything, mock.AnythingOfType("tokens.Refresh")).
+		Return(nil).
+		Maybe()
 	return r
 }
 
@@ -58,6 +93,10 @@ func getByIDUpdateRepo(t *testing.T) Repository {
 			}
 			return nil
 		})
+	r.
+		On("RevokeUserRefresh", mock.Anything, mock.AnythingOfType("int64")).
+		Return(nil).
+		Maybe()
 
 	return r
 }
@@ -108,14 +147,49 @@ func hashComparator(t *testing.T) Hasher {
 			}
 			return nil
 		})
+	h.
+		On("NeedsRehash", mock.AnythingOfType("string")).
+		Return(false).
+		Maybe()
 	return h
 }
 
+// loginGuard rejects attempts for wait and records failures to the slice
+func loginGuard(t *testing.T, wait time.Duration, failures *[]users.LoginFailure) LoginGuard {
+	g := mocks.NewLoginGuard(t)
+	g.
+		On("Wait", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(wait, nil).
+		Maybe()
+	g.
+		On("Failed", mock.Anything, mock.AnythingOfType("users.LoginFailure")).
+		Return(func(ctx context.Context, failure users.LoginFailure) error {
+			if failures != nil {
+				*failures = append(*failures, failure)
+			}
+			return nil
+		}).
+		Maybe()
+	g.
+		On("Succeeded", mock.Anything, mock.AnythingOfType("string")).
+		Return(nil).
+		Maybe()
+	return g
+}
+
+func revoker(t *testing.T) Revoker {
+	r := mocks.NewRevoker(t)
+	r.
+		On("RevokeUser", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).
+		Return(nil)
+	return r
+}
+
 func tokenizer(t *testing.T) Tokenizer {
 	tok := mocks.NewTokenizer(t)
 	tok.
-		On("Generate", mock.Anything, mock.AnythingOfType("int64")).
-		Return(func(ctx context.Context, id int64) (string, error) {
+		On("Generate", mock.Anything, mock.AnythingOfType("tokens.Claims")).
+		Return(func(ctx context.Context, claims tokens.Claims) (string, error) {
 			if ctx.Err() != nil {
 				return "", ctx.Err()
 			}
@@ -131,6 +205,7 @@ func TestApp_Register(t *testing.T) {
 		Tokenizer Tokenizer
 		Hasher    Hasher
 		Validator Validator
+		Mailing   Mailing
 	}
 	type args struct {
 		ctx      context.Context
@@ -152,8 +227,9 @@ func TestApp_Register(t *testing.T) {
 		{
 			name: "correct registering",
 			fields: fields{
-				Repo:   storeRepo(t),
-				Hasher: hashGenerator(t),
+				Repo:    storeRepo(t),
+				Hasher:  hashGenerator(t),
+				Mailing: mailing(t, nil, 1),
 			},
 			args: args{
 				ctx:      context.Background(),
@@ -166,6 +242,7 @@ func TestApp_Register(t *testing.T) {
 				Email:    "test@test.com",
 				Name:     "test",
 				Password: "asdf_hash",
+				Role:     permissions.RoleUser,
 			},
 		},
 		{
@@ -184,6 +261,7 @@ func TestApp_Register(t *testing.T) {
 				Email:    "",
 				Name:     "test",
 				Password: "asdf_hash",
+				Role:     permissions.RoleUser,
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.ErrorIs(t, err, ErrInvalidContent, i)
@@ -206,11 +284,36 @@ func TestApp_Register(t *testing.T) {
 				Email:    "already@exists.com",
 				Name:     "test",
 				Password: "asdf_hash",
+				Role:     permissions.RoleUser,
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.ErrorIs(t, err, ErrEmailAlreadyExists, i)
 			},
 		},
+		{
+			name: "mail not sent",
+			fields: fields{
+				Repo:    storeRepo(t),
+				Hasher:  hashGenerator(t),
+				Mailing: mailing(t, errors.New("smtp is down"), 1),
+			},
+			args: args{
+				ctx:      context.Background(),
+				email:    "test@test.com",
+				name:     "test",
+				password: "asdf",
+			},
+			want: users.User{
+				ID:       0,
+				Email:    "test@test.com",
+				Name:     "test",
+				Password: "asdf_hash",
+				Role:     permissions.RoleUser,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrMailNotSent, i)
+			},
+		},
 		{
 			name: "canceled ctx",
 			fields: fields{
@@ -231,6 +334,7 @@ func TestApp_Register(t *testing.T) {
 				Tokenizer: tt.fields.Tokenizer,
 				Hasher:    tt.fields.Hasher,
 				Validator: tt.fields.Validator,
+				Mailing:   tt.fields.Mailing,
 			}
 			got, err := a.Register(tt.args.ctx, tt.args.email, tt.args.name, tt.args.password)
 			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("Register(%v, %v, %v, %v)", tt.args.ctx, tt.args.email, tt.args.name, tt.args.password)) {
@@ -249,6 +353,7 @@ func TestApp_Authenticate(t *testing.T) {
 		Tokenizer Tokenizer
 		Hasher    Hasher
 		Validator Validator
+		Guard     LoginGuard
 	}
 	type args struct {
 		ctx      context.Context
@@ -272,6 +377,7 @@ func TestApp_Authenticate(t *testing.T) {
 				Repo:      getByEmailRepo(t),
 				Tokenizer: tokenizer(t),
 				Hasher:    hashComparator(t),
+				Guard:     loginGuard(t, 0, nil),
 			},
 			args: args{
 				ctx:      context.Background(),
@@ -283,7 +389,8 @@ func TestApp_Authenticate(t *testing.T) {
 		{
 			name: "canceled ctx",
 			fields: fields{
-				Repo: getByEmailRepo(t),
+				Repo:  getByEmailRepo(t),
+				Guard: loginGuard(t, 0, nil),
 			},
 			args: args{
 				ctx: canceledCtx,
@@ -295,7 +402,8 @@ func TestApp_Authenticate(t *testing.T) {
 		{
 			name: "incorrect email",
 			fields: fields{
-				Repo: getByEmailRepo(t),
+				Repo:  getByEmailRepo(t),
+				Guard: loginGuard(t, 0, nil),
 			},
 			args: args{
 				ctx:      context.Background(),
@@ -311,6 +419,7 @@ func TestApp_Authenticate(t *testing.T) {
 			fields: fields{
 				Repo:   getByEmailRepo(t),
 				Hasher: hashComparator(t),
+				Guard:  loginGuard(t, 0, nil),
 			},
 			args: args{
 				ctx:      context.Background(),
@@ -321,6 +430,39 @@ func TestApp_Authenticate(t *testing.T) {
 				return assert.ErrorIs(t, err, ErrIncorrectCredentials, i)
 			},
 		},
+		{
+			name: "user scheduled for deletion",
+			fields: fields{
+				Repo:   getByEmailRepo(t),
+				Hasher: hashComparator(t),
+				Guard:  loginGuard(t, 0, nil),
+			},
+			args: args{
+				ctx:      context.Background(),
+				email:    "deleted@test.com",
+				password: "test",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrUserDeleted, i)
+			},
+		},
+		{
+			name: "too many attempts",
+			fields: fields{
+				Guard: loginGuard(t, time.Minute, nil),
+			},
+			args: args{
+				ctx:      context.Background(),
+				email:    "test@test.com",
+				password: "test",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				var attempts TooManyAttemptsError
+				return assert.ErrorIs(t, err, ErrTooManyAttempts, i) &&
+					assert.ErrorAs(t, err, &attempts, i) &&
+					assert.Equal(t, time.Minute, attempts.RetryAfter, i)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -329,24 +471,88 @@ func TestApp_Authenticate(t *testing.T) {
 				Tokenizer: tt.fields.Tokenizer,
 				Hasher:    tt.fields.Hasher,
 				Validator: tt.fields.Validator,
+				Guard:     tt.fields.Guard,
 			}
-			got, err := a.Authenticate(tt.args.ctx, tt.args.email, tt.args.password)
+			got, err := a.Authenticate(tt.args.ctx, tt.args.email, tt.args.password, tokens.Client{IP: "127.0.0.1"})
 			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("Authenticate(%v, %v, %v)", tt.args.ctx, tt.args.email, tt.args.password)) {
 				return
 			} else if tt.wantErr == nil {
 				assert.NoError(t, err)
+				assert.NotEmpty(t, got.Refresh)
 			}
-			assert.Equalf(t, tt.want, got, "Authenticate(%v, %v, %v)", tt.args.ctx, tt.args.email, tt.args.password)
+			assert.Equalf(t, tt.want, got.Access, "Authenticate(%v, %v, %v)", tt.args.ctx, tt.args.email, tt.args.password)
 		})
 	}
 }
 
+func TestApp_Authenticate_Failures(t *testing.T) {
+	var failures []users.LoginFailure
+	a := App{
+		Repo:   getByEmailRepo(t),
+		Hasher: hashComparator(t),
+		Guard:  loginGuard(t, 0, &failures),
+	}
+	ctx := context.Background()
+
+	_, err := a.Authenticate(ctx, "incorrect@credentials.com", "test", tokens.Client{IP: "127.0.0.1"})
+	assert.ErrorIs(t, err, ErrIncorrectCredentials)
+	_, err = a.Authenticate(ctx, "test@test.com", "", tokens.Client{IP: "127.0.0.1"})
+	assert.ErrorIs(t, err, ErrIncorrectCredentials)
+
+	if assert.Len(t, failures, 2) {
+		assert.Equal(t, int64(0), failures[0].UserID)
+		assert.Equal(t, "unknown email", failures[0].Reason)
+		assert.Equal(t, "wrong password", failures[1].Reason)
+		assert.Equal(t, "127.0.0.1", failures[1].IP)
+		assert.False(t, failures[1].CreatedAt.IsZero())
+	}
+}
+
+func TestApp_Authenticate_Rehash(t *testing.T) {
+	user := users.User{Email: "test@test.com", Password: "$2a$04$legacy"}
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByEmail", mock.Anything, user.Email).
+		Return(user, nil)
+	r.
+		On("StoreSession", mock.Anything, mock.AnythingOfType("tokens.Session")).
+		Return(nil)
+	r.
+		On("StoreRefresh", mock.Anything, mock.AnythingOfType("tokens.Refresh")).
+		Return(nil)
+	r.
+		On("RehashPassword", mock.Anything, int64(0), "$2a$04$legacy", "$argon2id$new").
+		Return(nil).
+		Once()
+	h := mocks.NewHasher(t)
+	h.
+		On("Compare", mock.Anything, "$2a$04$legacy", "test").
+		Return(nil)
+	h.
+		On("NeedsRehash", "$2a$04$legacy").
+		Return(true)
+	h.
+		On("Generate", mock.Anything, "test").
+		Return("$argon2id$new", nil)
+	a := App{
+		Repo:      r,
+		Tokenizer: tokenizer(t),
+		Hasher:    h,
+		Guard:     loginGuard(t, 0, nil),
+	}
+
+	pair, err := a.Authenticate(context.Background(), user.Email, "test", tokens.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", pair.Access)
+}
+
 func TestApp_ChangePassword(t *testing.T) {
 	type fields struct {
 		Repo      Repository
 		Tokenizer Tokenizer
 		Hasher    Hasher
 		Validator Validator
+		Revoker   Revoker
 	}
 	type args struct {
 		ctx      context.Context
@@ -363,8 +569,9 @@ func TestApp_ChangePassword(t *testing.T) {
 		{
 			name: "correct changing",
 			fields: fields{
-				Repo:   getByIDUpdateRepo(t),
-				Hasher: hashGenerator(t),
+				Repo:    getByIDUpdateRepo(t),
+				Hasher:  hashGenerator(t),
+				Revoker: revoker(t),
 			},
 			args: args{
 				ctx:      context.Background(),
@@ -434,6 +641,7 @@ func TestApp_ChangePassword(t *testing.T) {
 				Tokenizer: tt.fields.Tokenizer,
 				Hasher:    tt.fields.Hasher,
 				Validator: tt.fields.Validator,
+				Revoker:   tt.fields.Revoker,
 			}
 			got, err := a.ChangePassword(tt.args.ctx, tt.args.id, tt.args.password)
 			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("ChangePassword(%v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.password)) {
@@ -445,3 +653,220 @@ func TestApp_ChangePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_SetRole(t *testing.T) {
+	type fields struct {
+		Repo    Repository
+		Revoker Revoker
+	}
+	type args struct {
+		ctx  context.Context
+		id   int64
+		role string
+	}
+	admin := permissions.WithRole(context.Background(), permissions.RoleAdmin)
+	tests := [...]struct {
+		name    string
+		fields  fields
+		args    args
+		want    users.User
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "correct setting",
+			fields: fields{
+				Repo:    getByIDUpdateRepo(t),
+				Revoker: revoker(t),
+			},
+			args: args{
+				ctx:  admin,
+				id:   0,
+				role: permissions.RoleModerator,
+			},
+			want: users.User{
+				ID:    0,
+				Name:  "test",
+				Email: "test@test.com",
+				Role:  permissions.RoleModerator,
+			},
+		},
+		{
+			name: "not admin",
+			args: args{
+				ctx:  permissions.WithRole(context.Background(), permissions.RoleModerator),
+				id:   0,
+				role: permissions.RoleAdmin,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrPermissionDenied, i)
+			},
+		},
+		{
+			name: "unknown role",
+			args: args{
+				ctx:  admin,
+				id:   0,
+				role: "root",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "not found",
+			fields: fields{
+				Repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:  admin,
+				id:   -1,
+				role: permissions.RoleAdmin,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNotFound, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := App{
+				Repo:    tt.fields.Repo,
+				Revoker: tt.fields.Revoker,
+			}
+			got, err := a.SetRole(tt.args.ctx, tt.args.id, tt.args.role)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("SetRole(%v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.role)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equalf(t, tt.want, got, "SetRole(%v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.role)
+		})
+	}
+}
+
+func TestApp_Refresh(t *testing.T) {
+	valid := tokens.Refresh{ID: 1, UserID: 2, Family: "valid", ExpiresAt: time.Now().Add(time.Hour)}
+	used := tokens.Refresh{ID: 3, UserID: 2, Family: "stolen", ExpiresAt: time.Now().Add(time.Hour), Used: true}
+	stored := map[string]tokens.Refresh{
+		"valid":   valid,
+		"used":    used,
+		"raced":   {ID: 4, UserID: 2, Family: "raced", ExpiresAt: time.Now().Add(time.Hour)},
+		"revoked": {ID: 5, UserID: 2, Family: "revoked", ExpiresAt: time.Now().Add(time.Hour), Revoked: true},
+		"expired": {ID: 6, UserID: 2, Family: "expired", ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+
+	tests := [...]struct {
+		name    string
+		token   string
+		repo    func(r *mocks.Repository)
+		revoked string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "correct rotation",
+			token: "valid",
+			repo: func(r *mocks.Repository) {
+				r.On("UseRefresh", mock.Anything, valid.ID).Return(true, nil)
+				r.On("GetByID", mock.Anything, valid.UserID).Return(users.User{ID: valid.UserID}, nil)
+				r.
+					On("TouchSession", mock.Anything, valid.Family, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+					Return(nil)
+				r.
+					On("StoreRefresh", mock.Anything, mock.MatchedBy(func(token tokens.Refresh) bool {
+						return token.Family == valid.Family && token.UserID == valid.UserID
+					})).
+					Return(nil)
+			},
+			want: "token",
+		},
+		{
+			name:  "reused token revokes family",
+			token: "used",
+			repo: func(r *mocks.Repository) {
+				r.On("RevokeFamily", mock.Anything, used.Family).Return(nil)
+			},
+			revoked: used.Family,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrTokenReused, i)
+			},
+		},
+		{
+			name:  "concurrently used token revokes family",
+			token: "raced",
+			repo: func(r *mocks.Repository) {
+				r.On("UseRefresh", mock.Anything, int64(4)).Return(false, nil)
+				r.On("RevokeFamily", mock.Anything, "raced").Return(nil)
+			},
+			revoked: "raced",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrTokenReused, i)
+			},
+		},
+		{
+			name:  "revoked token",
+			token: "revoked",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+		{
+			name:  "expired token",
+			token: "expired",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+		{
+			name:  "unknown token",
+			token: "unknown",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := refreshRepo(t, stored)
+			if tt.repo != nil {
+				tt.repo(r)
+			}
+			a := App{
+				Repo:           r,
+				RefreshExpires: time.Hour,
+			}
+			if tt.wantErr == nil {
+				a.Tokenizer = tokenizer(t)
+			}
+			if tt.revoked != "" {
+				rev := mocks.NewRevoker(t)
+				rev.On("RevokeSession", mock.Anything, tt.revoked).Return(nil)
+				a.Revoker = rev
+			}
+			got, err := a.Refresh(context.Background(), tt.token)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("Refresh(%v)", tt.token)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, got.Refresh)
+				assert.NotEqual(t, tt.token, got.Refresh)
+			}
+			assert.Equalf(t, tt.want, got.Access, "Refresh(%v)", tt.token)
+		})
+	}
+}
+
+func TestApp_Logout(t *testing.T) {
+	stored := map[string]tokens.Refresh{
+		"valid": {ID: 1, UserID: 2, Family: "family", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	r := refreshRepo(t, stored)
+	r.On("RevokeFamily", mock.Anything, "family").Return(nil).Twice()
+	rev := mocks.NewRevoker(t)
+	rev.On("RevokeToken", mock.Anything, "access").Return(nil).Once()
+	a := App{Repo: r, Revoker: rev}
+	assert.NoError(t, a.Logout(context.Background(), "valid", ""))
+	assert.NoError(t, a.Logout(context.Background(), "valid", "access"))
+	assert.ErrorIs(t, a.Logout(context.Background(), "unknown", "access"), ErrInvalidToken)
+}
@@ -1,6 +1,10 @@
 package app
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrIncorrectCredentials = errors.New("incorrect credentials")
@@ -9,6 +13,27 @@ var (
 	ErrEmailAlreadyExists   = errors.New("email already exists")
 	ErrInvalidToken         = errors.New("invalid token")
 	ErrPasswordToShort      = errors.New("password to short")
+	ErrTokenReused          = errors.New("refresh token reused")
+	ErrAlreadyVerified      = errors.New("email already verified")
+	ErrMailNotSent          = errors.New("mail not sent")
+	ErrPermissionDenied     = errors.New("permission denied")
+	ErrInvalidCode          = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication already enabled")
+	ErrTooManyAttempts      = errors.New("too many login attempts")
+	ErrUserDeleted          = errors.New("user is scheduled for deletion")
 )
 
+// TooManyAttemptsError is ErrTooManyAttempts with the time after which login can be retried
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyAttempts, e.RetryAfter)
+}
+
+func (e TooManyAttemptsError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
 const ServiceName = "Auth"
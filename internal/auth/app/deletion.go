@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"goads/internal/pkg/errwrap"
+	"time"
+)
+
+const maxEventsLimit = 1000
+
+// Delete schedules deletion of the user after DeletionGrace and revokes all its tokens. The email with
+// a link to restore the account is sent, errors of sending are wrapped with ErrMailNotSent. The data is
+// purged and the event is published to other services only after the grace period
+func (a App) Delete(ctx context.Context, id int64) error {
+	const op = "app.Delete"
+
+	user, err := a.Repo.GetByID(ctx, id)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if !user.DeleteAfter.IsZero() {
+		return errwrap.New(ErrUserDeleted, ServiceName, op).OnObject("user", id)
+	}
+	err = a.Repo.ScheduleDeletion(ctx, id, time.Now().UTC().Add(a.DeletionGrace))
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.revokeUser(ctx, id)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.sendAction(ctx, user, tokens.PurposeRestore)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// RestoreUser cancels deletion of the user by the token from email. The user must log in again,
+// because all its tokens have been revoked on deletion
+func (a App) RestoreUser(ctx context.Context, token string) (users.User, error) {
+	const op = "app.RestoreUser"
+
+	action, err := a.useAction(ctx, a.Mailing.Signer, token, tokens.PurposeRestore)
+	if err != nil {
+		return users.User{}, errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Repo.CancelDeletion(ctx, action.UserID)
+	if err != nil {
+		return users.User{}, errwrap.JoinWithCaller(err, op)
+	}
+	user, err := a.Repo.GetByID(ctx, action.UserID)
+	return user, errwrap.JoinWithCaller(err, op)
+}
+
+// GetUserEvents returns events published after the event with ID after. Limit is bounded by maxEventsLimit
+func (a App) GetUserEvents(ctx context.Context, after int64, limit int) ([]users.Event, error) {
+	const op = "app.GetUserEvents"
+	if limit <= 0 || limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+	events, err := a.Repo.GetUserEvents(ctx, after, limit)
+	return events, errwrap.JoinWithCaller(err, op)
+}
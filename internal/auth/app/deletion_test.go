@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"goads/internal/auth/app/mocks"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"testing"
+	"time"
+)
+
+func TestApp_Delete(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test"}
+	deleted := users.User{ID: 2, Email: "deleted@test.com", Name: "test", DeleteAfter: time.Now().Add(time.Hour)}
+
+	r := mocks.NewRepository(t)
+	r.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	r.On("GetByID", mock.Anything, deleted.ID).Return(deleted, nil)
+	r.
+		On("ScheduleDeletion", mock.Anything, user.ID, mock.MatchedBy(func(after time.Time) bool {
+			return after.Sub(time.Now()) > 23*time.Hour
+		})).
+		Return(nil).
+		Once()
+	r.On("RevokeUserRefresh", mock.Anything, user.ID).Return(nil).Once()
+	a := App{
+		Repo:          r,
+		Revoker:       revoker(t),
+		Mailing:       mailing(t, nil, 1),
+		DeletionGrace: 24 * time.Hour,
+	}
+
+	ctx := context.Background()
+	assert.NoError(t, a.Delete(ctx, user.ID))
+	assert.ErrorIs(t, a.Delete(ctx, deleted.ID), ErrUserDeleted)
+}
+
+func TestApp_Delete_MailNotSent(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test"}
+
+	r := mocks.NewRepository(t)
+	r.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	r.On("ScheduleDeletion", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+	r.On("RevokeUserRefresh", mock.Anything, user.ID).Return(nil).Once()
+	a := App{
+		Repo:          r,
+		Revoker:       revoker(t),
+		Mailing:       mailing(t, assert.AnError, 1),
+		DeletionGrace: time.Hour,
+	}
+	assert.ErrorIs(t, a.Delete(context.Background(), user.ID), ErrMailNotSent, "deletion must be scheduled anyway")
+}
+
+func TestApp_RestoreUser(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test"}
+	expires := time.Now().Add(time.Hour)
+	actions := map[string]tokens.Action{
+		"valid":  {ID: "valid", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeRestore, ExpiresAt: expires},
+		"used":   {ID: "used", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeRestore, ExpiresAt: expires},
+		"purged": {ID: "purged", UserID: 2, Email: "old@test.com", Purpose: tokens.PurposeRestore, ExpiresAt: expires},
+		"reset":  {ID: "reset", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeReset, ExpiresAt: expires},
+	}
+
+	r := actionsRepo(t, user, "used")
+	r.On("CancelDeletion", mock.Anything, user.ID).Return(nil).Once()
+	r.On("CancelDeletion", mock.Anything, int64(2)).Return(ErrNotFound).Once()
+	a := App{
+		Repo:    r,
+		Mailing: Mailing{Signer: parser(t, actions)},
+	}
+
+	ctx := context.Background()
+	got, err := a.RestoreUser(ctx, "valid")
+	assert.NoError(t, err)
+	assert.Equal(t, user, got)
+	_, err = a.RestoreUser(ctx, "used")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = a.RestoreUser(ctx, "reset")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = a.RestoreUser(ctx, "purged")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestApp_GetUserEvents(t *testing.T) {
+	events := []users.Event{{ID: 6, Type: users.EventDeleted, UserID: 1}}
+	r := mocks.NewRepository(t)
+	r.On("GetUserEvents", mock.Anything, int64(5), 10).Return(events, nil).Once()
+	r.On("GetUserEvents", mock.Anything, int64(5), maxEventsLimit).Return(events, nil).Twice()
+	a := App{Repo: r}
+
+	ctx := context.Background()
+	for _, limit := range []int{10, 0, maxEventsLimit + 1} {
+		got, err := a.GetUserEvents(ctx, 5, limit)
+		assert.NoError(t, err)
+		assert.Equal(t, events, got)
+	}
+}
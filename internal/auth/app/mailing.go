@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"goads/internal/pkg/errwrap"
+	"time"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Mailer
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=ActionSigner
+type ActionSigner interface {
+	Sign(ctx context.Context, action tokens.Action) (string, error)
+	// Parse checks signature of the token and returns its action
+	Parse(ctx context.Context, token string) (tokens.Action, error)
+}
+
+// Mailing is configuration of emails with action tokens. URLs must contain %s which is replaced by token
+type Mailing struct {
+	Mailer        Mailer
+	Signer        ActionSigner
+	VerifyURL     string
+	ResetURL      string
+	RestoreURL    string
+	VerifyExpires time.Duration
+	ResetExpires  time.Duration
+}
+
+// sendAction sends email with a link containing signed action token
+func (a App) sendAction(ctx context.Context, user users.User, purpose string) error {
+	const op = "app.sendAction"
+
+	subject, text, url, expires := "Confirm your email",
+		"To confirm your email address follow the link:", a.Mailing.VerifyURL, a.Mailing.VerifyExpires
+	switch purpose {
+	case tokens.PurposeReset:
+		subject, text, url, expires = "Reset your password",
+			"To set a new password follow the link:", a.Mailing.ResetURL, a.Mailing.ResetExpires
+	case tokens.PurposeRestore:
+		subject, text, url, expires = "Your account will be deleted",
+			"Your account and all its data will be deleted. To cancel the deletion follow the link:",
+			a.Mailing.RestoreURL, a.DeletionGrace
+	}
+
+	action, err := tokens.NewAction(user.ID, user.Email, purpose, time.Now().UTC().Add(expires))
+	if err != nil {
+		return errwrap.New(err, ServiceName, op).OnObject("user", user.ID)
+	}
+	token, err := a.Mailing.Signer.Sign(ctx, action)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	body := fmt.Sprintf("Hello, %s!\n\n%s\n%s\n\nThe link expires in %s.", user.Name, text,
+		fmt.Sprintf(url, token), expires)
+	err = a.Mailing.Mailer.Send(ctx, user.Email, subject, body)
+	if err != nil {
+		err = errwrap.New(errors.Join(ErrMailNotSent, err), ServiceName, op).OnObject("user", user.ID)
+	}
+	return err
+}
+
+// sendVerification sends verification email. Errors are wrapped with ErrMailNotSent,
+// so callers can complete their actions while the email can be requested again
+func (a App) sendVerification(ctx context.Context, user users.User) error {
+	const op = "app.sendVerification"
+	err := a.sendAction(ctx, user, tokens.PurposeVerify)
+	if err != nil && !errors.Is(err, ErrMailNotSent) {
+		err = errors.Join(ErrMailNotSent, err)
+	}
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// SendVerification sends verification email again
+func (a App) SendVerification(ctx context.Context, id int64) error {
+	const op = "app.SendVerification"
+
+	user, err := a.Repo.GetByID(ctx, id)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if user.Verified {
+		return errwrap.New(ErrAlreadyVerified, ServiceName, op).OnObject("user", id)
+	}
+	err = a.sendAction(ctx, user, tokens.PurposeVerify)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// useAction parses the token and marks it as used
+func (a App) useAction(ctx context.Context, signer ActionSigner, token string, purpose string) (tokens.Action, error) {
+	const op = "app.useAction"
+
+	action, err := signer.Parse(ctx, token)
+	if err != nil {
+		return action, errwrap.JoinWithCaller(err, op)
+	}
+	if action.Purpose != purpose || action.IsExpired(time.Now().UTC()) {
+		return action, errwrap.New(ErrInvalidToken, ServiceName, op).OnObject("user", action.UserID)
+	}
+	ok, err := a.Repo.UseAction(ctx, action.ID, action.ExpiresAt)
+	if err != nil {
+		return action, errwrap.JoinWithCaller(err, op)
+	}
+	if !ok {
+		return action, errwrap.New(ErrInvalidToken, ServiceName, op).
+			WithDetails("token has been already used").
+			OnObject("user", action.UserID)
+	}
+	return action, nil
+}
+
+// VerifyEmail confirms email of the user. The token is valid only for the address it has been sent to
+func (a App) VerifyEmail(ctx context.Context, token string) (users.User, error) {
+	const op = "app.VerifyEmail"
+
+	action, err := a.useAction(ctx, a.Mailing.Signer, token, tokens.PurposeVerify)
+	if err != nil {
+		return users.User{}, errwrap.JoinWithCaller(err, op)
+	}
+	user, err := a.change(ctx, action.UserID, func(user users.User) users.User {
+		if user.Email == action.Email {
+			user.Verified = true
+		}
+		return user
+	})
+	if err == nil && !user.Verified {
+		err = errwrap.New(ErrInvalidToken, ServiceName, op).
+			WithDetails("email has been changed").
+			OnObject("user", user.ID)
+	}
+	return user, errwrap.JoinWithCaller(err, op)
+}
+
+// RequestPasswordReset sends email with reset link. Unknown emails are ignored to hide registered users
+func (a App) RequestPasswordReset(ctx context.Context, email string) error {
+	const op = "app.RequestPasswordReset"
+
+	user, err := a.Repo.GetByEmail(ctx, email)
+	if errors.Is(err, ErrIncorrectCredentials) {
+		return nil
+	}
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.sendAction(ctx, user, tokens.PurposeReset)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// ResetPassword sets a new password using reset token. All tokens of the user are revoked
+func (a App) ResetPassword(ctx context.Context, token string, password string) error {
+	const op = "app.ResetPassword"
+
+	hash, err := a.Hasher.Generate(ctx, password)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	action, err := a.useAction(ctx, a.Mailing.Signer, token, tokens.PurposeReset)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	_, err = a.setPassword(ctx, action.UserID, hash)
+	return errwrap.JoinWithCaller(err, op)
+}
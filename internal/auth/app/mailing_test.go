@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"goads/internal/auth/app/mocks"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mailing sends emails with error sendErr, they must be sent the number of times
+func mailing(t *testing.T, sendErr error, times int) Mailing {
+	signer := mocks.NewActionSigner(t)
+	signer.
+		On("Sign", mock.Anything, mock.AnythingOfType("tokens.Action")).
+		Return(func(ctx context.Context, action tokens.Action) (string, error) {
+			return fmt.Sprintf("%s-token", action.Purpose), nil
+		}).
+		Times(times)
+	mailer := mocks.NewMailer(t)
+	mailer.
+		On("Send", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"),
+			mock.MatchedBy(func(body string) bool {
+				return strings.Contains(body, "https://goads/action?token=")
+			})).
+		Return(sendErr).
+		Times(times)
+	return Mailing{
+		Mailer:        mailer,
+		Signer:        signer,
+		VerifyURL:     "https://goads/action?token=%s",
+		ResetURL:      "https://goads/action?token=%s",
+		RestoreURL:    "https://goads/action?token=%s",
+		VerifyExpires: time.Hour,
+		ResetExpires:  time.Hour,
+	}
+}
+
+// parser parses tokens to the actions
+func parser(t *testing.T, actions map[string]tokens.Action) *mocks.ActionSigner {
+	signer := mocks.NewActionSigner(t)
+	signer.
+		On("Parse", mock.Anything, mock.AnythingOfType("string")).
+		Return(func(ctx context.Context, token string) (tokens.Action, error) {
+			action, ok := actions[token]
+			if !ok {
+				return tokens.Action{}, ErrInvalidToken
+			}
+			return action, nil
+		})
+	return signer
+}
+
+// actionsRepo stores users and used action tokens
+func actionsRepo(t *testing.T, user users.User, used ...string) *mocks.Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("UseAction", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(func(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+			for _, u := range used {
+				if u == id {
+					return false, nil
+				}
+			}
+			return true, nil
+		}).
+		Maybe()
+	r.On("GetByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+	return r
+}
+
+func TestApp_VerifyEmail(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test"}
+	expires := time.Now().Add(time.Hour)
+	actions := map[string]tokens.Action{
+		"valid":   {ID: "valid", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeVerify, ExpiresAt: expires},
+		"used":    {ID: "used", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeVerify, ExpiresAt: expires},
+		"reset":   {ID: "reset", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeReset, ExpiresAt: expires},
+		"expired": {ID: "expired", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeVerify, ExpiresAt: time.Now()},
+		"changed": {ID: "changed", UserID: 1, Email: "old@test.com", Purpose: tokens.PurposeVerify, ExpiresAt: expires},
+	}
+
+	tests := [...]struct {
+		name    string
+		token   string
+		want    bool
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "correct verification",
+			token: "valid",
+			want:  true,
+		},
+		{
+			name:  "used token",
+			token: "used",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+		{
+			name:  "reset token",
+			token: "reset",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+		{
+			name:  "expired token",
+			token: "expired",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+		{
+			name:  "email has been changed",
+			token: "changed",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+		{
+			name:  "forged token",
+			token: "forged",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidToken, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := actionsRepo(t, user, "used")
+			r.On("Update", mock.Anything, mock.AnythingOfType("users.User")).Return(nil).Maybe()
+			a := App{
+				Repo:    r,
+				Mailing: Mailing{Signer: parser(t, actions)},
+			}
+			got, err := a.VerifyEmail(context.Background(), tt.token)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("VerifyEmail(%v)", tt.token)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equalf(t, tt.want, got.Verified, "VerifyEmail(%v)", tt.token)
+		})
+	}
+}
+
+func TestApp_RequestPasswordReset(t *testing.T) {
+	a := App{
+		Repo:    getByEmailRepo(t),
+		Mailing: mailing(t, nil, 1),
+	}
+	assert.NoError(t, a.RequestPasswordReset(context.Background(), "test@test.com"))
+	assert.NoError(t, a.RequestPasswordReset(context.Background(), "incorrect@credentials.com"),
+		"unknown email must not be revealed")
+}
+
+func TestApp_ResetPassword(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", Name: "test", Password: "old_hash"}
+	expires := time.Now().Add(time.Hour)
+	actions := map[string]tokens.Action{
+		"valid":  {ID: "valid", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeReset, ExpiresAt: expires},
+		"used":   {ID: "used", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeReset, ExpiresAt: expires},
+		"verify": {ID: "verify", UserID: 1, Email: "test@test.com", Purpose: tokens.PurposeVerify, ExpiresAt: expires},
+	}
+
+	r := actionsRepo(t, user, "used")
+	r.
+		On("Update", mock.Anything, mock.MatchedBy(func(u users.User) bool {
+			return u.Password == "new_hash"
+		})).
+		Return(nil).
+		Once()
+	r.On("RevokeUserRefresh", mock.Anything, user.ID).Return(nil).Once()
+	a := App{
+		Repo:    r,
+		Hasher:  hashGenerator(t),
+		Revoker: revoker(t),
+		Mailing: Mailing{Signer: parser(t, actions)},
+	}
+	ctx := context.Background()
+	assert.NoError(t, a.ResetPassword(ctx, "valid", "new"))
+	assert.ErrorIs(t, a.ResetPassword(ctx, "used", "new"), ErrInvalidToken)
+	assert.ErrorIs(t, a.ResetPassword(ctx, "verify", "new"), ErrInvalidToken)
+	assert.ErrorIs(t, a.ResetPassword(ctx, "valid", ""), ErrPasswordToShort, "token must not be used by short password")
+}
@@ -51,6 +51,20 @@ func (_m *Hasher) Generate(ctx context.Context, password string) (string, error)
 	return r0, r1
 }
 
+// NeedsRehash provides a mock function with given fields: hash
+func (_m *Hasher) NeedsRehash(hash string) bool {
+	ret := _m.Called(hash)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewHasher interface {
 	mock.TestingT
 	Cleanup(func())
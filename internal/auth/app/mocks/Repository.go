@@ -4,7 +4,9 @@ package mocks
 
 import (
 	context "context"
+	tokens "goads/internal/auth/tokens"
 	users "goads/internal/auth/users"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -14,8 +16,8 @@ type Repository struct {
 	mock.Mock
 }
 
-// Delete provides a mock function with given fields: ctx, id
-func (_m *Repository) Delete(ctx context.Context, id int64) error {
+// CancelDeletion provides a mock function with given fields: ctx, id
+func (_m *Repository) CancelDeletion(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
 
 	var r0 error
@@ -28,6 +30,98 @@ func (_m *Repository) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
+// DeleteAPIKey provides a mock function with given fields: ctx, id, userID
+func (_m *Repository) DeleteAPIKey(ctx context.Context, id int64, userID int64) error {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteTOTP provides a mock function with given fields: ctx, userID
+func (_m *Repository) DeleteTOTP(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableTOTP provides a mock function with given fields: ctx, userID, step
+func (_m *Repository) EnableTOTP(ctx context.Context, userID int64, step int64) error {
+	ret := _m.Called(ctx, userID, step)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, userID, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAPIKey provides a mock function with given fields: ctx, hash
+func (_m *Repository) GetAPIKey(ctx context.Context, hash string) (tokens.APIKey, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 tokens.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (tokens.APIKey, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) tokens.APIKey); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(tokens.APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeys provides a mock function with given fields: ctx, userID
+func (_m *Repository) GetAPIKeys(ctx context.Context, userID int64) ([]tokens.APIKey, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []tokens.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]tokens.APIKey, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []tokens.APIKey); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]tokens.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByEmail provides a mock function with given fields: ctx, email
 func (_m *Repository) GetByEmail(ctx context.Context, email string) (users.User, error) {
 	ret := _m.Called(ctx, email)
@@ -76,6 +170,202 @@ func (_m *Repository) GetByID(ctx context.Context, id int64) (users.User, error)
 	return r0, r1
 }
 
+// GetRefresh provides a mock function with given fields: ctx, hash
+func (_m *Repository) GetRefresh(ctx context.Context, hash string) (tokens.Refresh, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 tokens.Refresh
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (tokens.Refresh, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) tokens.Refresh); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(tokens.Refresh)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessions provides a mock function with given fields: ctx, userID, moment
+func (_m *Repository) GetSessions(ctx context.Context, userID int64, moment time.Time) ([]tokens.Session, error) {
+	ret := _m.Called(ctx, userID, moment)
+
+	var r0 []tokens.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) ([]tokens.Session, error)); ok {
+		return rf(ctx, userID, moment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) []tokens.Session); ok {
+		r0 = rf(ctx, userID, moment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]tokens.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time) error); ok {
+		r1 = rf(ctx, userID, moment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTOTP provides a mock function with given fields: ctx, userID
+func (_m *Repository) GetTOTP(ctx context.Context, userID int64) (users.TOTP, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 users.TOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (users.TOTP, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) users.TOTP); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(users.TOTP)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserEvents provides a mock function with given fields: ctx, after, limit
+func (_m *Repository) GetUserEvents(ctx context.Context, after int64, limit int) ([]users.Event, error) {
+	ret := _m.Called(ctx, after, limit)
+
+	var r0 []users.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([]users.Event, error)); ok {
+		return rf(ctx, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) []users.Event); ok {
+		r0 = rf(ctx, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]users.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RehashPassword provides a mock function with given fields: ctx, id, old, hash
+func (_m *Repository) RehashPassword(ctx context.Context, id int64, old string, hash string) error {
+	ret := _m.Called(ctx, id, old, hash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) error); ok {
+		r0 = rf(ctx, id, old, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeFamily provides a mock function with given fields: ctx, family
+func (_m *Repository) RevokeFamily(ctx context.Context, family string) error {
+	ret := _m.Called(ctx, family)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, family)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeOtherSessions provides a mock function with given fields: ctx, userID, current
+func (_m *Repository) RevokeOtherSessions(ctx context.Context, userID int64, current string) ([]string, error) {
+	ret := _m.Called(ctx, userID, current)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) ([]string, error)); ok {
+		return rf(ctx, userID, current)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []string); ok {
+		r0 = rf(ctx, userID, current)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, userID, current)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: ctx, id, userID
+func (_m *Repository) RevokeSession(ctx context.Context, id string, userID int64) error {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeUserRefresh provides a mock function with given fields: ctx, userID
+func (_m *Repository) RevokeUserRefresh(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ScheduleDeletion provides a mock function with given fields: ctx, id, after
+func (_m *Repository) ScheduleDeletion(ctx context.Context, id int64, after time.Time) error {
+	ret := _m.Called(ctx, id, after)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) error); ok {
+		r0 = rf(ctx, id, after)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Store provides a mock function with given fields: ctx, user
 func (_m *Repository) Store(ctx context.Context, user users.User) (int64, error) {
 	ret := _m.Called(ctx, user)
@@ -100,6 +390,86 @@ func (_m *Repository) Store(ctx context.Context, user users.User) (int64, error)
 	return r0, r1
 }
 
+// StoreAPIKey provides a mock function with given fields: ctx, key
+func (_m *Repository) StoreAPIKey(ctx context.Context, key tokens.APIKey) (int64, error) {
+	ret := _m.Called(ctx, key)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.APIKey) (int64, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.APIKey) int64); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, tokens.APIKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreRefresh provides a mock function with given fields: ctx, token
+func (_m *Repository) StoreRefresh(ctx context.Context, token tokens.Refresh) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.Refresh) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreSession provides a mock function with given fields: ctx, session
+func (_m *Repository) StoreSession(ctx context.Context, session tokens.Session) error {
+	ret := _m.Called(ctx, session)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.Session) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreTOTP provides a mock function with given fields: ctx, totp, recoveryCodes
+func (_m *Repository) StoreTOTP(ctx context.Context, totp users.TOTP, recoveryCodes []string) error {
+	ret := _m.Called(ctx, totp, recoveryCodes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.TOTP, []string) error); ok {
+		r0 = rf(ctx, totp, recoveryCodes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchSession provides a mock function with given fields: ctx, id, moment, expiresAt
+func (_m *Repository) TouchSession(ctx context.Context, id string, moment time.Time, expiresAt time.Time) error {
+	ret := _m.Called(ctx, id, moment, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r0 = rf(ctx, id, moment, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, user
 func (_m *Repository) Update(ctx context.Context, user users.User) error {
 	ret := _m.Called(ctx, user)
@@ -114,6 +484,102 @@ func (_m *Repository) Update(ctx context.Context, user users.User) error {
 	return r0
 }
 
+// UseAction provides a mock function with given fields: ctx, id, expiresAt
+func (_m *Repository) UseAction(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, id, expiresAt)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, id, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, id, expiresAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, id, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseRecoveryCode provides a mock function with given fields: ctx, userID, hash
+func (_m *Repository) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	ret := _m.Called(ctx, userID, hash)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (bool, error)); ok {
+		return rf(ctx, userID, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) bool); ok {
+		r0 = rf(ctx, userID, hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, userID, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseRefresh provides a mock function with given fields: ctx, id
+func (_m *Repository) UseRefresh(ctx context.Context, id int64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseTOTPStep provides a mock function with given fields: ctx, userID, step
+func (_m *Repository) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	ret := _m.Called(ctx, userID, step)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return rf(ctx, userID, step)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = rf(ctx, userID, step)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, userID, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	tokens "goads/internal/auth/tokens"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,23 +14,23 @@ type Tokenizer struct {
 	mock.Mock
 }
 
-// Generate provides a mock function with given fields: ctx, id
-func (_m *Tokenizer) Generate(ctx context.Context, id int64) (string, error) {
-	ret := _m.Called(ctx, id)
+// Generate provides a mock function with given fields: ctx, claims
+func (_m *Tokenizer) Generate(ctx context.Context, claims tokens.Claims) (string, error) {
+	ret := _m.Called(ctx, claims)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) (string, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.Claims) (string, error)); ok {
+		return rf(ctx, claims)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) string); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.Claims) string); ok {
+		r0 = rf(ctx, claims)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, tokens.Claims) error); ok {
+		r1 = rf(ctx, claims)
 	} else {
 		r1 = ret.Error(1)
 	}
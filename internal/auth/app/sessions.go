@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"goads/internal/pkg/errwrap"
+	"time"
+)
+
+// startSession stores a new session of the client and issues its first pair of tokens
+func (a App) startSession(ctx context.Context, user users.User, client tokens.Client) (tokens.Pair, error) {
+	const op = "app.startSession"
+
+	now := time.Now().UTC()
+	session, err := tokens.NewSession(user.ID, client, now, now.Add(a.RefreshExpires))
+	if err != nil {
+		return tokens.Pair{}, errwrap.New(err, ServiceName, op).OnObject("user", user.ID)
+	}
+	if err := a.Repo.StoreSession(ctx, session); err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	pair, err := a.issue(ctx, user, session.ID)
+	return pair, errwrap.JoinWithCaller(err, op)
+}
+
+// ListSessions returns active sessions of the user, the last used one goes first
+func (a App) ListSessions(ctx context.Context, userID int64) ([]tokens.Session, error) {
+	const op = "app.ListSessions"
+
+	sessions, err := a.Repo.GetSessions(ctx, userID, time.Now().UTC())
+	return sessions, errwrap.JoinWithCaller(err, op)
+}
+
+// RevokeSession logs the user out of the session. Its refresh and access tokens stop being accepted
+func (a App) RevokeSession(ctx context.Context, userID int64, id string) error {
+	const op = "app.RevokeSession"
+
+	if err := a.Repo.RevokeSession(ctx, id, userID); err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err := a.Revoker.RevokeSession(ctx, id)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// RevokeAllOtherSessions logs the user out of all sessions except the current one
+func (a App) RevokeAllOtherSessions(ctx context.Context, userID int64, current string) error {
+	const op = "app.RevokeAllOtherSessions"
+
+	ids, err := a.Repo.RevokeOtherSessions(ctx, userID, current)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	for _, id := range ids {
+		if err := a.Revoker.RevokeSession(ctx, id); err != nil {
+			return errwrap.JoinWithCaller(err, op)
+		}
+	}
+	return nil
+}
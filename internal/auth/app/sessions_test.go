@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"goads/internal/auth/app/mocks"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"testing"
+	"time"
+)
+
+func TestApp_startSession(t *testing.T) {
+	client := tokens.Client{Device: "laptop", IP: "127.0.0.1", UserAgent: "test"}
+	var session tokens.Session
+	r := mocks.NewRepository(t)
+	r.
+		On("StoreSession", mock.Anything, mock.AnythingOfType("tokens.Session")).
+		Return(func(_ context.Context, s tokens.Session) error {
+			session = s
+			return nil
+		})
+	r.
+		On("StoreRefresh", mock.Anything, mock.MatchedBy(func(refresh tokens.Refresh) bool {
+			return refresh.Family == session.ID
+		})).
+		Return(nil)
+	tok := mocks.NewTokenizer(t)
+	tok.
+		On("Generate", mock.Anything, mock.MatchedBy(func(claims tokens.Claims) bool {
+			return claims.Session == session.ID
+		})).
+		Return("token", nil)
+	a := App{Repo: r, Tokenizer: tok, RefreshExpires: time.Hour}
+
+	pair, err := a.startSession(context.Background(), users.User{ID: 1}, client)
+	require.NoError(t, err)
+	assert.Equal(t, "token", pair.Access)
+	assert.NotEmpty(t, session.ID)
+	assert.Equal(t, int64(1), session.UserID)
+	assert.Equal(t, client, session.Client)
+	assert.Equal(t, time.Hour, session.ExpiresAt.Sub(session.CreatedAt))
+}
+
+func TestApp_RevokeSession(t *testing.T) {
+	ctx := context.Background()
+	r := mocks.NewRepository(t)
+	r.On("RevokeSession", mock.Anything, "own", int64(1)).Return(nil)
+	r.On("RevokeSession", mock.Anything, "foreign", int64(1)).Return(ErrNotFound)
+	rev := mocks.NewRevoker(t)
+	rev.On("RevokeSession", mock.Anything, "own").Return(nil).Once()
+	a := App{Repo: r, Revoker: rev}
+
+	assert.NoError(t, a.RevokeSession(ctx, 1, "own"))
+	assert.ErrorIs(t, a.RevokeSession(ctx, 1, "foreign"), ErrNotFound)
+}
+
+func TestApp_RevokeAllOtherSessions(t *testing.T) {
+	r := mocks.NewRepository(t)
+	r.On("RevokeOtherSessions", mock.Anything, int64(1), "current").Return([]string{"first", "second"}, nil)
+	rev := mocks.NewRevoker(t)
+	rev.On("RevokeSession", mock.Anything, "first").Return(nil).Once()
+	rev.On("RevokeSession", mock.Anything, "second").Return(nil).Once()
+	a := App{Repo: r, Revoker: rev}
+
+	assert.NoError(t, a.RevokeAllOtherSessions(context.Background(), 1, "current"))
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"goads/internal/pkg/errwrap"
+	"strings"
+	"time"
+)
+
+// recoveryCodes is a number of recovery codes generated on enrolment
+const recoveryCodes = 10
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=OTP
+type OTP interface {
+	GenerateSecret() (string, error)
+	// URI returns otpauth:// URI of the secret for authenticator apps
+	URI(secret string, account string) string
+	// Validate checks the code at the moment with clock skew tolerance and returns time step of the code
+	Validate(secret string, code string, moment time.Time) (int64, bool)
+}
+
+// TwoFactor is configuration of TOTP second factor. Challenge tokens of two-step login are signed by Signer
+type TwoFactor struct {
+	OTP              OTP
+	Signer           ActionSigner
+	ChallengeExpires time.Duration
+}
+
+// EnrollTOTP generates a new secret of the user and recovery codes. The second factor is enabled
+// only after confirmation by ConfirmTOTP. Enrolment which is not confirmed is replaced
+func (a App) EnrollTOTP(ctx context.Context, userID int64) (string, []string, error) {
+	const op = "app.EnrollTOTP"
+
+	user, err := a.Repo.GetByID(ctx, userID)
+	if err != nil {
+		return "", nil, errwrap.JoinWithCaller(err, op)
+	}
+	if user.TwoFactor {
+		return "", nil, errwrap.New(ErrTwoFactorEnabled, ServiceName, op).OnObject("user", userID)
+	}
+	secret, err := a.TwoFactor.OTP.GenerateSecret()
+	if err != nil {
+		return "", nil, errwrap.New(err, ServiceName, op).OnObject("user", userID)
+	}
+	codes := make([]string, recoveryCodes)
+	hashes := make([]string, recoveryCodes)
+	for i := range codes {
+		codes[i], err = tokens.NewRecoveryCode()
+		if err != nil {
+			return "", nil, errwrap.New(err, ServiceName, op).OnObject("user", userID)
+		}
+		hashes[i] = tokens.Hash(codes[i])
+	}
+	err = a.Repo.StoreTOTP(ctx, users.TOTP{UserID: userID, Secret: secret}, hashes)
+	if err != nil {
+		return "", nil, errwrap.JoinWithCaller(err, op)
+	}
+	return a.TwoFactor.OTP.URI(secret, user.Email), codes, nil
+}
+
+// ConfirmTOTP enables the second factor if the code is valid for the enrolled secret
+func (a App) ConfirmTOTP(ctx context.Context, userID int64, code string) error {
+	const op = "app.ConfirmTOTP"
+
+	totp, err := a.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if totp.Enabled {
+		return errwrap.New(ErrTwoFactorEnabled, ServiceName, op).OnObject("user", userID)
+	}
+	step, ok := a.TwoFactor.OTP.Validate(totp.Secret, code, time.Now().UTC())
+	if !ok {
+		return errwrap.New(ErrInvalidCode, ServiceName, op).OnObject("user", userID)
+	}
+	err = a.Repo.EnableTOTP(ctx, userID, step)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// DisableTOTP removes the second factor. It must be confirmed by a valid code or a recovery code
+func (a App) DisableTOTP(ctx context.Context, userID int64, code string) error {
+	const op = "app.DisableTOTP"
+
+	err := a.checkSecondFactor(ctx, userID, code)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	err = a.Repo.DeleteTOTP(ctx, userID)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// checkSecondFactor accepts TOTP code or recovery code of the user with enabled second factor.
+// Each TOTP code is accepted once, each recovery code is deleted after usage
+func (a App) checkSecondFactor(ctx context.Context, userID int64, code string) error {
+	const op = "app.checkSecondFactor"
+
+	totp, err := a.Repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if !totp.Enabled {
+		return errwrap.New(ErrNotFound, ServiceName, op).
+			WithDetails("two-factor authentication is not enabled").
+			OnObject("user", userID)
+	}
+
+	var used bool
+	if step, ok := a.TwoFactor.OTP.Validate(totp.Secret, code, time.Now().UTC()); ok {
+		used, err = a.Repo.UseTOTPStep(ctx, userID, step)
+	} else {
+		used, err = a.Repo.UseRecoveryCode(ctx, userID, tokens.Hash(strings.ToLower(strings.TrimSpace(code))))
+	}
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if !used {
+		return errwrap.New(ErrInvalidCode, ServiceName, op).OnObject("user", userID)
+	}
+	return nil
+}
+
+// challenge returns a single-use token which must be sent with the second factor to finish login
+func (a App) challenge(ctx context.Context, user users.User) (string, error) {
+	const op = "app.challenge"
+
+	action, err := tokens.NewAction(user.ID, user.Email, tokens.PurposeTwoFactor,
+		time.Now().UTC().Add(a.TwoFactor.ChallengeExpires))
+	if err != nil {
+		return "", errwrap.New(err, ServiceName, op).OnObject("user", user.ID)
+	}
+	token, err := a.TwoFactor.Signer.Sign(ctx, action)
+	return token, errwrap.JoinWithCaller(err, op)
+}
+
+// AuthenticateTOTP finishes two-step login by the challenge token returned by Authenticate and the code.
+// The challenge is used once regardless of the code, so the code cannot be guessed by one challenge.
+// Wrong codes are login failures, so new challenges are throttled like attempts to guess the password
+func (a App) AuthenticateTOTP(ctx context.Context, challenge string, code string, client tokens.Client) (tokens.Pair, error) {
+	const op = "app.AuthenticateTOTP"
+
+	action, err := a.useAction(ctx, a.TwoFactor.Signer, challenge, tokens.PurposeTwoFactor)
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	wait, err := a.Guard.Wait(ctx, action.Email, client.IP)
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	if wait > 0 {
+		return tokens.Pair{}, errwrap.New(TooManyAttemptsError{RetryAfter: wait}, ServiceName, op).
+			WithDetails(fmt.Sprintf("email: %s, ip: %s", action.Email, client.IP))
+	}
+	err = a.checkSecondFactor(ctx, action.UserID, code)
+	if errors.Is(err, ErrInvalidCode) {
+		err = a.failed(ctx, err, users.LoginFailure{
+			UserID: action.UserID,
+			Email:  action.Email,
+			IP:     client.IP,
+			Reason: "wrong second factor code",
+		})
+	}
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	if err := a.Guard.Succeeded(ctx, action.Email); err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	user, err := a.Repo.GetByID(ctx, action.UserID)
+	if err != nil {
+		return tokens.Pair{}, errwrap.JoinWithCaller(err, op)
+	}
+	pair, err := a.startSession(ctx, user, client)
+	return pair, errwrap.JoinWithCaller(err, op)
+}
@@ -0,0 +1,261 @@
+package app
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"goads/internal/auth/app/mocks"
+	"goads/internal/auth/tokens"
+	"goads/internal/auth/users"
+	"testing"
+	"time"
+)
+
+// otp accepts code 123456 of step 100 and code 654321 of step 101
+func otp(t *testing.T) *mocks.OTP {
+	o := mocks.NewOTP(t)
+	o.On("GenerateSecret").Return("SECRET", nil).Maybe()
+	o.
+		On("URI", "SECRET", mock.AnythingOfType("string")).
+		Return(func(secret string, account string) string {
+			return "otpauth://totp/goads:" + account + "?secret=" + secret
+		}).
+		Maybe()
+	o.
+		On("Validate", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(func(secret string, code string, moment time.Time) (int64, bool) {
+			switch code {
+			case "123456":
+				return 100, true
+			case "654321":
+				return 101, true
+			}
+			return 0, false
+		}).
+		Maybe()
+	return o
+}
+
+// twoFactorRepo stores the second factor of the user in totp and hashes of recovery codes
+func twoFactorRepo(t *testing.T, user users.User, totp *users.TOTP, codes map[string]bool) *mocks.Repository {
+	used := make(map[string]bool)
+	r := mocks.NewRepository(t)
+	r.On("GetByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+	r.On("GetByEmail", mock.Anything, user.Email).Return(user, nil).Maybe()
+	r.
+		On("GetTOTP", mock.Anything, user.ID).
+		Return(func(ctx context.Context, id int64) (users.TOTP, error) {
+			if totp == nil {
+				return users.TOTP{}, ErrNotFound
+			}
+			return *totp, nil
+		}).
+		Maybe()
+	r.
+		On("StoreTOTP", mock.Anything, mock.AnythingOfType("users.TOTP"), mock.AnythingOfType("[]string")).
+		Return(func(ctx context.Context, stored users.TOTP, hashes []string) error {
+			*totp = stored
+			for _, h := range hashes {
+				codes[h] = true
+			}
+			return nil
+		}).
+		Maybe()
+	r.
+		On("UseTOTPStep", mock.Anything, user.ID, mock.AnythingOfType("int64")).
+		Return(func(ctx context.Context, id int64, step int64) (bool, error) {
+			if step <= totp.LastStep {
+				return false, nil
+			}
+			totp.LastStep = step
+			return true, nil
+		}).
+		Maybe()
+	r.
+		On("EnableTOTP", mock.Anything, user.ID, mock.AnythingOfType("int64")).
+		Return(func(ctx context.Context, id int64, step int64) error {
+			totp.Enabled, totp.LastStep = true, step
+			return nil
+		}).
+		Maybe()
+	r.
+		On("UseRecoveryCode", mock.Anything, user.ID, mock.AnythingOfType("string")).
+		Return(func(ctx context.Context, id int64, hash string) (bool, error) {
+			ok := codes[hash]
+			delete(codes, hash)
+			return ok, nil
+		}).
+		Maybe()
+	r.
+		On("UseAction", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(func(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+			ok := !used[id]
+			used[id] = true
+			return ok, nil
+		}).
+		Maybe()
+	r.On("StoreSession", mock.Anything, mock.AnythingOfType("tokens.Session")).Return(nil).Maybe()
+	r.On("StoreRefresh", mock.Anything, mock.AnythingOfType("tokens.Refresh")).Return(nil).Maybe()
+	return r
+}
+
+func TestApp_Authenticate_TwoFactor(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", TwoFactor: true}
+	signer := mocks.NewActionSigner(t)
+	signer.
+		On("Sign", mock.Anything, mock.MatchedBy(func(action tokens.Action) bool {
+			return action.Purpose == tokens.PurposeTwoFactor && action.UserID == user.ID
+		})).
+		Return("challenge", nil)
+	succeeded := 0
+	a := App{
+		Repo:      twoFactorRepo(t, user, nil, nil),
+		Hasher:    hashComparator(t),
+		TwoFactor: TwoFactor{Signer: signer, ChallengeExpires: time.Minute},
+		Guard:     countingGuard{max: 1, failures: &[]users.LoginFailure{}, succeeded: &succeeded},
+	}
+
+	pair, err := a.Authenticate(context.Background(), user.Email, "test", tokens.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, tokens.Pair{Challenge: "challenge"}, pair, "tokens must not be issued before the second factor")
+	assert.Zero(t, succeeded, "failures must not be reset before the second factor")
+}
+
+func TestApp_AuthenticateTOTP(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", TwoFactor: true}
+	expires := time.Now().Add(time.Minute)
+	challenge := func(id string) tokens.Action {
+		return tokens.Action{ID: id, UserID: 1, Email: user.Email, Purpose: tokens.PurposeTwoFactor, ExpiresAt: expires}
+	}
+	actions := map[string]tokens.Action{
+		"first":  challenge("first"),
+		"second": challenge("second"),
+		"third":  challenge("third"),
+		"fourth": challenge("fourth"),
+		"reset":  {ID: "reset", UserID: 1, Email: user.Email, Purpose: tokens.PurposeReset, ExpiresAt: expires},
+	}
+	totp := &users.TOTP{UserID: 1, Secret: "SECRET", Enabled: true, LastStep: 99}
+	codes := map[string]bool{tokens.Hash("aaaaa-bbbbb"): true}
+	a := App{
+		Repo:      twoFactorRepo(t, user, totp, codes),
+		Tokenizer: tokenizer(t),
+		TwoFactor: TwoFactor{OTP: otp(t), Signer: parser(t, actions)},
+		Guard:     loginGuard(t, 0, nil),
+	}
+	ctx := context.Background()
+
+	pair, err := a.AuthenticateTOTP(ctx, "first", "123456", tokens.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", pair.Access)
+	assert.NotEmpty(t, pair.Refresh)
+
+	_, err = a.AuthenticateTOTP(ctx, "first", "654321", tokens.Client{})
+	assert.ErrorIs(t, err, ErrInvalidToken, "challenge is single-use")
+
+	_, err = a.AuthenticateTOTP(ctx, "second", "123456", tokens.Client{})
+	assert.ErrorIs(t, err, ErrInvalidCode, "code of used step is rejected")
+
+	_, err = a.AuthenticateTOTP(ctx, "third", " AAAAA-BBBBB ", tokens.Client{})
+	assert.NoError(t, err, "recovery code is accepted")
+
+	_, err = a.AuthenticateTOTP(ctx, "fourth", "aaaaa-bbbbb", tokens.Client{})
+	assert.ErrorIs(t, err, ErrInvalidCode, "recovery code is single-use")
+
+	_, err = a.AuthenticateTOTP(ctx, "reset", "654321", tokens.Client{})
+	assert.ErrorIs(t, err, ErrInvalidToken, "only challenge tokens are accepted")
+}
+
+// countingGuard rejects attempts after max failures and counts successes
+type countingGuard struct {
+	max       int
+	failures  *[]users.LoginFailure
+	succeeded *int
+}
+
+func (g countingGuard) Wait(context.Context, string, string) (time.Duration, error) {
+	if len(*g.failures) >= g.max {
+		return time.Minute, nil
+	}
+	return 0, nil
+}
+
+func (g countingGuard) Failed(_ context.Context, failure users.LoginFailure) error {
+	*g.failures = append(*g.failures, failure)
+	return nil
+}
+
+func (g countingGuard) Succeeded(context.Context, string) error {
+	*g.succeeded++
+	return nil
+}
+
+func TestApp_AuthenticateTOTP_Throttled(t *testing.T) {
+	user := users.User{ID: 1, Email: "test@test.com", TwoFactor: true}
+	actions := make(map[string]tokens.Action)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		actions[id] = tokens.Action{
+			ID: id, UserID: 1, Email: user.Email, Purpose: tokens.PurposeTwoFactor, ExpiresAt: time.Now().Add(time.Minute),
+		}
+	}
+	totp := &users.TOTP{UserID: 1, Secret: "SECRET", Enabled: true, LastStep: 99}
+	var failures []users.LoginFailure
+	succeeded := 0
+	signer := parser(t, actions)
+	a := App{
+		Repo:      twoFactorRepo(t, user, totp, map[string]bool{}),
+		Tokenizer: tokenizer(t),
+		TwoFactor: TwoFactor{OTP: otp(t), Signer: signer},
+		Guard:     countingGuard{max: 2, failures: &failures, succeeded: &succeeded},
+	}
+	ctx := context.Background()
+	client := tokens.Client{IP: "127.0.0.1"}
+
+	_, err := a.AuthenticateTOTP(ctx, "1", "000000", client)
+	assert.ErrorIs(t, err, ErrInvalidCode)
+	_, err = a.AuthenticateTOTP(ctx, "2", "111111", client)
+	assert.ErrorIs(t, err, ErrInvalidCode)
+	assert.Equal(t, []users.LoginFailure{
+		{UserID: 1, Email: user.Email, IP: "127.0.0.1", Reason: "wrong second factor code", CreatedAt: failures[0].CreatedAt},
+		{UserID: 1, Email: user.Email, IP: "127.0.0.1", Reason: "wrong second factor code", CreatedAt: failures[1].CreatedAt},
+	}, failures, "wrong codes are login failures")
+
+	_, err = a.AuthenticateTOTP(ctx, "3", "123456", client)
+	assert.ErrorIs(t, err, ErrTooManyAttempts, "even the correct code is rejected after too many wrong ones")
+	assert.Zero(t, succeeded, "failures are not reset before the second factor")
+
+	failures = nil
+	_, err = a.AuthenticateTOTP(ctx, "4", "123456", client)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestApp_EnrollTOTP(t *testing.T) {
+	ctx := context.Background()
+	user := users.User{ID: 1, Email: "test@test.com"}
+	totp := &users.TOTP{}
+	codes := make(map[string]bool)
+	a := App{
+		Repo:      twoFactorRepo(t, user, totp, codes),
+		TwoFactor: TwoFactor{OTP: otp(t)},
+	}
+
+	uri, recovery, err := a.EnrollTOTP(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/goads:test@test.com?secret=SECRET", uri)
+	assert.Len(t, recovery, recoveryCodes)
+	for _, c := range recovery {
+		assert.True(t, codes[tokens.Hash(c)], "only hashes of recovery codes are stored")
+	}
+	assert.False(t, totp.Enabled, "second factor is enabled only after confirmation")
+
+	assert.ErrorIs(t, a.ConfirmTOTP(ctx, user.ID, "000000"), ErrInvalidCode)
+	assert.NoError(t, a.ConfirmTOTP(ctx, user.ID, "123456"))
+	assert.Equal(t, users.TOTP{UserID: 1, Secret: "SECRET", Enabled: true, LastStep: 100}, *totp)
+
+	enabled := App{
+		Repo:      twoFactorRepo(t, users.User{ID: 1, Email: "test@test.com", TwoFactor: true}, totp, codes),
+		TwoFactor: TwoFactor{OTP: otp(t)},
+	}
+	_, _, err = enabled.EnrollTOTP(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+}
@@ -3,19 +3,42 @@ package grpc
 import (
 	"context"
 	"goads/internal/auth/proto"
+	"goads/internal/auth/tokens"
 	"goads/internal/auth/users"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type App interface {
 	Register(ctx context.Context, email string, name string, password string) (users.User, error)
-	Authenticate(ctx context.Context, email string, password string) (string, error)
+	Authenticate(ctx context.Context, email string, password string, client tokens.Client) (tokens.Pair, error)
+	Refresh(ctx context.Context, token string) (tokens.Pair, error)
+	Logout(ctx context.Context, token string, access string) error
+	GetPublicKeys(ctx context.Context) ([]tokens.PublicKey, error)
+	GetRevocations(ctx context.Context) (tokens.Revocations, error)
 	GetByID(ctx context.Context, id int64) (users.User, error)
 	ChangeEmail(ctx context.Context, id int64, email string) (users.User, error)
 	ChangeName(ctx context.Context, id int64, name string) (users.User, error)
 	ChangePassword(ctx context.Context, id int64, password string) (users.User, error)
+	SetRole(ctx context.Context, id int64, role string) (users.User, error)
 	Delete(ctx context.Context, id int64) error
-	Validate(ctx context.Context, token string) (int64, error)
+	RestoreUser(ctx context.Context, token string) (users.User, error)
+	GetUserEvents(ctx context.Context, after int64, limit int) ([]users.Event, error)
+	Validate(ctx context.Context, token string) (tokens.Claims, error)
+	ValidateAPIKey(ctx context.Context, key string) (tokens.Claims, error)
+	CreateAPIKey(ctx context.Context, userID int64, name string, scopes []string) (string, tokens.APIKey, error)
+	GetAPIKeys(ctx context.Context, userID int64) ([]tokens.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID int64, id int64) error
+	AuthenticateTOTP(ctx context.Context, challenge string, code string, client tokens.Client) (tokens.Pair, error)
+	EnrollTOTP(ctx context.Context, userID int64) (string, []string, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) error
+	DisableTOTP(ctx context.Context, userID int64, code string) error
+	ListSessions(ctx context.Context, userID int64) ([]tokens.Session, error)
+	RevokeSession(ctx context.Context, userID int64, id string) error
+	RevokeAllOtherSessions(ctx context.Context, userID int64, current string) error
+	SendVerification(ctx context.Context, id int64) error
+	VerifyEmail(ctx context.Context, token string) (users.User, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
 }
 
 type Service struct {
@@ -24,10 +47,10 @@ type Service struct {
 
 func (s Service) Register(ctx context.Context, request *proto.RegisterRequest) (*proto.RegisterResponse, error) {
 	user, err := s.app.Register(ctx, request.Email, request.Name, request.Password)
-	if err != nil {
+	if err = skipMailError(err); err != nil {
 		return nil, getErrorStatus(err)
 	}
-	token, err := s.app.Authenticate(ctx, request.Email, request.Password)
+	token, err := s.app.Authenticate(ctx, request.Email, request.Password, tokens.Client{})
 	return &proto.RegisterResponse{
 		User:  userToInfoResponse(user),
 		Token: tokenToResponse(token),
@@ -35,13 +58,64 @@ func (s Service) Register(ctx context.Context, request *proto.RegisterRequest) (
 }
 
 func (s Service) Authenticate(ctx context.Context, request *proto.AuthenticateRequest) (*proto.TokenResponse, error) {
-	token, err := s.app.Authenticate(ctx, request.Email, request.Password)
+	token, err := s.app.Authenticate(ctx, request.Email, request.Password, tokens.Client{
+		Device:    request.Device,
+		IP:        request.Ip,
+		UserAgent: request.UserAgent,
+	})
 	return tokenToResponse(token), getErrorStatus(err)
 }
 
+func (s Service) AuthenticateTOTP(ctx context.Context, request *proto.AuthenticateTOTPRequest) (*proto.TokenResponse, error) {
+	pair, err := s.app.AuthenticateTOTP(ctx, request.ChallengeToken, request.Code, tokens.Client{
+		Device:    request.Device,
+		IP:        request.Ip,
+		UserAgent: request.UserAgent,
+	})
+	return tokenToResponse(pair), getErrorStatus(err)
+}
+
+func (s Service) Refresh(ctx context.Context, request *proto.RefreshRequest) (*proto.TokenResponse, error) {
+	pair, err := s.app.Refresh(ctx, request.RefreshToken)
+	return tokenToResponse(pair), getErrorStatus(err)
+}
+
+func (s Service) Logout(ctx context.Context, request *proto.RefreshRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.Logout(ctx, request.RefreshToken, request.AccessToken))
+}
+
+func (s Service) GetPublicKeys(ctx context.Context, _ *emptypb.Empty) (*proto.PublicKeysResponse, error) {
+	keys, err := s.app.GetPublicKeys(ctx)
+	return publicKeysToResponse(keys), getErrorStatus(err)
+}
+
+func (s Service) GetRevocations(ctx context.Context, _ *emptypb.Empty) (*proto.RevocationsResponse, error) {
+	list, err := s.app.GetRevocations(ctx)
+	return revocationsToResponse(list), getErrorStatus(err)
+}
+
 func (s Service) Validate(ctx context.Context, request *proto.ValidateRequest) (*proto.UserIDResponse, error) {
-	id, err := s.app.Validate(ctx, request.Token)
-	return &proto.UserIDResponse{Id: id}, getErrorStatus(err)
+	claims, err := s.app.Validate(ctx, request.Token)
+	return claimsToResponse(claims), getErrorStatus(err)
+}
+
+func (s Service) ValidateAPIKey(ctx context.Context, request *proto.ValidateRequest) (*proto.UserIDResponse, error) {
+	claims, err := s.app.ValidateAPIKey(ctx, request.Token)
+	return claimsToResponse(claims), getErrorStatus(err)
+}
+
+func (s Service) CreateAPIKey(ctx context.Context, request *proto.CreateAPIKeyRequest) (*proto.CreateAPIKeyResponse, error) {
+	secret, key, err := s.app.CreateAPIKey(ctx, request.UserId, request.Name, request.Scopes)
+	return &proto.CreateAPIKeyResponse{Key: apiKeyToResponse(key), Secret: secret}, getErrorStatus(err)
+}
+
+func (s Service) GetAPIKeys(ctx context.Context, request *proto.GetUserByIDRequest) (*proto.APIKeysResponse, error) {
+	keys, err := s.app.GetAPIKeys(ctx, request.Id)
+	return apiKeysToResponse(keys), getErrorStatus(err)
+}
+
+func (s Service) RevokeAPIKey(ctx context.Context, request *proto.RevokeAPIKeyRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.RevokeAPIKey(ctx, request.UserId, request.Id))
 }
 
 func (s Service) ChangeName(ctx context.Context, request *proto.ChangeUserNameRequest) (*proto.UserInfoResponse, error) {
@@ -51,7 +125,7 @@ func (s Service) ChangeName(ctx context.Context, request *proto.ChangeUserNameRe
 
 func (s Service) ChangeEmail(ctx context.Context, request *proto.ChangeUserEmailRequest) (*proto.UserInfoResponse, error) {
 	user, err := s.app.ChangeEmail(ctx, request.Id, request.Email)
-	return userToInfoResponse(user), getErrorStatus(err)
+	return userToInfoResponse(user), getErrorStatus(skipMailError(err))
 }
 
 func (s Service) ChangePassword(ctx context.Context, request *proto.ChangeUserPasswordRequest) (*proto.UserInfoResponse, error) {
@@ -64,8 +138,66 @@ func (s Service) GetByID(ctx context.Context, request *proto.GetUserByIDRequest)
 	return userToInfoResponse(user), getErrorStatus(err)
 }
 
+func (s Service) SetRole(ctx context.Context, request *proto.SetUserRoleRequest) (*proto.UserInfoResponse, error) {
+	user, err := s.app.SetRole(ctx, request.Id, request.Role)
+	return userToInfoResponse(user), getErrorStatus(err)
+}
+
 func (s Service) Delete(ctx context.Context, request *proto.DeleteUserRequest) (*emptypb.Empty, error) {
-	return new(emptypb.Empty), getErrorStatus(s.app.Delete(ctx, request.Id))
+	return new(emptypb.Empty), getErrorStatus(skipMailError(s.app.Delete(ctx, request.Id)))
+}
+
+func (s Service) RestoreUser(ctx context.Context, request *proto.RestoreUserRequest) (*proto.UserInfoResponse, error) {
+	user, err := s.app.RestoreUser(ctx, request.Token)
+	return userToInfoResponse(user), getErrorStatus(err)
+}
+
+func (s Service) GetUserEvents(ctx context.Context, request *proto.GetUserEventsRequest) (*proto.UserEventsResponse, error) {
+	events, err := s.app.GetUserEvents(ctx, request.AfterId, int(request.Limit))
+	return eventsToResponse(events), getErrorStatus(err)
+}
+
+func (s Service) SendVerification(ctx context.Context, request *proto.GetUserByIDRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.SendVerification(ctx, request.Id))
+}
+
+func (s Service) VerifyEmail(ctx context.Context, request *proto.VerifyEmailRequest) (*proto.UserInfoResponse, error) {
+	user, err := s.app.VerifyEmail(ctx, request.Token)
+	return userToInfoResponse(user), getErrorStatus(err)
+}
+
+func (s Service) RequestPasswordReset(ctx context.Context, request *proto.PasswordResetRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.RequestPasswordReset(ctx, request.Email))
+}
+
+func (s Service) ResetPassword(ctx context.Context, request *proto.ResetPasswordRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.ResetPassword(ctx, request.Token, request.Password))
+}
+
+func (s Service) EnrollTOTP(ctx context.Context, request *proto.GetUserByIDRequest) (*proto.EnrollTOTPResponse, error) {
+	uri, codes, err := s.app.EnrollTOTP(ctx, request.Id)
+	return &proto.EnrollTOTPResponse{Uri: uri, RecoveryCodes: codes}, getErrorStatus(err)
+}
+
+func (s Service) ConfirmTOTP(ctx context.Context, request *proto.TOTPCodeRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.ConfirmTOTP(ctx, request.UserId, request.Code))
+}
+
+func (s Service) DisableTOTP(ctx context.Context, request *proto.TOTPCodeRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.DisableTOTP(ctx, request.UserId, request.Code))
+}
+
+func (s Service) ListSessions(ctx context.Context, request *proto.GetUserByIDRequest) (*proto.SessionsResponse, error) {
+	sessions, err := s.app.ListSessions(ctx, request.Id)
+	return sessionsToResponse(sessions), getErrorStatus(err)
+}
+
+func (s Service) RevokeSession(ctx context.Context, request *proto.UserSessionRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.RevokeSession(ctx, request.UserId, request.SessionId))
+}
+
+func (s Service) RevokeAllOtherSessions(ctx context.Context, request *proto.UserSessionRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), getErrorStatus(s.app.RevokeAllOtherSessions(ctx, request.UserId, request.SessionId))
 }
 
 func NewService(app App) Service {
@@ -1,13 +1,19 @@
 package grpc
 
 import (
+	"encoding/base64"
 	"errors"
 	"goads/internal/auth/app"
 	"goads/internal/auth/proto"
+	"goads/internal/auth/tokens"
 	"goads/internal/auth/users"
 	"goads/internal/pkg/errwrap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"log"
+	"math/big"
 )
 
 func getErrorStatus(err error) error {
@@ -22,31 +28,156 @@ func getErrorStatus(err error) error {
 	if errors.Is(err, app.ErrNotFound) {
 		code = codes.NotFound
 	}
-	if errors.Is(err, app.ErrIncorrectCredentials) || errors.Is(err, app.ErrInvalidToken) {
+	if errors.Is(err, app.ErrIncorrectCredentials) || errors.Is(err, app.ErrInvalidToken) ||
+		errors.Is(err, app.ErrTokenReused) || errors.Is(err, app.ErrInvalidCode) {
 		code = codes.Unauthenticated
 	}
 	if errors.Is(err, app.ErrInvalidContent) || errors.Is(err, app.ErrPasswordToShort) {
 		code = codes.InvalidArgument
 	}
-	if errors.Is(err, app.ErrEmailAlreadyExists) {
+	if errors.Is(err, app.ErrEmailAlreadyExists) || errors.Is(err, app.ErrAlreadyVerified) ||
+		errors.Is(err, app.ErrTwoFactorEnabled) {
 		code = codes.AlreadyExists
 	}
+	if errors.Is(err, app.ErrPermissionDenied) {
+		code = codes.PermissionDenied
+	}
+	if errors.Is(err, app.ErrTooManyAttempts) {
+		code = codes.ResourceExhausted
+	}
+	if errors.Is(err, app.ErrUserDeleted) {
+		code = codes.OutOfRange
+	}
 	if code == codes.Internal {
 		err = errors.New("internal error")
 	}
-	return status.Error(code, err.Error())
+	st := status.New(code, err.Error())
+	var attempts app.TooManyAttemptsError
+	if errors.As(err, &attempts) {
+		detailed, detailsErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(attempts.RetryAfter)})
+		if detailsErr == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// skipMailError logs and skips error of sending email after successful action.
+// Verification email can be requested again by SendVerification
+func skipMailError(err error) error {
+	if errors.Is(err, app.ErrMailNotSent) {
+		log.Printf("cannot send email: %v\n", err)
+		return nil
+	}
+	return err
 }
 
 func userToInfoResponse(user users.User) *proto.UserInfoResponse {
-	return &proto.UserInfoResponse{
-		Id:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
+	res := &proto.UserInfoResponse{
+		Id:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Verified:  user.Verified,
+		Role:      user.Role,
+		TwoFactor: user.TwoFactor,
+	}
+	if !user.DeleteAfter.IsZero() {
+		res.DeleteDate = user.DeleteAfter.UnixMilli()
 	}
+	return res
 }
 
-func tokenToResponse(token string) *proto.TokenResponse {
+func eventsToResponse(events []users.Event) *proto.UserEventsResponse {
+	res := &proto.UserEventsResponse{Events: make([]*proto.UserEvent, len(events))}
+	for i, e := range events {
+		res.Events[i] = &proto.UserEvent{
+			Id:         e.ID,
+			Type:       e.Type,
+			UserId:     e.UserID,
+			CreateDate: e.CreatedAt.UnixMilli(),
+		}
+	}
+	return res
+}
+
+func tokenToResponse(pair tokens.Pair) *proto.TokenResponse {
 	return &proto.TokenResponse{
-		Token: token,
+		Token:          pair.Access,
+		RefreshToken:   pair.Refresh,
+		ChallengeToken: pair.Challenge,
+	}
+}
+
+func publicKeysToResponse(keys []tokens.PublicKey) *proto.PublicKeysResponse {
+	res := make([]*proto.PublicKey, len(keys))
+	for i, k := range keys {
+		res[i] = &proto.PublicKey{
+			Kid: k.ID,
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(k.Key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.Key.E)).Bytes()),
+		}
+	}
+	return &proto.PublicKeysResponse{Keys: res}
+}
+
+func revocationsToResponse(list tokens.Revocations) *proto.RevocationsResponse {
+	res := &proto.RevocationsResponse{
+		Tokens:   make(map[string]int64, len(list.Tokens)),
+		Sessions: make(map[string]int64, len(list.Sessions)),
+		Users:    make(map[int64]int64, len(list.Users)),
+	}
+	for jti, expiresAt := range list.Tokens {
+		res.Tokens[jti] = expiresAt.UnixMilli()
+	}
+	for id, expiresAt := range list.Sessions {
+		res.Sessions[id] = expiresAt.UnixMilli()
+	}
+	for id, before := range list.Users {
+		res.Users[id] = before.UnixMilli()
+	}
+	return res
+}
+
+func claimsToResponse(claims tokens.Claims) *proto.UserIDResponse {
+	return &proto.UserIDResponse{
+		Id:       claims.UserID,
+		Verified: claims.Verified,
+		Role:     claims.Role,
+		Scopes:   claims.Scopes,
+		Session:  claims.Session,
+	}
+}
+
+func apiKeyToResponse(key tokens.APIKey) *proto.APIKey {
+	return &proto.APIKey{
+		Id:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreateDate: key.CreatedAt.UnixMilli(),
+	}
+}
+
+func apiKeysToResponse(keys []tokens.APIKey) *proto.APIKeysResponse {
+	res := make([]*proto.APIKey, len(keys))
+	for i, k := range keys {
+		res[i] = apiKeyToResponse(k)
+	}
+	return &proto.APIKeysResponse{Keys: res}
+}
+
+func sessionsToResponse(sessions []tokens.Session) *proto.SessionsResponse {
+	res := make([]*proto.Session, len(sessions))
+	for i, s := range sessions {
+		res[i] = &proto.Session{
+			Id:           s.ID,
+			Device:       s.Client.Device,
+			Ip:           s.Client.IP,
+			UserAgent:    s.Client.UserAgent,
+			CreateDate:   s.CreatedAt.UnixMilli(),
+			LastUsedDate: s.LastUsedAt.UnixMilli(),
+		}
 	}
+	return &proto.SessionsResponse{Sessions: res}
 }
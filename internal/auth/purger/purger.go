@@ -0,0 +1,50 @@
+package purger
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type Repository interface {
+	// PurgeDeleted deletes users scheduled for deletion before the moment and publishes their events
+	PurgeDeleted(ctx context.Context, moment time.Time) (int64, error)
+}
+
+// Purger deletes users whose grace period is over every interval in background
+type Purger struct {
+	Repo     Repository
+	interval time.Duration
+}
+
+func (p Purger) purge(ctx context.Context) {
+	n, err := p.Repo.PurgeDeleted(ctx, time.Now().UTC())
+	if err != nil {
+		log.Printf("cannot purge deleted users: %v\n", err)
+	} else if n > 0 {
+		log.Printf("%d deleted users have been purged\n", n)
+	}
+}
+
+// Listen purges deleted users until ctx is done
+func (p Purger) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.purge(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+func New(repo Repository, interval time.Duration) Purger {
+	return Purger{
+		Repo:     repo,
+		interval: interval,
+	}
+}
@@ -0,0 +1,31 @@
+package purger
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	calls atomic.Int64
+}
+
+func (r *repo) PurgeDeleted(_ context.Context, moment time.Time) (int64, error) {
+	r.calls.Add(1)
+	return 0, nil
+}
+
+func TestPurger_Listen(t *testing.T) {
+	r := &repo{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- New(r, 10*time.Millisecond).Listen(ctx)
+	}()
+
+	assert.Eventually(t, func() bool { return r.calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,38 @@
+package tokens
+
+import "time"
+
+const (
+	PurposeVerify    = "verify"
+	PurposeReset     = "reset"
+	PurposeTwoFactor = "2fa"
+	PurposeRestore   = "restore"
+)
+
+// Action is a single-use token sent to user's email to confirm an action or returned as a challenge
+// of two-step login. Email binds verification token to the address it has been sent to
+type Action struct {
+	ID        string
+	UserID    int64
+	Email     string
+	Purpose   string
+	ExpiresAt time.Time
+}
+
+func (a Action) IsExpired(moment time.Time) bool {
+	return !moment.Before(a.ExpiresAt)
+}
+
+func NewAction(userID int64, email string, purpose string, expiresAt time.Time) (Action, error) {
+	id, err := random(16)
+	if err != nil {
+		return Action{}, err
+	}
+	return Action{
+		ID:        id,
+		UserID:    userID,
+		Email:     email,
+		Purpose:   purpose,
+		ExpiresAt: expiresAt,
+	}, nil
+}
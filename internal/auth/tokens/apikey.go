@@ -0,0 +1,37 @@
+package tokens
+
+import (
+	"time"
+)
+
+// APIKeyPrefix marks raw API keys, so they can be recognized in logs and secret scanners
+const APIKeyPrefix = "goads_"
+
+// APIKey is a long-lived credential of the user limited by scopes. Only hash of the key is stored,
+// Prefix is its beginning shown to the user to distinguish keys
+type APIKey struct {
+	ID        int64
+	UserID    int64
+	Name      string `validate:"min:1; max:99"`
+	Prefix    string
+	Hash      string
+	Scopes    []string
+	CreatedAt time.Time
+}
+
+// NewAPIKey generates raw API key and its record
+func NewAPIKey(userID int64, name string, scopes []string, createdAt time.Time) (string, APIKey, error) {
+	secret, err := random(32)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	key := APIKeyPrefix + secret
+	return key, APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:len(APIKeyPrefix)+6],
+		Hash:      Hash(key),
+		Scopes:    scopes,
+		CreatedAt: createdAt,
+	}, nil
+}
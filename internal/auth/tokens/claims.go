@@ -0,0 +1,12 @@
+package tokens
+
+// Claims is data of user carried by access token or API key. Scopes limit requests
+// authenticated by API key, they are nil for access tokens. Session is ID of the session
+// which issued access token, it is empty for API keys
+type Claims struct {
+	UserID   int64
+	Verified bool
+	Role     string
+	Scopes   []string
+	Session  string
+}
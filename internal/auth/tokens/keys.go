@@ -0,0 +1,19 @@
+package tokens
+
+import (
+	"crypto/rsa"
+	"time"
+)
+
+// SigningKey is a stored key of the key ring. Private is PEM-encoded PKCS #8 private key
+type SigningKey struct {
+	ID        string
+	Private   []byte
+	CreatedAt time.Time
+}
+
+// PublicKey is a key used to verify tokens signed by the key with the same ID
+type PublicKey struct {
+	ID  string
+	Key *rsa.PublicKey
+}
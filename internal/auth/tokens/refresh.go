@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Refresh is a server-side record of refresh token. Only hash of the token is stored.
+// Tokens issued by rotation from one login share the same Family
+type Refresh struct {
+	ID        int64
+	UserID    int64
+	Family    string
+	Hash      string
+	ExpiresAt time.Time
+	Used      bool
+	Revoked   bool
+}
+
+// Pair is short-lived access token with refresh token used to get the next pair.
+// If the second factor is required, only Challenge is set and it must be exchanged for the pair
+type Pair struct {
+	Access    string
+	Refresh   string
+	Challenge string
+}
+
+// IsExpired returns true if the token cannot be used at the moment
+func (r Refresh) IsExpired(moment time.Time) bool {
+	return !moment.Before(r.ExpiresAt)
+}
+
+// Hash returns hash of raw refresh token or API key as it is stored
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func random(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewRecoveryCode generates a single-use code replacing the second factor, e.g. "k3mzq-7ta2x".
+// Only hash of the code is stored
+func NewRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// NewFamily generates ID of a new tokens family
+func NewFamily() (string, error) {
+	return random(16)
+}
+
+// New generates raw refresh token and its record
+func New(userID int64, family string, expiresAt time.Time) (string, Refresh, error) {
+	token, err := random(32)
+	if err != nil {
+		return "", Refresh{}, err
+	}
+	return token, Refresh{
+		UserID:    userID,
+		Family:    family,
+		Hash:      Hash(token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
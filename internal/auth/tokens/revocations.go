@@ -0,0 +1,23 @@
+package tokens
+
+import "time"
+
+// Revocations is the list of revoked access tokens. Tokens and Sessions hold expiration of revocations
+// by jti and by session ID, Users hold the moment before which all tokens of the user are revoked
+type Revocations struct {
+	Tokens   map[string]time.Time
+	Sessions map[string]time.Time
+	Users    map[int64]time.Time
+}
+
+// IsRevoked returns true if the token was revoked by its jti, by its session or as one of the user's tokens
+func (r Revocations) IsRevoked(jti string, session string, userID int64, issuedAt time.Time) bool {
+	if _, ok := r.Tokens[jti]; ok {
+		return true
+	}
+	if _, ok := r.Sessions[session]; ok && session != "" {
+		return true
+	}
+	before, ok := r.Users[userID]
+	return ok && issuedAt.Before(before)
+}
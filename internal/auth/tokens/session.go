@@ -0,0 +1,37 @@
+package tokens
+
+import "time"
+
+// Client describes where the user logs in from. Device is a name given by the client, e.g. "Work laptop"
+type Client struct {
+	Device    string
+	IP        string
+	UserAgent string
+}
+
+// Session is a login of the user on a client. It is identified by the family of its refresh tokens
+// and access tokens issued by the session carry its ID
+type Session struct {
+	ID         string
+	UserID     int64
+	Client     Client
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// NewSession starts a new session of the user with a new family of refresh tokens
+func NewSession(userID int64, client Client, createdAt time.Time, expiresAt time.Time) (Session, error) {
+	family, err := NewFamily()
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{
+		ID:         family,
+		UserID:     userID,
+		Client:     client,
+		CreatedAt:  createdAt,
+		LastUsedAt: createdAt,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
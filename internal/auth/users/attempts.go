@@ -0,0 +1,12 @@
+package users
+
+import "time"
+
+// LoginFailure is an audit entry of failed login. UserID is 0 if there is no user with the email
+type LoginFailure struct {
+	UserID    int64
+	Email     string
+	IP        string
+	Reason    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,14 @@
+package users
+
+import "time"
+
+// EventDeleted is published after the user has been purged, so other services can remove its data
+const EventDeleted = "user.deleted"
+
+// Event is a change of the user published to other services. IDs of events are increasing
+type Event struct {
+	ID        int64
+	Type      string
+	UserID    int64
+	CreatedAt time.Time
+}
@@ -0,0 +1,10 @@
+package users
+
+// TOTP is the second factor of the user. It is not enabled until the user confirms it by a valid code.
+// LastStep is a time step of the last accepted code, codes of the same or earlier steps are rejected
+type TOTP struct {
+	UserID   int64
+	Secret   string
+	Enabled  bool
+	LastStep int64
+}
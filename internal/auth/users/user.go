@@ -1,12 +1,23 @@
 package users
 
-import "fmt"
+import (
+	"fmt"
+	"goads/internal/pkg/permissions"
+	"time"
+)
 
 type User struct {
 	ID       int64
 	Email    string `validate:"min:3; max:320"`
 	Name     string `validate:"min:2; max:99"`
 	Password string
+	Verified bool
+	Role     string
+	// TwoFactor is true if the user has enabled TOTP, it is not changed by Update
+	TwoFactor bool
+	// DeleteAfter is the moment after which the user scheduled for deletion is purged. It is zero for active
+	// users and it is not changed by Update
+	DeleteAfter time.Time
 }
 
 func (u User) String() string {
@@ -23,5 +34,6 @@ func New(email string, name string, password string) User {
 		Email:    email,
 		Name:     name,
 		Password: password,
+		Role:     permissions.RoleUser,
 	}
 }
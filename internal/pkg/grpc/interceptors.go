@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"goads/internal/pkg/permissions"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -30,5 +31,5 @@ func RecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
 }
 
 func GetUnaryInterceptors() grpc.ServerOption {
-	return grpc.ChainUnaryInterceptor(LogUnaryInterceptor, RecoveryUnaryInterceptor())
+	return grpc.ChainUnaryInterceptor(LogUnaryInterceptor, RecoveryUnaryInterceptor(), permissions.UnaryServerInterceptor)
 }
@@ -0,0 +1,100 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sort keys of lists. Key prefixed by "-" means descending order
+const (
+	SortCreated   = "created"
+	SortUpdated   = "updated"
+	SortTitle     = "title"
+	SortAlias     = "alias"
+	SortRelevance = "relevance"
+	SortVersion   = "version"
+)
+
+const (
+	DefaultSize = 50
+	MaxSize     = 500
+)
+
+var ErrInvalidPage = errors.New("invalid page")
+
+// Page is a request of a part of the list. Token is returned with the previous page and continues it,
+// Sort is a sort key optionally prefixed by "-" for descending order. Total requests the number of all items
+type Page struct {
+	Size  int
+	Token string
+	Sort  string
+	Total bool
+}
+
+// Info describes the returned page. NextToken is empty on the last page, Total is set only if it is requested
+type Info struct {
+	NextToken string
+	Total     int64
+}
+
+// Cursor is the position after the last item of the page. Key is the sort key of the item as SQL text,
+// so it can be cast back to the type of the key
+type Cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"i"`
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Normalize sets default size and sort, which is the first of allowed sorts, and checks the page
+func (p Page) Normalize(sorts ...string) (Page, error) {
+	if p.Size <= 0 {
+		p.Size = DefaultSize
+	}
+	if p.Size > MaxSize {
+		p.Size = MaxSize
+	}
+	if p.Sort == "" && len(sorts) > 0 {
+		p.Sort = sorts[0]
+	}
+	key, _ := p.Key()
+	for _, s := range sorts {
+		if strings.TrimPrefix(s, "-") == key {
+			_, err := p.Cursor()
+			return p, err
+		}
+	}
+	return p, fmt.Errorf("%w: unknown sort %q", ErrInvalidPage, p.Sort)
+}
+
+// Key returns sort key without direction and whether the order is descending
+func (p Page) Key() (string, bool) {
+	key, desc := strings.CutPrefix(p.Sort, "-")
+	return key, desc
+}
+
+// Cursor decodes the token. Token of the first page is empty and its cursor is nil
+func (p Page) Cursor() (*Cursor, error) {
+	if p.Token == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(p.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPage, err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPage, err)
+	}
+	if c.Sort != p.Sort {
+		return nil, fmt.Errorf("%w: token of sort %q is used with %q", ErrInvalidPage, c.Sort, p.Sort)
+	}
+	return &c, nil
+}
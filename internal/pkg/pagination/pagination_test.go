@@ -0,0 +1,57 @@
+package pagination
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPage_Normalize(t *testing.T) {
+	sorts := []string{"-" + SortCreated, SortUpdated, SortTitle}
+
+	p, err := Page{}.Normalize(sorts...)
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Size: DefaultSize, Sort: "-created"}, p)
+
+	p, err = Page{Size: MaxSize + 1, Sort: "-title"}.Normalize(sorts...)
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Size: MaxSize, Sort: "-title"}, p, "direction of allowed sort can be changed")
+
+	_, err = Page{Sort: "alias"}.Normalize(sorts...)
+	assert.ErrorIs(t, err, ErrInvalidPage)
+
+	_, err = Page{Sort: "title", Token: "broken!"}.Normalize(sorts...)
+	assert.ErrorIs(t, err, ErrInvalidPage)
+}
+
+func TestPage_Cursor(t *testing.T) {
+	cursor := Cursor{Sort: "-created", Key: "2023-01-02 03:04:05.123456", ID: 7}
+
+	got, err := Page{Sort: "-created", Token: cursor.Encode()}.Cursor()
+	assert.NoError(t, err)
+	assert.Equal(t, &cursor, got)
+
+	_, err = Page{Sort: "created", Token: cursor.Encode()}.Cursor()
+	assert.ErrorIs(t, err, ErrInvalidPage, "token must not be used with another sort")
+
+	got, err = Page{Sort: "created"}.Cursor()
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestKeyset(t *testing.T) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + string(rune('0'+len(args)))
+	}
+
+	where, order := Keyset("create_date", "timestamp", "id", true, nil, arg)
+	assert.Empty(t, where)
+	assert.Equal(t, "create_date DESC, id DESC", order)
+	assert.Empty(t, args)
+
+	where, order = Keyset("title", "text", "id", false, &Cursor{Key: "bike", ID: 3}, arg)
+	assert.Equal(t, "(title, id) > ($1::text, $2)", where)
+	assert.Equal(t, "title ASC, id ASC", order)
+	assert.Equal(t, []any{"bike", int64(3)}, args)
+}
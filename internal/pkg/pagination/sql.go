@@ -0,0 +1,18 @@
+package pagination
+
+import "fmt"
+
+// Keyset returns SQL condition selecting items after the cursor and ORDER BY clause for items ordered by
+// the key expression with the id column as tie-breaker. keyType is SQL type which the cursor key is cast to,
+// arg adds the argument of the query and returns its placeholder. The condition is empty for the first page
+func Keyset(key string, keyType string, id string, desc bool, cursor *Cursor, arg func(any) string) (string, string) {
+	op, dir := ">", "ASC"
+	if desc {
+		op, dir = "<", "DESC"
+	}
+	order := fmt.Sprintf("%s %s, %s %s", key, dir, id, dir)
+	if cursor == nil {
+		return "", order
+	}
+	return fmt.Sprintf("(%s, %s) %s (%s::%s, %s)", key, id, op, arg(cursor.Key), keyType, arg(cursor.ID)), order
+}
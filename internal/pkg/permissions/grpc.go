@@ -0,0 +1,32 @@
+package permissions
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor puts role from metadata of incoming request to the context
+func UnaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if roles := md.Get(MetadataKey); len(roles) > 0 {
+			ctx = WithRole(ctx, roles[0])
+		}
+	}
+	return handler(ctx, req)
+}
+
+// UnaryClientInterceptor passes role from the context to metadata of outgoing request
+func UnaryClientInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if role, ok := ctx.Value(roleKey{}).(string); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, role)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
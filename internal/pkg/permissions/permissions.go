@@ -0,0 +1,67 @@
+package permissions
+
+import (
+	"context"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission allows a role to do actions with objects created by other users
+type Permission int
+
+const (
+	EditAny Permission = iota
+	DeleteAny
+	UnpublishAny
+	ManageUsers
+	// ModerateAds allows to review pending ads. Unlike other permissions it is not given to authors of ads
+	ModerateAds
+)
+
+var grants = map[string][]Permission{
+	RoleUser:      {},
+	RoleModerator: {UnpublishAny, ModerateAds},
+	RoleAdmin:     {EditAny, DeleteAny, UnpublishAny, ManageUsers, ModerateAds},
+}
+
+// MetadataKey is a key of gRPC metadata with role of the user who makes a request
+const MetadataKey = "x-user-role"
+
+type roleKey struct{}
+
+func IsValid(role string) bool {
+	_, ok := grants[role]
+	return ok
+}
+
+// Has returns true if the role has the permission
+func Has(role string, permission Permission) bool {
+	for _, p := range grants[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// FromContext returns role of the user who makes a request. It is RoleUser if the role is not set
+func FromContext(ctx context.Context) string {
+	role, ok := ctx.Value(roleKey{}).(string)
+	if !ok || !IsValid(role) {
+		return RoleUser
+	}
+	return role
+}
+
+// Allowed returns true if the user is the author of an object or the user's role has the permission
+func Allowed(ctx context.Context, userID int64, authorID int64, permission Permission) bool {
+	return userID == authorID || Has(FromContext(ctx), permission)
+}
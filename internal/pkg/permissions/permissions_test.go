@@ -0,0 +1,57 @@
+package permissions
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"testing"
+)
+
+func TestHas(t *testing.T) {
+	assert.False(t, Has(RoleUser, EditAny))
+	assert.True(t, Has(RoleModerator, UnpublishAny))
+	assert.False(t, Has(RoleModerator, DeleteAny))
+	assert.True(t, Has(RoleModerator, ModerateAds))
+	assert.False(t, Has(RoleUser, ModerateAds))
+	assert.True(t, Has(RoleAdmin, ManageUsers))
+	assert.False(t, Has("root", EditAny))
+}
+
+func TestAllowed(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, Allowed(ctx, 1, 1, EditAny), "author is always allowed")
+	assert.False(t, Allowed(ctx, 2, 1, EditAny), "role is user by default")
+	assert.False(t, Allowed(WithRole(ctx, "root"), 2, 1, EditAny), "unknown role is user")
+	assert.True(t, Allowed(WithRole(ctx, RoleAdmin), 2, 1, EditAny))
+}
+
+func TestInterceptors(t *testing.T) {
+	ctx := WithRole(context.Background(), RoleModerator)
+	var outgoing metadata.MD
+	err := UnaryClientInterceptor(ctx, "/test", nil, nil, nil,
+		func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{RoleModerator}, outgoing.Get(MetadataKey))
+
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	role, err := UnaryServerInterceptor(incoming, nil, nil, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return FromContext(ctx), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, RoleModerator, role)
+}
+
+func TestHasScope(t *testing.T) {
+	granted := []string{ScopeLinksWrite, ScopeAdsRead}
+	assert.True(t, HasScope(granted, ResourceLinks, http.MethodPost))
+	assert.True(t, HasScope(granted, ResourceLinks, http.MethodGet), "write scope allows reading")
+	assert.True(t, HasScope(granted, ResourceAds, http.MethodGet))
+	assert.False(t, HasScope(granted, ResourceAds, http.MethodDelete))
+	assert.False(t, HasScope(nil, ResourceLinks, http.MethodGet))
+}
@@ -0,0 +1,52 @@
+package permissions
+
+import (
+	"net/http"
+)
+
+// Resources which can be accessed by API keys
+const (
+	ResourceLinks = "links"
+	ResourceAds   = "ads"
+)
+
+// Scopes of API keys. Read scope allows only safe methods, write scope allows any method
+const (
+	ScopeLinksRead  = ResourceLinks + ":read"
+	ScopeLinksWrite = ResourceLinks + ":write"
+	ScopeAdsRead    = ResourceAds + ":read"
+	ScopeAdsWrite   = ResourceAds + ":write"
+)
+
+var scopes = map[string]struct{}{
+	ScopeLinksRead:  {},
+	ScopeLinksWrite: {},
+	ScopeAdsRead:    {},
+	ScopeAdsWrite:   {},
+}
+
+func IsValidScope(scope string) bool {
+	_, ok := scopes[scope]
+	return ok
+}
+
+// RequiredScope returns scope needed to make a request with the method to the resource
+func RequiredScope(resource string, method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return resource + ":read"
+	}
+	return resource + ":write"
+}
+
+// HasScope returns true if granted scopes allow a request with the method to the resource.
+// Write scope of the resource also allows reading
+func HasScope(granted []string, resource string, method string) bool {
+	required := RequiredScope(resource, method)
+	for _, s := range granted {
+		if s == required || s == resource+":write" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package textdiff
+
+import (
+	"unicode"
+)
+
+// Operations of the diff's parts
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// maxCells limits the size of the table of the longest common subsequence. Larger texts are diffed as a whole
+const maxCells = 1 << 20
+
+// Part is a fragment of texts which is equal in both texts, inserted into the new one or deleted from the old one
+type Part struct {
+	Op   string
+	Text string
+}
+
+// Words returns the word-level diff between texts before and after the change. Joined texts of Equal and Delete
+// parts give the text before, joined texts of Equal and Insert parts give the text after
+func Words(before, after string) []Part {
+	a, b := tokenize(before), tokenize(after)
+	if len(a)*len(b) > maxCells {
+		var res []Part
+		res = appendPart(res, Delete, before)
+		return appendPart(res, Insert, after)
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var res []Part
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			res = appendPart(res, Equal, a[i])
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			res = appendPart(res, Delete, a[i])
+			i++
+		default:
+			res = appendPart(res, Insert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		res = appendPart(res, Delete, a[i])
+	}
+	for ; j < len(b); j++ {
+		res = appendPart(res, Insert, b[j])
+	}
+	return res
+}
+
+// appendPart appends the text to the last part if it has the same operation. Empty texts are skipped
+func appendPart(parts []Part, op string, text string) []Part {
+	if text == "" {
+		return parts
+	}
+	if n := len(parts); n > 0 && parts[n-1].Op == op {
+		parts[n-1].Text += text
+		return parts
+	}
+	return append(parts, Part{Op: op, Text: text})
+}
+
+// tokenize splits the text into words, runs of spaces and single punctuation marks
+func tokenize(text string) []string {
+	var tokens []string
+	start := 0
+	kind := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 3
+	}
+	prev := 0
+	for i, r := range text {
+		k := kind(r)
+		if i > start && (k != prev || k == 3) {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		prev = k
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
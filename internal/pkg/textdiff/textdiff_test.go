@@ -0,0 +1,39 @@
+package textdiff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func join(parts []Part, skip string) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		if p.Op != skip {
+			sb.WriteString(p.Text)
+		}
+	}
+	return sb.String()
+}
+
+func TestWords(t *testing.T) {
+	before, after := "Red bicycle for sale, cheap!", "Blue bicycle for sale, very cheap!"
+	parts := Words(before, after)
+	assert.Equal(t, []Part{
+		{Op: Delete, Text: "Red"},
+		{Op: Insert, Text: "Blue"},
+		{Op: Equal, Text: " bicycle for sale, "},
+		{Op: Insert, Text: "very "},
+		{Op: Equal, Text: "cheap!"},
+	}, parts)
+	assert.Equal(t, before, join(parts, Insert))
+	assert.Equal(t, after, join(parts, Delete))
+
+	assert.Equal(t, []Part{{Op: Insert, Text: "new"}}, Words("", "new"))
+	assert.Empty(t, Words("", ""))
+}
+
+func TestWords_Large(t *testing.T) {
+	before, after := strings.Repeat("a ", 1100), strings.Repeat("b ", 1100)
+	assert.Equal(t, []Part{{Op: Delete, Text: before}, {Op: Insert, Text: after}}, Words(before, after))
+}
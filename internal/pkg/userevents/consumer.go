@@ -0,0 +1,114 @@
+package userevents
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// UserDeleted is published by Auth after the user has been purged
+const UserDeleted = "user.deleted"
+
+// Event is a change of the user published by Auth. IDs of events are increasing
+type Event struct {
+	ID        int64
+	Type      string
+	UserID    int64
+	CreatedAt time.Time
+}
+
+// Source returns events with ID greater than after in ascending order
+type Source interface {
+	Events(ctx context.Context, after int64, limit int) ([]Event, error)
+}
+
+// Cursor stores ID of the last handled event for each consumer
+type Cursor interface {
+	GetEventCursor(ctx context.Context, consumer string) (int64, error)
+	SetEventCursor(ctx context.Context, consumer string, id int64) error
+}
+
+// Handler removes data of the deleted user owned by the service. Events are delivered at least once,
+// so the handler must be idempotent
+type Handler interface {
+	DeleteUserData(ctx context.Context, userID int64) error
+}
+
+// Consumer polls events every interval in background and moves its cursor after each handled event
+type Consumer struct {
+	Source   Source
+	Cursor   Cursor
+	Handler  Handler
+	name     string
+	interval time.Duration
+	batch    int
+}
+
+// consume handles the next batch of events and returns their number
+func (c Consumer) consume(ctx context.Context) (int, error) {
+	after, err := c.Cursor.GetEventCursor(ctx, c.name)
+	if err != nil {
+		return 0, err
+	}
+	events, err := c.Source.Events(ctx, after, c.batch)
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		if event.Type == UserDeleted {
+			if err := c.Handler.DeleteUserData(ctx, event.UserID); err != nil {
+				return i, fmt.Errorf("event %d: %w", event.ID, err)
+			}
+		}
+		if err := c.Cursor.SetEventCursor(ctx, c.name, event.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// poll consumes batches until all published events are handled
+func (c Consumer) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := c.consume(ctx)
+		if err != nil {
+			log.Printf("cannot consume user events by %s: %v\n", c.name, err)
+			return
+		}
+		if n > 0 {
+			log.Printf("%d user events have been consumed by %s\n", n, c.name)
+		}
+		if n < c.batch {
+			return
+		}
+	}
+}
+
+// Listen consumes events until ctx is done
+func (c Consumer) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			c.poll(ctx)
+		}
+	}
+}
+
+// New creates consumer. The name identifies its cursor, so it must be unique among services
+func New(name string, source Source, cursor Cursor, handler Handler, interval time.Duration, batch int) Consumer {
+	return Consumer{
+		Source:   source,
+		Cursor:   cursor,
+		Handler:  handler,
+		name:     name,
+		interval: interval,
+		batch:    batch,
+	}
+}
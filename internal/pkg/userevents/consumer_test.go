@@ -0,0 +1,119 @@
+package userevents
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type source struct {
+	events []Event
+}
+
+func (s *source) Events(_ context.Context, after int64, limit int) ([]Event, error) {
+	res := make([]Event, 0)
+	for _, e := range s.events {
+		if e.ID > after && len(res) < limit {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+type cursor struct {
+	mu  sync.Mutex
+	ids map[string]int64
+}
+
+func (c *cursor) GetEventCursor(_ context.Context, consumer string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ids[consumer], nil
+}
+
+func (c *cursor) SetEventCursor(_ context.Context, consumer string, id int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[consumer] = id
+	return nil
+}
+
+func (c *cursor) get(consumer string) int64 {
+	id, _ := c.GetEventCursor(context.Background(), consumer)
+	return id
+}
+
+type handler struct {
+	mu      sync.Mutex
+	deleted []int64
+	failOn  int64
+}
+
+func (h *handler) DeleteUserData(_ context.Context, userID int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if userID == h.failOn {
+		return errors.New("cannot delete")
+	}
+	h.deleted = append(h.deleted, userID)
+	return nil
+}
+
+func events() *source {
+	return &source{events: []Event{
+		{ID: 1, Type: UserDeleted, UserID: 10},
+		{ID: 2, Type: "user.unknown", UserID: 20},
+		{ID: 4, Type: UserDeleted, UserID: 30},
+		{ID: 5, Type: UserDeleted, UserID: 40},
+	}}
+}
+
+func TestConsumer_consume(t *testing.T) {
+	c, h := &cursor{ids: map[string]int64{}}, &handler{}
+	consumer := New("ads", events(), c, h, time.Minute, 3)
+
+	n, err := consumer.consume(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{10, 30}, h.deleted)
+	assert.Equal(t, int64(4), c.get("ads"))
+
+	n, err = consumer.consume(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{10, 30, 40}, h.deleted)
+	assert.Equal(t, int64(5), c.get("ads"))
+	assert.Equal(t, int64(0), c.get("links"))
+}
+
+func TestConsumer_consume_Failed(t *testing.T) {
+	c, h := &cursor{ids: map[string]int64{}}, &handler{failOn: 30}
+	consumer := New("ads", events(), c, h, time.Minute, 10)
+
+	n, err := consumer.consume(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, int64(2), c.get("ads"), "cursor must stop before the failed event")
+
+	h.failOn = 0
+	_, err = consumer.consume(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{10, 30, 40}, h.deleted)
+	assert.Equal(t, int64(5), c.get("ads"))
+}
+
+func TestConsumer_Listen(t *testing.T) {
+	c, h := &cursor{ids: map[string]int64{}}, &handler{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- New("ads", events(), c, h, 10*time.Millisecond, 2).Listen(ctx)
+	}()
+
+	assert.Eventually(t, func() bool { return c.get("ads") == 5 }, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
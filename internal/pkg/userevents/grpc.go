@@ -0,0 +1,33 @@
+package userevents
+
+import (
+	"context"
+	"goads/internal/auth/proto"
+	"time"
+)
+
+// AuthSource reads events from Auth service
+type AuthSource struct {
+	Svc proto.AuthServiceClient
+}
+
+func (s AuthSource) Events(ctx context.Context, after int64, limit int) ([]Event, error) {
+	res, err := s.Svc.GetUserEvents(ctx, &proto.GetUserEventsRequest{AfterId: after, Limit: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, len(res.Events))
+	for i, e := range res.Events {
+		events[i] = Event{
+			ID:        e.Id,
+			Type:      e.Type,
+			UserID:    e.UserId,
+			CreatedAt: time.UnixMilli(e.CreateDate).UTC(),
+		}
+	}
+	return events, nil
+}
+
+func NewAuthSource(svc proto.AuthServiceClient) AuthSource {
+	return AuthSource{Svc: svc}
+}
@@ -13,17 +13,27 @@ type Client struct {
 	Svc proto.AdServiceClient
 }
 
-func (c Client) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error) {
+func (c Client) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, []int64, error) {
 	const op = "ads.GetOnlyPublished"
 	adsList, err := c.Svc.GetOnlyPublished(ctx, &proto.AdIDsRequest{Id: ids})
 	if err != nil {
-		return nil, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("ad ids: %v", ids))
+		return nil, nil, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("ad ids: %v", ids))
 	}
 	res := make([]ads.Ad, len(adsList.List))
 	for i := range res {
 		res[i] = ads.New(adsList.List[i].Title, adsList.List[i].Text)
+		res[i].ID = adsList.List[i].Id
 	}
-	return res, nil
+	return res, adsList.Missing, nil
+}
+
+func (c Client) CountImpression(ctx context.Context, adID int64) error {
+	const op = "ads.CountImpression"
+	_, err := c.Svc.CountImpression(ctx, &proto.CountImpressionRequest{AdId: adID})
+	if err != nil {
+		return errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("ad id: %d", adID))
+	}
+	return nil
 }
 
 func New(svc proto.AdServiceClient) Client {
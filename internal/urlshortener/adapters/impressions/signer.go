@@ -0,0 +1,113 @@
+package impressions
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/urlshortener/app"
+	"goads/internal/urlshortener/entities/redirects"
+	"strings"
+	"time"
+)
+
+// payload is signed content of impression token
+type payload struct {
+	ID      string `json:"iid"`
+	LinkID  int64  `json:"lid"`
+	AdID    int64  `json:"aid"`
+	Visitor string `json:"vis"`
+	Expires int64  `json:"exp"`
+}
+
+// Signer issues impression tokens signed by HMAC-SHA256. Token is base64url-encoded payload and its signature
+// separated by dot. Tokens are bound to IP address of the visitor, which is stored in the token only as its hash
+type Signer struct {
+	secret  []byte
+	expires time.Duration
+}
+
+func (s Signer) mac(data string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// hashIP returns hash of the IP address, so the token can be checked without storing the address itself
+func (s Signer) hashIP(ip string) string {
+	return hex.EncodeToString(s.mac("ip:" + ip))
+}
+
+func (s Signer) sign(data string) string {
+	return base64.RawURLEncoding.EncodeToString(s.mac(data))
+}
+
+// Issue returns token of the impression of the ad shown on redirect of the link to the visitor with the IP address
+func (s Signer) Issue(ctx context.Context, linkID int64, adID int64, ip string) (string, error) {
+	const op = "impressions.Issue"
+	if ctx.Err() != nil {
+		return "", errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+
+	impression, err := redirects.NewImpression(linkID, adID, s.hashIP(ip), time.Now().UTC().Add(s.expires))
+	if err != nil {
+		return "", errwrap.New(err, app.ServiceName, op).OnObject("link", linkID)
+	}
+	data, err := json.Marshal(payload{
+		ID:      impression.ID,
+		LinkID:  impression.LinkID,
+		AdID:    impression.AdID,
+		Visitor: impression.Visitor,
+		Expires: impression.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", errwrap.New(err, app.ServiceName, op).OnObject("link", linkID)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + s.sign(encoded), nil
+}
+
+// Parse checks signature of the token and that it is issued to the visitor with the IP address.
+// Expiration is not checked
+func (s Signer) Parse(ctx context.Context, token string, ip string) (redirects.Impression, error) {
+	const op = "impressions.Parse"
+	if ctx.Err() != nil {
+		return redirects.Impression{}, errwrap.New(ctx.Err(), app.ServiceName, op)
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return redirects.Impression{}, errwrap.New(app.ErrInvalidImpression, app.ServiceName, op).
+			WithDetails("wrong signature")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return redirects.Impression{}, errwrap.New(app.ErrInvalidImpression, app.ServiceName, op).
+			WithDetails(err.Error())
+	}
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return redirects.Impression{}, errwrap.New(app.ErrInvalidImpression, app.ServiceName, op).
+			WithDetails(err.Error())
+	}
+	if !hmac.Equal([]byte(p.Visitor), []byte(s.hashIP(ip))) {
+		return redirects.Impression{}, errwrap.New(app.ErrInvalidImpression, app.ServiceName, op).
+			OnObject("link", p.LinkID).
+			WithDetails("issued to other visitor")
+	}
+	return redirects.Impression{
+		ID:        p.ID,
+		LinkID:    p.LinkID,
+		AdID:      p.AdID,
+		Visitor:   p.Visitor,
+		ExpiresAt: time.Unix(p.Expires, 0).UTC(),
+	}, nil
+}
+
+// New creates the signer of tokens valid for expires
+func New(secret []byte, expires time.Duration) Signer {
+	return Signer{secret: secret, expires: expires}
+}
@@ -0,0 +1,39 @@
+package impressions
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/urlshortener/app"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	ctx := context.Background()
+	s := New([]byte("secret"), time.Hour)
+
+	token, err := s.Issue(ctx, 1, 2, "127.0.0.1")
+	require.NoError(t, err)
+	got, err := s.Parse(ctx, token, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.LinkID)
+	assert.Equal(t, int64(2), got.AdID)
+	assert.NotEmpty(t, got.ID)
+	assert.NotContains(t, got.Visitor, "127.0.0.1")
+	assert.False(t, got.IsExpired(time.Now()))
+	assert.True(t, got.IsExpired(time.Now().Add(2*time.Hour)))
+
+	other, err := s.Issue(ctx, 1, 2, "127.0.0.1")
+	require.NoError(t, err)
+	otherGot, err := s.Parse(ctx, other, "127.0.0.1")
+	require.NoError(t, err)
+	assert.NotEqual(t, got.ID, otherGot.ID, "every impression must have its own ID")
+
+	_, err = s.Parse(ctx, token, "10.0.0.1")
+	assert.ErrorIs(t, err, app.ErrInvalidImpression, "token of other visitor must be rejected")
+	_, err = New([]byte("other"), time.Hour).Parse(ctx, token, "127.0.0.1")
+	assert.ErrorIs(t, err, app.ErrInvalidImpression, "token signed by other secret must be rejected")
+	_, err = s.Parse(ctx, "e30."+token[len(token)-43:], "127.0.0.1")
+	assert.ErrorIs(t, err, app.ErrInvalidImpression, "changed payload must be rejected")
+}
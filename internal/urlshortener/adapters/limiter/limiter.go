@@ -0,0 +1,64 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type window struct {
+	start    time.Time
+	failures int
+}
+
+// Limiter is in-memory limiter of failed attempts. Each key is allowed to fail maxFailures
+// times during the period, after that attempts are rejected until the period ends
+type Limiter struct {
+	mu          *sync.Mutex
+	windows     map[string]window
+	maxFailures int
+	period      time.Duration
+}
+
+func (l Limiter) current(key string, now time.Time) window {
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.period {
+		return window{start: now}
+	}
+	return w
+}
+
+func (l Limiter) Allowed(_ context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current(key, time.Now()).failures < l.maxFailures, nil
+}
+
+func (l Limiter) Failed(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	w := l.current(key, now)
+	w.failures++
+	l.windows[key] = w
+	l.collect(now)
+	return nil
+}
+
+// collect removes outdated windows to prevent unbounded growth of the map
+func (l Limiter) collect(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.period {
+			delete(l.windows, key)
+		}
+	}
+}
+
+func New(maxFailures int, period time.Duration) Limiter {
+	return Limiter{
+		mu:          &sync.Mutex{},
+		windows:     make(map[string]window),
+		maxFailures: maxFailures,
+		period:      period,
+	}
+}
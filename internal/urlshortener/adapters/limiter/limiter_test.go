@@ -0,0 +1,29 @@
+package limiter
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := New(2, 50*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		allowed, err := l.Allowed(ctx, "alias")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		require.NoError(t, l.Failed(ctx, "alias"))
+	}
+	allowed, _ := l.Allowed(ctx, "alias")
+	assert.False(t, allowed)
+	allowed, _ = l.Allowed(ctx, "other")
+	assert.True(t, allowed, "keys must be limited independently")
+
+	time.Sleep(50 * time.Millisecond)
+	allowed, _ = l.Allowed(ctx, "alias")
+	assert.True(t, allowed, "limit must be reset after the period")
+}
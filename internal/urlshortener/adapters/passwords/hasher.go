@@ -0,0 +1,41 @@
+package passwords
+
+import (
+	"context"
+	"errors"
+	"goads/internal/auth/adapters/bcrypt"
+	authApp "goads/internal/auth/app"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/urlshortener/app"
+)
+
+// Hasher hashes link passwords with the same bcrypt adapter as Auth and converts its errors
+type Hasher struct {
+	bcrypt bcrypt.BCrypt
+}
+
+func (h Hasher) Generate(ctx context.Context, password string) (string, error) {
+	const op = "passwords.Generate"
+	hash, err := h.bcrypt.Generate(ctx, password)
+	if errors.Is(err, authApp.ErrPasswordToShort) {
+		err = errwrap.New(app.ErrInvalidContent, app.ServiceName, op).WithDetails(err.Error())
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return hash, err
+}
+
+func (h Hasher) Compare(ctx context.Context, hash string, password string) error {
+	const op = "passwords.Compare"
+	err := h.bcrypt.Compare(ctx, hash, password)
+	if errors.Is(err, authApp.ErrIncorrectCredentials) {
+		err = errwrap.New(app.ErrWrongPassword, app.ServiceName, op).WithDetails(err.Error())
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op)
+	}
+	return err
+}
+
+func New(cost int) Hasher {
+	return Hasher{bcrypt.New(cost)}
+}
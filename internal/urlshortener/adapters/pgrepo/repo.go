@@ -7,14 +7,18 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/pagination"
 	"goads/internal/urlshortener/app"
+	"goads/internal/urlshortener/entities/clicks"
 	"goads/internal/urlshortener/entities/links"
 	"sort"
+	"time"
 )
 
 type Repo struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
 const (
@@ -38,12 +42,20 @@ func (r Repo) SizeApprox(ctx context.Context) (int64, error) {
 }
 
 func (r Repo) Store(ctx context.Context, link links.Link) (id int64, err error) {
-	const linksQuery = `INSERT INTO links (alias, url, author_id) VALUES ($1, $2, $3) RETURNING id`
-	const adsQuery = `INSERT INTO link_ads (link_id, ad_id) VALUES ($1, $2)`
+	const linksQuery = `
+		INSERT INTO links (alias, url, author_id, ad_delay, active_from, expires_at, password, strategy,
+		                   created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id
+	`
+	const adsQuery = `INSERT INTO link_ads (link_id, ad_id, weight) VALUES ($1, $2, $3)`
 	const op = "pgrepo.Store"
 
 	id = -1
-	err = r.db.QueryRow(ctx, linksQuery, link.Alias, link.URL, link.AuthorID).Scan(&id)
+	err = r.db.QueryRow(
+		ctx, linksQuery,
+		link.Alias, link.URL, link.AuthorID, link.AdDelay, nullTime(link.ActiveFrom), nullTime(link.ExpiresAt),
+		link.PasswordHash, link.Strategy, link.CreateDate, link.UpdateDate,
+	).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.ConstraintName == constrAlias {
@@ -60,7 +72,7 @@ func (r Repo) Store(ctx context.Context, link links.Link) (id int64, err error)
 
 	batchAds := &pgx.Batch{}
 	for _, adID := range link.Ads {
-		batchAds.Queue(adsQuery, id, adID)
+		batchAds.Queue(adsQuery, id, adID, link.AdWeight(adID))
 	}
 	br := r.db.SendBatch(ctx, batchAds)
 	defer func() {
@@ -82,6 +94,42 @@ func (r Repo) Store(ctx context.Context, link links.Link) (id int64, err error)
 	return
 }
 
+// schedule is nullable time columns of a link
+type schedule struct {
+	activeFrom pgtype.Timestamp
+	expiresAt  pgtype.Timestamp
+	archivedAt pgtype.Timestamp
+}
+
+func (s schedule) apply(link *links.Link) {
+	link.ActiveFrom = s.activeFrom.Time
+	link.ExpiresAt = s.expiresAt.Time
+	link.ArchivedAt = s.archivedAt.Time
+}
+
+// nullTime converts zero time to NULL
+func nullTime(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: !t.IsZero()}
+}
+
+// adWeights builds weights of ads by aggregated arrays of ads and their weights in the same order
+func adWeights(ads pgtype.Array[pgtype.Int8], weights pgtype.Array[pgtype.Int4]) map[int64]int {
+	if !ads.Valid || !weights.Valid || len(ads.Elements) != len(weights.Elements) {
+		return nil
+	}
+	var res map[int64]int
+	for i := range ads.Elements {
+		if !ads.Elements[i].Valid || !weights.Elements[i].Valid {
+			continue
+		}
+		if res == nil {
+			res = make(map[int64]int, len(ads.Elements))
+		}
+		res[ads.Elements[i].Int64] = int(weights.Elements[i].Int32)
+	}
+	return res
+}
+
 func pgArrayToGoSlice(arr pgtype.Array[pgtype.Int8]) []int64 {
 	if !arr.Valid {
 		return nil
@@ -100,7 +148,8 @@ func pgArrayToGoSlice(arr pgtype.Array[pgtype.Int8]) []int64 {
 
 func (r Repo) GetByID(ctx context.Context, id int64) (links.Link, error) {
 	const query = `
-		SELECT links.id, alias, url, author_id, ARRAY_AGG(la.ad_id ORDER BY la.ad_id)
+		SELECT links.id, alias, url, author_id, ad_delay, active_from, expires_at, archived_at, password, strategy,
+		       created_at, updated_at, ARRAY_AGG(la.ad_id ORDER BY la.ad_id), ARRAY_AGG(la.weight ORDER BY la.ad_id)
 		FROM links
         	LEFT JOIN link_ads la on links.id = la.link_id 
 		WHERE links.id=$1
@@ -110,8 +159,14 @@ func (r Repo) GetByID(ctx context.Context, id int64) (links.Link, error) {
 
 	link := links.Link{}
 	var ads pgtype.Array[pgtype.Int8]
+	var weights pgtype.Array[pgtype.Int4]
+	var sch schedule
 
-	err := r.db.QueryRow(ctx, query, id).Scan(&link.ID, &link.Alias, &link.URL, &link.AuthorID, &ads)
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&link.ID, &link.Alias, &link.URL, &link.AuthorID, &link.AdDelay,
+		&sch.activeFrom, &sch.expiresAt, &sch.archivedAt, &link.PasswordHash, &link.Strategy,
+		&link.CreateDate, &link.UpdateDate, &ads, &weights,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).
@@ -123,48 +178,104 @@ func (r Repo) GetByID(ctx context.Context, id int64) (links.Link, error) {
 		}
 		return link, err
 	}
+	sch.apply(&link)
+	link.AdWeights = adWeights(ads, weights)
 	link.Ads = pgArrayToGoSlice(ads)
 	return link, nil
 }
 
-func (r Repo) GetByAuthor(ctx context.Context, authorID int64) ([]links.Link, error) {
-	const query = `
-		SELECT id, alias, url, ARRAY_AGG(la.ad_id ORDER BY la.ad_id)
-		FROM links 
-			LEFT JOIN link_ads la on links.id=la.link_id 
-		WHERE links.author_id=$1
-		GROUP BY links.id
-	`
+// sortKeys are SQL expressions of sort keys of links with their types
+var sortKeys = map[string][2]string{
+	pagination.SortCreated: {"links.created_at", "timestamp"},
+	pagination.SortUpdated: {"links.updated_at", "timestamp"},
+	pagination.SortAlias:   {"links.alias", "text"},
+}
+
+// GetByAuthor returns the page of author's links by keyset pagination on the sort key and ID of the last link
+func (r Repo) GetByAuthor(ctx context.Context, authorID int64, page pagination.Page) ([]links.Link, pagination.Info, error) {
 	const op = "pgrepo.GetByAuthor"
 
-	rows, err := r.db.Query(ctx, query, authorID)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).
-				WithDetails(fmt.Sprintf("%v | author ID: %d", err, authorID))
-		} else {
-			err = errwrap.New(err, app.ServiceName, op).
+	var info pagination.Info
+	var args []any
+	// arg adds the argument of the query and returns its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := "links.author_id=" + arg(authorID)
+	if page.Total {
+		err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM links WHERE `+where, args...).Scan(&info.Total)
+		if err != nil {
+			return nil, info, errwrap.New(err, app.ServiceName, op).
 				WithDetails(fmt.Sprintf("author ID: %d", authorID))
 		}
-		return nil, err
+	}
+
+	name, desc := page.Key()
+	key, ok := sortKeys[name]
+	if !ok {
+		return nil, info, errwrap.New(app.ErrInvalidContent, app.ServiceName, op).
+			WithDetails(fmt.Sprintf("unknown sort: %s", page.Sort))
+	}
+	cursor, err := page.Cursor()
+	if err != nil {
+		return nil, info, errwrap.New(errors.Join(app.ErrInvalidContent, err), app.ServiceName, op)
+	}
+	after, order := pagination.Keyset(key[0], key[1], "links.id", desc, cursor, arg)
+	if after != "" {
+		where += " AND " + after
+	}
+	query := `
+		SELECT links.id, alias, url, ad_delay, active_from, expires_at, archived_at, password, strategy,
+		       created_at, updated_at, ` + key[0] + `::text,
+		       ARRAY_AGG(la.ad_id ORDER BY la.ad_id), ARRAY_AGG(la.weight ORDER BY la.ad_id)
+		FROM links
+			LEFT JOIN link_ads la on links.id=la.link_id
+		WHERE ` + where + `
+		GROUP BY links.id
+		ORDER BY ` + order + ` LIMIT ` + arg(page.Size+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, info, errwrap.New(err, app.ServiceName, op).
+			WithDetails(fmt.Sprintf("author ID: %d", authorID))
 	}
 	defer rows.Close()
-	var res []links.Link
+	res := make([]links.Link, 0, page.Size)
+	var last pagination.Cursor
 	for rows.Next() {
 		link := links.Link{AuthorID: authorID}
 		var ads pgtype.Array[pgtype.Int8]
-		err := rows.Scan(&link.ID, &link.Alias, &link.URL, &ads)
+		var weights pgtype.Array[pgtype.Int4]
+		var sch schedule
+		var sortKey string
+		err := rows.Scan(
+			&link.ID, &link.Alias, &link.URL, &link.AdDelay,
+			&sch.activeFrom, &sch.expiresAt, &sch.archivedAt, &link.PasswordHash, &link.Strategy,
+			&link.CreateDate, &link.UpdateDate, &sortKey, &ads, &weights,
+		)
 		if err != nil {
-			return res, errwrap.New(err, app.ServiceName, op)
+			return res, info, errwrap.New(err, app.ServiceName, op)
+		}
+		if len(res) == page.Size {
+			info.NextToken = last.Encode()
+			break
 		}
+		sch.apply(&link)
+		link.AdWeights = adWeights(ads, weights)
 		link.Ads = pgArrayToGoSlice(ads)
+		last = pagination.Cursor{Sort: page.Sort, Key: sortKey, ID: link.ID}
 		res = append(res, link)
 	}
-	return res, nil
+	if rows.Err() != nil {
+		return res, info, errwrap.New(rows.Err(), app.ServiceName, op)
+	}
+	return res, info, nil
 }
 
 func (r Repo) GetByAlias(ctx context.Context, alias string) (links.Link, error) {
-	const query = `SELECT links.id, url, author_id, ARRAY_AGG(la.ad_id ORDER BY la.ad_id)
+	const query = `SELECT links.id, url, author_id, ad_delay, active_from, expires_at, archived_at, password, strategy,
+		       created_at, updated_at, ARRAY_AGG(la.ad_id ORDER BY la.ad_id), ARRAY_AGG(la.weight ORDER BY la.ad_id)
 		FROM links
 				 LEFT JOIN link_ads la on links.id = la.link_id
 		WHERE links.alias = $1
@@ -174,7 +285,13 @@ func (r Repo) GetByAlias(ctx context.Context, alias string) (links.Link, error)
 
 	link := links.Link{Alias: alias}
 	var ads pgtype.Array[pgtype.Int8]
-	err := r.db.QueryRow(ctx, query, alias).Scan(&link.ID, &link.URL, &link.AuthorID, &ads)
+	var weights pgtype.Array[pgtype.Int4]
+	var sch schedule
+	err := r.db.QueryRow(ctx, query, alias).Scan(
+		&link.ID, &link.URL, &link.AuthorID, &link.AdDelay,
+		&sch.activeFrom, &sch.expiresAt, &sch.archivedAt, &link.PasswordHash, &link.Strategy,
+		&link.CreateDate, &link.UpdateDate, &ads, &weights,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = errwrap.New(app.ErrNotFound, app.ServiceName, op).
@@ -185,15 +302,17 @@ func (r Repo) GetByAlias(ctx context.Context, alias string) (links.Link, error)
 		}
 		return link, err
 	}
+	sch.apply(&link)
+	link.AdWeights = adWeights(ads, weights)
 	link.Ads = pgArrayToGoSlice(ads)
 	return link, nil
 }
 
-func (r Repo) UpdateAlias(ctx context.Context, id int64, alias string) error {
-	const query = `UPDATE links SET alias=$1 WHERE id=$2`
+func (r Repo) UpdateAlias(ctx context.Context, id int64, alias string, moment time.Time) error {
+	const query = `UPDATE links SET alias=$1, updated_at=$3 WHERE id=$2`
 	const op = "pgrepo.UpdateAlias"
 
-	_, err := r.db.Exec(ctx, query, alias, id)
+	_, err := r.db.Exec(ctx, query, alias, id, moment)
 	if errors.Is(err, pgx.ErrNoRows) {
 		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).OnObject("link", id).WithDetails(err.Error())
 	} else if err != nil {
@@ -202,11 +321,85 @@ func (r Repo) UpdateAlias(ctx context.Context, id int64, alias string) error {
 	return err
 }
 
-func (r Repo) AddAd(ctx context.Context, linkID int64, adID int64) error {
-	const query = `INSERT INTO link_ads (link_id, ad_id) VALUES ($1, $2)`
+// UpdateURL changes URL of the link and saves the previous one to the history in the same statement
+func (r Repo) UpdateURL(ctx context.Context, id int64, url string, editorID int64, moment time.Time) error {
+	const query = `
+		WITH prev AS (SELECT id, url FROM links WHERE id=$2 FOR UPDATE),
+			 history AS (
+				 INSERT INTO link_url_history (link_id, url, changed_at, changed_by)
+				 SELECT id, url, $3, $4 FROM prev
+			 )
+		UPDATE links SET url=$1, updated_at=$3 FROM prev WHERE links.id=prev.id
+	`
+	const op = "pgrepo.UpdateURL"
+
+	tag, err := r.db.Exec(ctx, query, url, id, moment, editorID)
+	if err == nil && tag.RowsAffected() == 0 {
+		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).OnObject("link", id)
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("link", id)
+	}
+	return err
+}
+
+func (r Repo) UpdateAdDelay(ctx context.Context, id int64, adDelay int, moment time.Time) error {
+	const query = `UPDATE links SET ad_delay=$1, updated_at=$3 WHERE id=$2`
+	const op = "pgrepo.UpdateAdDelay"
+
+	_, err := r.db.Exec(ctx, query, adDelay, id, moment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).OnObject("link", id).WithDetails(err.Error())
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("link", id)
+	}
+	return err
+}
+
+func (r Repo) UpdatePassword(ctx context.Context, id int64, passwordHash string, moment time.Time) error {
+	const query = `UPDATE links SET password=$1, updated_at=$3 WHERE id=$2`
+	const op = "pgrepo.UpdatePassword"
+
+	_, err := r.db.Exec(ctx, query, passwordHash, id, moment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errwrap.New(app.ErrNotFound, app.ServiceName, op).OnObject("link", id).WithDetails(err.Error())
+	} else if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("link", id)
+	}
+	return err
+}
+
+// ArchiveExpired marks links expired by the moment as archived and returns number of them
+func (r Repo) ArchiveExpired(ctx context.Context, moment time.Time) (int64, error) {
+	const query = `UPDATE links SET archived_at=$1 WHERE archived_at IS NULL AND expires_at <= $1`
+	const op = "pgrepo.ArchiveExpired"
+
+	tag, err := r.db.Exec(ctx, query, moment)
+	if err != nil {
+		return 0, errwrap.New(err, app.ServiceName, op)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r Repo) UpdateStrategy(ctx context.Context, id int64, strategy string, moment time.Time) error {
+	const query = `UPDATE links SET strategy=$1, updated_at=$3 WHERE id=$2`
+	const op = "pgrepo.UpdateStrategy"
+
+	_, err := r.db.Exec(ctx, query, strategy, id, moment)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("link", id)
+	}
+	return err
+}
+
+// AddAd adds the ad to the link or changes its weight if the ad has been already added
+func (r Repo) AddAd(ctx context.Context, linkID int64, adID int64, weight int) error {
+	const query = `
+		INSERT INTO link_ads (link_id, ad_id, weight) VALUES ($1, $2, $3)
+		ON CONFLICT (link_id, ad_id) DO UPDATE SET weight=excluded.weight
+	`
 	const op = "pgrepo.AddAd"
 
-	_, err := r.db.Exec(ctx, query, linkID, adID)
+	_, err := r.db.Exec(ctx, query, linkID, adID, weight)
 	if err != nil {
 		err = errwrap.New(err, app.ServiceName, op).OnObject("link", linkID).
 			WithDetails(fmt.Sprintf("ad ID: %d", adID))
@@ -245,6 +438,167 @@ func (r Repo) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
-func New(db *pgx.Conn) Repo {
+// StoreClicks inserts clicks by one batch. Clicks of already deleted links are skipped
+func (r Repo) StoreClicks(ctx context.Context, list []clicks.Click) (err error) {
+	const query = `
+		INSERT INTO clicks (link_id, ad_id, created_at, referrer, user_agent, ip_hash)
+		SELECT $1::bigint, NULLIF($2::bigint, 0), $3::timestamp, $4::text, $5::text, $6::text
+		WHERE EXISTS (SELECT 1 FROM links WHERE id=$1)
+	`
+	const op = "pgrepo.StoreClicks"
+
+	batch := &pgx.Batch{}
+	for _, c := range list {
+		batch.Queue(query, c.LinkID, c.AdID, c.Time, c.Referrer, c.UserAgent, c.IPHash)
+	}
+	br := r.db.SendBatch(ctx, batch)
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, br.Close())
+		} else if err = br.Close(); err != nil {
+			err = errwrap.New(err, app.ServiceName, op)
+		}
+	}()
+	for range list {
+		if _, err = br.Exec(); err != nil {
+			return errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("clicks: %d", len(list)))
+		}
+	}
+	return nil
+}
+
+func (r Repo) StoreAdClick(ctx context.Context, click clicks.AdClick, since time.Time) error {
+	const query = `
+		INSERT INTO ad_clicks (link_id, ad_id, created_at, impression_id, visitor)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ad_clicks
+			WHERE link_id=$1 AND ad_id=$2 AND visitor=$5 AND created_at >= $6
+		)
+		ON CONFLICT (impression_id) DO NOTHING
+	`
+	const op = "pgrepo.StoreAdClick"
+
+	_, err := r.db.Exec(ctx, query, click.LinkID, click.AdID, click.Time, click.Impression, click.Visitor, since)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("link", click.LinkID).
+			WithDetails(fmt.Sprintf("ad ID: %d", click.AdID))
+	}
+	return err
+}
+
+// GetAdPerformance returns shows and clicks of every ad of the link
+func (r Repo) GetAdPerformance(ctx context.Context, linkID int64) ([]clicks.AdPerformance, error) {
+	const query = `
+		SELECT la.ad_id,
+		       (SELECT COUNT(*) FROM clicks c WHERE c.link_id=la.link_id AND c.ad_id=la.ad_id),
+		       (SELECT COUNT(*) FROM ad_clicks ac WHERE ac.link_id=la.link_id AND ac.ad_id=la.ad_id)
+		FROM link_ads la
+		WHERE la.link_id=$1
+	`
+	const op = "pgrepo.GetAdPerformance"
+
+	rows, err := r.db.Query(ctx, query, linkID)
+	if err != nil {
+		return nil, errwrap.New(err, app.ServiceName, op).OnObject("link", linkID)
+	}
+	res, err := pgx.CollectRows(rows, pgx.RowToStructByPos[clicks.AdPerformance])
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("link", linkID)
+	}
+	return res, err
+}
+
+func (r Repo) GetStats(ctx context.Context, linkID int64, from time.Time, to time.Time) (stats clicks.Stats, err error) {
+	const byDayQuery = `
+		SELECT date_trunc('day', created_at), COUNT(*) FROM clicks
+		WHERE link_id=$1 AND created_at >= $2 AND created_at < $3
+		GROUP BY 1 ORDER BY 1
+	`
+	const byReferrerQuery = `
+		SELECT referrer, COUNT(*) FROM clicks
+		WHERE link_id=$1 AND created_at >= $2 AND created_at < $3
+		GROUP BY 1 ORDER BY 2 DESC, 1
+	`
+	const byAdQuery = `
+		SELECT COALESCE(ad_id, 0), COUNT(*) FROM clicks
+		WHERE link_id=$1 AND created_at >= $2 AND created_at < $3
+		GROUP BY 1 ORDER BY 2 DESC, 1
+	`
+	const op = "pgrepo.GetStats"
+
+	defer func() {
+		if err != nil {
+			err = errwrap.New(err, app.ServiceName, op).OnObject("link", linkID)
+		}
+	}()
+
+	rows, err := r.db.Query(ctx, byDayQuery, linkID, from, to)
+	if err != nil {
+		return
+	}
+	stats.ByDay, err = pgx.CollectRows(rows, pgx.RowToStructByPos[clicks.DayStat])
+	if err != nil {
+		return
+	}
+	for _, s := range stats.ByDay {
+		stats.Total += s.Count
+	}
+
+	rows, err = r.db.Query(ctx, byReferrerQuery, linkID, from, to)
+	if err != nil {
+		return
+	}
+	stats.ByReferrer, err = pgx.CollectRows(rows, pgx.RowToStructByPos[clicks.ReferrerStat])
+	if err != nil {
+		return
+	}
+
+	rows, err = r.db.Query(ctx, byAdQuery, linkID, from, to)
+	if err != nil {
+		return
+	}
+	stats.ByAd, err = pgx.CollectRows(rows, pgx.RowToStructByPos[clicks.AdStat])
+	return
+}
+
+func (r Repo) DeleteByAuthor(ctx context.Context, authorID int64) error {
+	const query = `DELETE FROM links WHERE author_id=$1`
+	const op = "pgrepo.DeleteByAuthor"
+
+	_, err := r.db.Exec(ctx, query, authorID)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).OnObject("author", authorID)
+	}
+	return err
+}
+
+func (r Repo) GetEventCursor(ctx context.Context, consumer string) (int64, error) {
+	const query = `SELECT last_event_id FROM event_cursors WHERE consumer=$1`
+	const op = "pgrepo.GetEventCursor"
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, consumer).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("consumer: %s", consumer))
+	}
+	return id, nil
+}
+
+func (r Repo) SetEventCursor(ctx context.Context, consumer string, id int64) error {
+	const query = `INSERT INTO event_cursors (consumer, last_event_id) VALUES ($1, $2)
+		ON CONFLICT (consumer) DO UPDATE SET last_event_id=EXCLUDED.last_event_id`
+	const op = "pgrepo.SetEventCursor"
+
+	_, err := r.db.Exec(ctx, query, consumer, id)
+	if err != nil {
+		err = errwrap.New(err, app.ServiceName, op).WithDetails(fmt.Sprintf("consumer: %s, event: %d", consumer, id))
+	}
+	return err
+}
+
+func New(db *pgxpool.Pool) Repo {
 	return Repo{db}
 }
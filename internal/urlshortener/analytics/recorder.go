@@ -0,0 +1,106 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/urlshortener/app"
+	"goads/internal/urlshortener/entities/clicks"
+	"log"
+	"time"
+)
+
+var ErrQueueFull = errors.New("clicks queue is full")
+
+type Repository interface {
+	StoreClicks(ctx context.Context, list []clicks.Click) error
+}
+
+// Recorder is a redirect event pipeline: clicks are queued by Record without waiting for the
+// database and written to the Repo by batches of batchSize or every interval in Listen
+type Recorder struct {
+	Repo      Repository
+	salt      []byte
+	queue     chan clicks.Click
+	batchSize int
+	interval  time.Duration
+}
+
+// hashIP returns HMAC-SHA256 of the IP address, so clicks from the same visitor can be
+// grouped without storing the address itself
+func (r Recorder) hashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Record queues the click. If the queue is full, the click is dropped and ErrQueueFull is returned
+func (r Recorder) Record(ctx context.Context, linkID int64, adID int64, visitor clicks.Visitor) error {
+	const op = "analytics.Record"
+
+	click := clicks.New(linkID, adID, visitor.Referrer, visitor.UserAgent, r.hashIP(visitor.IP))
+	select {
+	case <-ctx.Done():
+		return errwrap.New(ctx.Err(), app.ServiceName, op).OnObject("link", linkID)
+	case r.queue <- click:
+		return nil
+	default:
+		return errwrap.New(ErrQueueFull, app.ServiceName, op).OnObject("link", linkID)
+	}
+}
+
+func (r Recorder) flush(ctx context.Context, batch []clicks.Click) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := r.Repo.StoreClicks(ctx, batch); err != nil {
+		log.Printf("cannot store %d clicks: %v\n", len(batch), err)
+	}
+}
+
+// Listen writes queued clicks until ctx is done. Remaining clicks are flushed before returning
+func (r Recorder) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	batch := make([]clicks.Click, 0, r.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case click := <-r.queue:
+					batch = append(batch, click)
+				default:
+					r.flush(context.Background(), batch)
+					return ctx.Err()
+				}
+			}
+		case click := <-r.queue:
+			batch = append(batch, click)
+			if len(batch) >= r.batchSize {
+				r.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func New(repo Repository, salt string, queueSize int, batchSize int, interval time.Duration) Recorder {
+	return Recorder{
+		Repo:      repo,
+		salt:      []byte(salt),
+		queue:     make(chan clicks.Click, queueSize),
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/urlshortener/entities/clicks"
+	"sync"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	mu      sync.Mutex
+	batches [][]clicks.Click
+}
+
+func (r *repo) StoreClicks(_ context.Context, list []clicks.Click) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, append([]clicks.Click(nil), list...))
+	return nil
+}
+
+func (r *repo) stored() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, b := range r.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestRecorder_Record(t *testing.T) {
+	rec := New(&repo{}, "salt", 1, 1, time.Hour)
+	visitor := clicks.Visitor{Referrer: "https://github.com", UserAgent: "test", IP: "127.0.0.1"}
+
+	require.NoError(t, rec.Record(context.Background(), 1, 2, visitor))
+	assert.ErrorIs(t, rec.Record(context.Background(), 1, 2, visitor), ErrQueueFull)
+
+	click := <-rec.queue
+	assert.Equal(t, int64(1), click.LinkID)
+	assert.Equal(t, int64(2), click.AdID)
+	assert.Equal(t, visitor.Referrer, click.Referrer)
+	assert.NotEmpty(t, click.IPHash)
+	assert.NotContains(t, click.IPHash, visitor.IP)
+	assert.Equal(t, rec.hashIP(visitor.IP), click.IPHash)
+	assert.NotEqual(t, New(nil, "other", 1, 1, time.Hour).hashIP(visitor.IP), click.IPHash)
+}
+
+func TestRecorder_Listen(t *testing.T) {
+	r := &repo{}
+	rec := New(r, "salt", 10, 3, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- rec.Listen(ctx)
+	}()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, rec.Record(context.Background(), int64(i), 0, clicks.Visitor{}))
+	}
+	assert.Eventually(t, func() bool { return r.stored() == 3 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 4, r.stored(), "remaining clicks must be flushed on shutdown")
+}
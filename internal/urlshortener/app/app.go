@@ -6,22 +6,38 @@ import (
 	"fmt"
 	"github.com/ormequ/validator"
 	"goads/internal/pkg/errwrap"
+	"goads/internal/pkg/pagination"
+	"goads/internal/pkg/permissions"
 	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/clicks"
 	"goads/internal/urlshortener/entities/links"
 	"goads/internal/urlshortener/entities/redirects"
 	"math/rand"
+	"time"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Repository
 type Repository interface {
 	Store(ctx context.Context, link links.Link) (int64, error)
 	GetByID(ctx context.Context, id int64) (links.Link, error)
-	GetByAuthor(ctx context.Context, authorID int64) ([]links.Link, error)
+	GetByAuthor(ctx context.Context, authorID int64, page pagination.Page) ([]links.Link, pagination.Info, error)
 	GetByAlias(ctx context.Context, alias string) (links.Link, error)
-	UpdateAlias(ctx context.Context, id int64, alias string) error
-	AddAd(ctx context.Context, linkID int64, adID int64) error
+	// UpdateAlias and other updates of the link's fields set its update date to the moment
+	UpdateAlias(ctx context.Context, id int64, alias string, moment time.Time) error
+	// UpdateURL saves the previous URL to the history of the link with the user who changed it
+	UpdateURL(ctx context.Context, id int64, url string, editorID int64, moment time.Time) error
+	UpdateAdDelay(ctx context.Context, id int64, adDelay int, moment time.Time) error
+	UpdatePassword(ctx context.Context, id int64, passwordHash string, moment time.Time) error
+	UpdateStrategy(ctx context.Context, id int64, strategy string, moment time.Time) error
+	AddAd(ctx context.Context, linkID int64, adID int64, weight int) error
 	DeleteAd(ctx context.Context, linkID int64, adID int64) error
 	Delete(ctx context.Context, id int64) error
+	// DeleteByAuthor removes all links of the author
+	DeleteByAuthor(ctx context.Context, authorID int64) error
+	GetStats(ctx context.Context, linkID int64, from time.Time, to time.Time) (clicks.Stats, error)
+	// StoreAdClick stores the click unless a click of the same impression or of the same visitor
+	// since the moment is already stored
+	StoreAdClick(ctx context.Context, click clicks.AdClick, since time.Time) error
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Generator
@@ -31,13 +47,59 @@ type Generator interface {
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=AdsService
 type AdsService interface {
-	GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error)
+	// GetOnlyPublished returns published ads with given ids and ids of ads which do not exist anymore
+	GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, []int64, error)
+	CountImpression(ctx context.Context, adID int64) error
 }
 
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=ClickRecorder
+type ClickRecorder interface {
+	Record(ctx context.Context, linkID int64, adID int64, visitor clicks.Visitor) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=Hasher
+type Hasher interface {
+	Generate(ctx context.Context, password string) (string, error)
+	Compare(ctx context.Context, hash string, password string) error
+}
+
+// AttemptsLimiter limits wrong password attempts by key
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=AttemptsLimiter
+type AttemptsLimiter interface {
+	Allowed(ctx context.Context, key string) (bool, error)
+	Failed(ctx context.Context, key string) error
+}
+
+// ImpressionSigner issues tokens of ads shown to visitors, so clicks are recorded only on shown ads
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=ImpressionSigner
+type ImpressionSigner interface {
+	Issue(ctx context.Context, linkID int64, adID int64, ip string) (string, error)
+	// Parse returns the impression of the token issued to the visitor with the IP address
+	Parse(ctx context.Context, token string, ip string) (redirects.Impression, error)
+}
+
+// AdSelector selects the ad shown on redirect of the link from its published ads
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.0 --name=AdSelector
+type AdSelector interface {
+	Select(ctx context.Context, link links.Link, list []ads.Ad) (ads.Ad, error)
+}
+
+// App is the URL Shortener application. Selectors are ad selection strategies by their names,
+// links.StrategyRandom is built in and always available. ClickWindow is the period during which
+// only one click of the visitor on the ad is recorded
 type App struct {
-	Repo Repository
-	Gen  Generator
-	Ads  AdsService
+	Repo        Repository
+	Gen         Generator
+	Ads         AdsService
+	Clicks      ClickRecorder
+	Hasher      Hasher
+	Attempts    AttemptsLimiter
+	Selectors   map[string]AdSelector
+	Impressions ImpressionSigner
+	ClickWindow time.Duration
 }
 
 func (a App) generateFreeAlias(ctx context.Context) (alias string, err error) {
@@ -52,8 +114,59 @@ func (a App) generateFreeAlias(ctx context.Context) (alias string, err error) {
 	return
 }
 
-func (a App) Create(ctx context.Context, url string, alias string, authorID int64, ads []int64) (links.Link, error) {
-	const op = "app.generateFreeAlias"
+// checkStrategy returns ErrInvalidContent if the strategy is not available
+func (a App) checkStrategy(strategy string) error {
+	const op = "app.checkStrategy"
+	if _, ok := a.Selectors[strategy]; ok || strategy == links.StrategyRandom {
+		return nil
+	}
+	return errwrap.New(ErrInvalidContent, ServiceName, op).WithDetails(fmt.Sprintf("unknown strategy `%s`", strategy))
+}
+
+// selectAd selects the ad by the strategy of the link. Unavailable or failed strategy is replaced by the random one
+func (a App) selectAd(ctx context.Context, link links.Link, list []ads.Ad) ads.Ad {
+	if selector, ok := a.Selectors[link.Strategy]; ok {
+		if ad, err := selector.Select(ctx, link, list); err == nil {
+			return ad
+		}
+	}
+	return list[rand.Intn(len(list))]
+}
+
+// showAd selects the ad and counts its impression. Ads which impressions are not counted, e.g. because
+// of exhausted campaign, are not shown and another ad is selected
+func (a App) showAd(ctx context.Context, link links.Link, list []ads.Ad) ads.Ad {
+	list = append([]ads.Ad(nil), list...)
+	for len(list) > 0 {
+		ad := a.selectAd(ctx, link, list)
+		if a.Ads.CountImpression(ctx, ad.ID) == nil {
+			return ad
+		}
+		for i := range list {
+			if list[i].ID == ad.ID {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+	}
+	return ads.Ad{}
+}
+
+// Create creates a new link. Zero activeFrom and expiresAt mean that the link is active without time restrictions.
+// Empty password means that the link is not protected, empty strategy means links.StrategyRandom
+func (a App) Create(
+	ctx context.Context,
+	url string,
+	alias string,
+	authorID int64,
+	ads []int64,
+	adDelay int,
+	activeFrom time.Time,
+	expiresAt time.Time,
+	password string,
+	strategy string,
+) (links.Link, error) {
+	const op = "app.Create"
 
 	var err error
 	if alias == "" {
@@ -62,12 +175,28 @@ func (a App) Create(ctx context.Context, url string, alias string, authorID int6
 			return links.Link{}, errwrap.JoinWithCaller(err, op)
 		}
 	}
-	link := links.New(url, alias, authorID, ads)
+	link := links.New(url, alias, authorID, ads, adDelay, activeFrom, expiresAt)
+	if strategy != "" {
+		link.Strategy = strategy
+	}
 	err = govalid.Validate(link)
 	if err != nil {
 		err = errors.Join(ErrInvalidContent, err)
 		return link, errwrap.New(err, ServiceName, op)
 	}
+	if !activeFrom.IsZero() && !expiresAt.IsZero() && !expiresAt.After(activeFrom) {
+		return link, errwrap.New(ErrInvalidContent, ServiceName, op).
+			WithDetails(fmt.Sprintf("link expires at %s before activation at %s", expiresAt, activeFrom))
+	}
+	if err = a.checkStrategy(link.Strategy); err != nil {
+		return link, errwrap.JoinWithCaller(err, op)
+	}
+	if password != "" {
+		link.PasswordHash, err = a.Hasher.Generate(ctx, password)
+		if err != nil {
+			return link, errwrap.JoinWithCaller(err, op)
+		}
+	}
 	link.ID, err = a.Repo.Store(ctx, link)
 	return link, errwrap.JoinWithCaller(err, op)
 }
@@ -78,25 +207,106 @@ func (a App) GetByID(ctx context.Context, id int64) (links.Link, error) {
 	return link, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) GetByAuthor(ctx context.Context, author int64) ([]links.Link, error) {
+// GetByAuthor returns the page of author's links, which are sorted by creation date by default
+func (a App) GetByAuthor(ctx context.Context, author int64, page pagination.Page) ([]links.Link, pagination.Info, error) {
 	const op = "app.GetByAuthor"
-	link, err := a.Repo.GetByAuthor(ctx, author)
-	return link, errwrap.JoinWithCaller(err, op)
+	page, err := page.Normalize("-"+pagination.SortCreated, pagination.SortUpdated, pagination.SortAlias)
+	if err != nil {
+		return nil, pagination.Info{}, errwrap.New(errors.Join(ErrInvalidContent, err), ServiceName, op)
+	}
+	list, info, err := a.Repo.GetByAuthor(ctx, author, page)
+	return list, info, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) GetRedirect(ctx context.Context, alias string) (redirects.Redirect, error) {
+// checkPassword compares password with the hash of the protected link. Wrong attempts are limited by alias
+func (a App) checkPassword(ctx context.Context, link links.Link, password string) error {
+	const op = "app.checkPassword"
+	if !link.IsProtected() {
+		return nil
+	}
+	if password == "" {
+		return errwrap.New(ErrPasswordRequired, ServiceName, op).OnObject("link", link.ID)
+	}
+	allowed, err := a.Attempts.Allowed(ctx, link.Alias)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if !allowed {
+		return errwrap.New(ErrTooManyAttempts, ServiceName, op).OnObject("link", link.ID)
+	}
+	err = a.Hasher.Compare(ctx, link.PasswordHash, password)
+	if errors.Is(err, ErrWrongPassword) {
+		err = errors.Join(err, a.Attempts.Failed(ctx, link.Alias))
+	}
+	return errwrap.JoinWithCaller(err, op)
+}
+
+// GetRedirect returns link with randomly selected ad and records the click of the visitor.
+// Protected link requires the password. Failed recording does not affect the redirect
+func (a App) GetRedirect(ctx context.Context, alias string, visitor clicks.Visitor, password string) (redirects.Redirect, error) {
 	const op = "app.GetRedirect"
 	link, err := a.GetByAlias(ctx, alias)
 	if err != nil {
 		return redirects.Redirect{}, errwrap.JoinWithCaller(err, op)
 	}
+	if err := a.checkAvailable(link, time.Now().UTC()); err != nil {
+		return redirects.Redirect{}, errwrap.JoinWithCaller(err, op)
+	}
+	if err := a.checkPassword(ctx, link, password); err != nil {
+		return redirects.Redirect{}, errwrap.JoinWithCaller(err, op)
+	}
 
-	adsList, err := a.Ads.GetOnlyPublished(ctx, link.Ads)
+	adsList, missing, err := a.Ads.GetOnlyPublished(ctx, link.Ads)
+	for _, id := range missing {
+		// the ad is deleted in the ads service, the link does not show it anymore
+		_ = a.Repo.DeleteAd(ctx, link.ID, id)
+	}
 	var ad ads.Ad
 	if err == nil && len(adsList) > 0 {
-		ad = adsList[rand.Intn(len(adsList))]
+		ad = a.showAd(ctx, link, adsList)
+	}
+	_ = a.Clicks.Record(ctx, link.ID, ad.ID, visitor)
+	redirect := redirects.New(link, ad)
+	if ad.ID != 0 {
+		// without the token the ad is still shown, only its clicks are not recorded
+		redirect.Impression, _ = a.Impressions.Issue(ctx, link.ID, ad.ID, visitor.IP)
+	}
+	return redirect, nil
+}
+
+// checkAvailable returns an error if the link is not active at the moment
+func (a App) checkAvailable(link links.Link, moment time.Time) error {
+	const op = "app.checkAvailable"
+	if !link.IsActivated(moment) {
+		return errwrap.New(ErrNotActive, ServiceName, op).
+			OnObject("link", link.ID).
+			WithDetails(fmt.Sprintf("active from %s", link.ActiveFrom))
+	}
+	if link.IsExpired(moment) {
+		return errwrap.New(ErrExpired, ServiceName, op).
+			OnObject("link", link.ID).
+			WithDetails(fmt.Sprintf("expired at %s", link.ExpiresAt))
+	}
+	return nil
+}
+
+// GetStats returns aggregated clicks of the link in [from, to). Zero to means now
+func (a App) GetStats(ctx context.Context, linkID int64, authorID int64, from time.Time, to time.Time) (clicks.Stats, error) {
+	const op = "app.GetStats"
+	_, err := a.getEditable(ctx, linkID, authorID, permissions.EditAny)
+	if err != nil {
+		return clicks.Stats{}, errwrap.JoinWithCaller(err, op)
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.After(to) {
+		return clicks.Stats{}, errwrap.New(ErrInvalidContent, ServiceName, op).
+			OnObject("link", linkID).
+			WithDetails(fmt.Sprintf("from %s is after to %s", from, to))
 	}
-	return redirects.New(link, ad), nil
+	stats, err := a.Repo.GetStats(ctx, linkID, from, to)
+	return stats, errwrap.JoinWithCaller(err, op)
 }
 
 func (a App) GetByAlias(ctx context.Context, alias string) (links.Link, error) {
@@ -105,13 +315,14 @@ func (a App) GetByAlias(ctx context.Context, alias string) (links.Link, error) {
 	return link, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) getEditable(ctx context.Context, id int64, authorID int64) (links.Link, error) {
+// getEditable returns the link if authorID is equal to author id of the link or the user's role has the permission
+func (a App) getEditable(ctx context.Context, id int64, authorID int64, permission permissions.Permission) (links.Link, error) {
 	const op = "app.getEditable"
 	link, err := a.GetByID(ctx, id)
 	if err != nil {
 		return link, errwrap.JoinWithCaller(err, op)
 	}
-	if link.AuthorID != authorID {
+	if !permissions.Allowed(ctx, authorID, link.AuthorID, permission) {
 		return link, errwrap.New(ErrPermissionDenied, ServiceName, op).OnObject("link", id)
 	}
 	return link, nil
@@ -119,7 +330,7 @@ func (a App) getEditable(ctx context.Context, id int64, authorID int64) (links.L
 
 func (a App) UpdateAlias(ctx context.Context, id int64, authorID int64, alias string) (links.Link, error) {
 	const op = "app.UpdateAlias"
-	link, err := a.getEditable(ctx, id, authorID)
+	link, err := a.getEditable(ctx, id, authorID, permissions.EditAny)
 	if err != nil {
 		return link, errwrap.JoinWithCaller(err, op)
 	}
@@ -137,36 +348,193 @@ func (a App) UpdateAlias(ctx context.Context, id int64, authorID int64, alias st
 		err = errors.Join(ErrInvalidContent, err)
 		return link, errwrap.New(err, ServiceName, op).OnObject("link", id)
 	}
-	err = a.Repo.UpdateAlias(ctx, id, alias)
+	now := time.Now().UTC()
+	err = a.Repo.UpdateAlias(ctx, id, alias, now)
 	if err != nil {
 		link.Alias = prev
+	} else {
+		link.UpdateDate = now
+	}
+	return link, errwrap.JoinWithCaller(err, op)
+}
+
+// UpdateURL changes destination of the link. Previous destination is kept in the history by the repository
+func (a App) UpdateURL(ctx context.Context, id int64, authorID int64, url string) (links.Link, error) {
+	const op = "app.UpdateURL"
+	link, err := a.getEditable(ctx, id, authorID, permissions.EditAny)
+	if err != nil {
+		return link, errwrap.JoinWithCaller(err, op)
+	}
+	prev := link.URL
+	link.URL = url
+	err = govalid.Validate(link)
+	if err != nil {
+		link.URL = prev
+		err = errors.Join(ErrInvalidContent, err)
+		return link, errwrap.New(err, ServiceName, op).OnObject("link", id)
+	}
+	now := time.Now().UTC()
+	err = a.Repo.UpdateURL(ctx, id, url, authorID, now)
+	if err != nil {
+		link.URL = prev
+	} else {
+		link.UpdateDate = now
+	}
+	return link, errwrap.JoinWithCaller(err, op)
+}
+
+// UpdateAdDelay changes seconds during which an ad of the link is shown before redirecting
+func (a App) UpdateAdDelay(ctx context.Context, id int64, authorID int64, adDelay int) (links.Link, error) {
+	const op = "app.UpdateAdDelay"
+	link, err := a.getEditable(ctx, id, authorID, permissions.EditAny)
+	if err != nil {
+		return link, errwrap.JoinWithCaller(err, op)
+	}
+	prev := link.AdDelay
+	link.AdDelay = adDelay
+	err = govalid.Validate(link)
+	if err != nil {
+		link.AdDelay = prev
+		err = errors.Join(ErrInvalidContent, err)
+		return link, errwrap.New(err, ServiceName, op).OnObject("link", id)
+	}
+	now := time.Now().UTC()
+	err = a.Repo.UpdateAdDelay(ctx, id, adDelay, now)
+	if err != nil {
+		link.AdDelay = prev
+	} else {
+		link.UpdateDate = now
 	}
 	return link, errwrap.JoinWithCaller(err, op)
 }
 
-func (a App) AddAd(ctx context.Context, linkID int64, adID int64, authorID int64) (links.Link, error) {
+// SetPassword protects the link by password. Empty password removes the protection
+func (a App) SetPassword(ctx context.Context, id int64, authorID int64, password string) (links.Link, error) {
+	const op = "app.SetPassword"
+	link, err := a.getEditable(ctx, id, authorID, permissions.EditAny)
+	if err != nil {
+		return link, errwrap.JoinWithCaller(err, op)
+	}
+	hash := ""
+	if password != "" {
+		hash, err = a.Hasher.Generate(ctx, password)
+		if err != nil {
+			return link, errwrap.JoinWithCaller(err, op)
+		}
+	}
+	now := time.Now().UTC()
+	err = a.Repo.UpdatePassword(ctx, id, hash, now)
+	if err == nil {
+		link.PasswordHash, link.UpdateDate = hash, now
+	}
+	return link, errwrap.JoinWithCaller(err, op)
+}
+
+// AddAd adds the ad with the weight to the link and changes ad selection strategy of the link.
+// Zero weight means links.DefaultAdWeight and empty strategy keeps the current one. Weight of already added ad
+// is changed only if it is set explicitly
+func (a App) AddAd(
+	ctx context.Context,
+	linkID int64,
+	adID int64,
+	authorID int64,
+	weight int,
+	strategy string,
+) (links.Link, error) {
 	const op = "app.AddAd"
-	link, err := a.getEditable(ctx, linkID, authorID)
+	link, err := a.getEditable(ctx, linkID, authorID, permissions.EditAny)
 	if err != nil {
 		return link, errwrap.JoinWithCaller(err, op)
 	}
+	added := false
 	for i := range link.Ads {
 		if link.Ads[i] == adID {
-			return link, errwrap.New(ErrAdAlreadyAdded, ServiceName, op).
-				OnObject("link", linkID).
-				WithDetails(fmt.Sprintf("ad ID: %d", adID))
+			added = true
+			break
 		}
 	}
-	err = a.Repo.AddAd(ctx, linkID, adID)
-	if err == nil {
+	if added && weight == 0 && strategy == "" {
+		return link, errwrap.New(ErrAdAlreadyAdded, ServiceName, op).
+			OnObject("link", linkID).
+			WithDetails(fmt.Sprintf("ad ID: %d", adID))
+	}
+	if weight < 0 || weight > links.MaxAdWeight {
+		return link, errwrap.New(ErrInvalidContent, ServiceName, op).
+			OnObject("link", linkID).
+			WithDetails(fmt.Sprintf("weight %d is out of [1, %d]", weight, links.MaxAdWeight))
+	}
+	if strategy != "" {
+		if err = a.checkStrategy(strategy); err != nil {
+			return link, errwrap.JoinWithCaller(err, op)
+		}
+		now := time.Now().UTC()
+		if err = a.Repo.UpdateStrategy(ctx, linkID, strategy, now); err != nil {
+			return link, errwrap.JoinWithCaller(err, op)
+		}
+		link.Strategy, link.UpdateDate = strategy, now
+	}
+	if added && weight == 0 {
+		return link, nil
+	}
+	if weight == 0 {
+		weight = links.DefaultAdWeight
+	}
+	err = a.Repo.AddAd(ctx, linkID, adID, weight)
+	if err != nil {
+		return link, errwrap.JoinWithCaller(err, op)
+	}
+	if !added {
 		link.Ads = append(link.Ads, adID)
 	}
-	return link, errwrap.JoinWithCaller(err, op)
+	if link.AdWeights == nil {
+		link.AdWeights = make(map[int64]int)
+	}
+	link.AdWeights[adID] = weight
+	return link, nil
+}
+
+// ClickAd records the click of the visitor with the IP address on the ad shown on redirect of the link.
+// The impression token is issued by GetRedirect only after the link's password is checked, so it is not asked
+// again. Repeated clicks of the impression or of the visitor within ClickWindow are ignored
+func (a App) ClickAd(ctx context.Context, alias string, adID int64, impression string, ip string) error {
+	const op = "app.ClickAd"
+	link, err := a.GetByAlias(ctx, alias)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	now := time.Now().UTC()
+	if err := a.checkAvailable(link, now); err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	shown, err := a.Impressions.Parse(ctx, impression, ip)
+	if err != nil {
+		return errwrap.JoinWithCaller(err, op)
+	}
+	if shown.LinkID != link.ID || shown.AdID != adID || shown.IsExpired(now) {
+		return errwrap.New(ErrInvalidImpression, ServiceName, op).
+			OnObject("link", link.ID).
+			WithDetails(fmt.Sprintf("ad ID: %d, impression of link %d and ad %d", adID, shown.LinkID, shown.AdID))
+	}
+	for i := range link.Ads {
+		if link.Ads[i] == adID {
+			err := a.Repo.StoreAdClick(ctx, clicks.AdClick{
+				LinkID:     link.ID,
+				AdID:       adID,
+				Impression: shown.ID,
+				Visitor:    shown.Visitor,
+				Time:       now,
+			}, now.Add(-a.ClickWindow))
+			return errwrap.JoinWithCaller(err, op)
+		}
+	}
+	return errwrap.New(ErrAdNotExists, ServiceName, op).
+		OnObject("link", link.ID).
+		WithDetails(fmt.Sprintf("ad ID: %d", adID))
 }
 
 func (a App) DeleteAd(ctx context.Context, linkID int64, adID int64, authorID int64) (links.Link, error) {
 	const op = "app.DeleteAd"
-	link, err := a.getEditable(ctx, linkID, authorID)
+	link, err := a.getEditable(ctx, linkID, authorID, permissions.EditAny)
 	if err != nil {
 		return link, errwrap.JoinWithCaller(err, op)
 	}
@@ -185,23 +553,47 @@ func (a App) DeleteAd(ctx context.Context, linkID int64, adID int64, authorID in
 	err = a.Repo.DeleteAd(ctx, linkID, adID)
 	if err == nil {
 		link.Ads = append(link.Ads[:adIdx], link.Ads[adIdx+1:]...)
+		delete(link.AdWeights, adID)
 	}
 	return link, errwrap.JoinWithCaller(err, op)
 }
 
 func (a App) Delete(ctx context.Context, id int64, authorID int64) error {
 	const op = "app.Delete"
-	_, err := a.getEditable(ctx, id, authorID)
+	_, err := a.getEditable(ctx, id, authorID, permissions.DeleteAny)
 	if err != nil {
 		return errwrap.JoinWithCaller(err, op)
 	}
 	return a.Repo.Delete(ctx, id)
 }
 
-func New(repo Repository, generator Generator, ads AdsService) App {
+// DeleteUserData removes links of the deleted user. It is called on user events from Auth
+func (a App) DeleteUserData(ctx context.Context, userID int64) error {
+	const op = "app.DeleteUserData"
+	err := a.Repo.DeleteByAuthor(ctx, userID)
+	return errwrap.JoinWithCaller(err, op)
+}
+
+func New(
+	repo Repository,
+	generator Generator,
+	ads AdsService,
+	clicks ClickRecorder,
+	hasher Hasher,
+	attempts AttemptsLimiter,
+	selectors map[string]AdSelector,
+	impressions ImpressionSigner,
+	clickWindow time.Duration,
+) App {
 	return App{
-		Repo: repo,
-		Gen:  generator,
-		Ads:  ads,
+		Repo:        repo,
+		Gen:         generator,
+		Ads:         ads,
+		Clicks:      clicks,
+		Hasher:      hasher,
+		Attempts:    attempts,
+		Selectors:   selectors,
+		Impressions: impressions,
+		ClickWindow: clickWindow,
 	}
 }
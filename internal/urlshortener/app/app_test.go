@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"goads/internal/pkg/pagination"
+	"goads/internal/pkg/permissions"
 	"goads/internal/urlshortener/app/mocks"
 	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/clicks"
 	"goads/internal/urlshortener/entities/links"
 	"goads/internal/urlshortener/entities/redirects"
 	"testing"
+	"time"
 )
 
 func storeRepo(t *testing.T) Repository {
@@ -29,7 +33,7 @@ func adsService(t *testing.T) AdsService {
 	a := mocks.NewAdsService(t)
 	a.
 		On("GetOnlyPublished", mock.Anything, mock.AnythingOfType("[]int64")).
-		Return(func(_ context.Context, ids []int64) ([]ads.Ad, error) {
+		Return(func(_ context.Context, ids []int64) ([]ads.Ad, []int64, error) {
 			if len(ids) == 3 {
 				ids = []int64{ids[0], ids[2]}
 			}
@@ -41,11 +45,58 @@ func adsService(t *testing.T) AdsService {
 					Text:  fmt.Sprintf("test text %d", i),
 				}
 			}
-			return res, nil
+			return res, nil, nil
+		})
+	a.
+		On("CountImpression", mock.Anything, mock.AnythingOfType("int64")).
+		Return(nil).Maybe()
+	return a
+}
+
+// exhaustedAdsService returns ads 1 and 3, where campaign of the ad 1 is exhausted
+func exhaustedAdsService(t *testing.T) AdsService {
+	a := mocks.NewAdsService(t)
+	a.
+		On("GetOnlyPublished", mock.Anything, mock.AnythingOfType("[]int64")).
+		Return([]ads.Ad{{ID: 1}, {ID: 3}}, nil, nil)
+	a.
+		On("CountImpression", mock.Anything, mock.AnythingOfType("int64")).
+		Return(func(_ context.Context, id int64) error {
+			if id == 1 {
+				return ErrNoAds
+			}
+			return nil
 		})
 	return a
 }
 
+func clickRecorder(t *testing.T) ClickRecorder {
+	c := mocks.NewClickRecorder(t)
+	c.
+		On("Record", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64"), mock.AnythingOfType("clicks.Visitor")).
+		Return(nil)
+	return c
+}
+
+func impressionSigner(t *testing.T) ImpressionSigner {
+	s := mocks.NewImpressionSigner(t)
+	s.
+		On("Issue", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64"), mock.AnythingOfType("string")).
+		Return("impression", nil)
+	return s
+}
+
+func getByIDGetStatsRepo(t *testing.T) Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByID", mock.Anything, mock.AnythingOfType("int64")).
+		Return(links.Link{URL: "https://github.com", Alias: "github"}, nil)
+	r.
+		On("GetStats", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(clicks.Stats{Total: 1, ByAd: []clicks.AdStat{{AdID: 1, Count: 1}}}, nil)
+	return r
+}
+
 func notFoundByAliasRepo(t *testing.T) Repository {
 	r := mocks.NewRepository(t)
 	r.
@@ -62,6 +113,51 @@ func getByAliasRepo(t *testing.T, adIDs []int64) Repository {
 	return r
 }
 
+func getScheduledByAliasRepo(t *testing.T, activeFrom time.Time, expiresAt time.Time) Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByAlias", mock.Anything, mock.AnythingOfType("string")).
+		Return(links.Link{ActiveFrom: activeFrom, ExpiresAt: expiresAt}, nil)
+	return r
+}
+
+func getProtectedByAliasRepo(t *testing.T) Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByAlias", mock.Anything, mock.AnythingOfType("string")).
+		Return(links.Link{Alias: "secret", PasswordHash: "hash"}, nil)
+	return r
+}
+
+func hasher(t *testing.T) Hasher {
+	h := mocks.NewHasher(t)
+	h.
+		On("Compare", mock.Anything, "hash", mock.AnythingOfType("string")).
+		Return(func(_ context.Context, _ string, password string) error {
+			if password != "password" {
+				return ErrWrongPassword
+			}
+			return nil
+		}).Maybe()
+	h.
+		On("Generate", mock.Anything, mock.AnythingOfType("string")).
+		Return("hash", nil).Maybe()
+	return h
+}
+
+func attemptsLimiter(t *testing.T, allowed bool, failed bool) AttemptsLimiter {
+	l := mocks.NewAttemptsLimiter(t)
+	l.
+		On("Allowed", mock.Anything, mock.AnythingOfType("string")).
+		Return(allowed, nil)
+	if failed {
+		l.
+			On("Failed", mock.Anything, mock.AnythingOfType("string")).
+			Return(nil)
+	}
+	return l
+}
+
 func storeGetByAliasRepo(t *testing.T) Repository {
 	r := mocks.NewRepository(t)
 	r.
@@ -86,8 +182,9 @@ func getByIDUpdateAliasGetByAliasRepo(t *testing.T) Repository {
 		Return(links.Link{URL: "https://github.com", Alias: "github"}, nil)
 
 	r.
-		On("UpdateAlias", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string")).
-		Return(func(_ context.Context, id int64, alias string) error {
+		On("UpdateAlias", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string"),
+			mock.AnythingOfType("time.Time")).
+		Return(func(_ context.Context, id int64, alias string, _ time.Time) error {
 			if alias == "already-exists" {
 				return ErrAlreadyExists
 			}
@@ -107,8 +204,9 @@ func getByIDUpdateAliasRepo(t *testing.T) Repository {
 		Return(links.Link{URL: "https://github.com", Alias: "github"}, nil)
 
 	r.
-		On("UpdateAlias", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string")).
-		Return(func(_ context.Context, id int64, alias string) error {
+		On("UpdateAlias", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string"),
+			mock.AnythingOfType("time.Time")).
+		Return(func(_ context.Context, id int64, alias string, _ time.Time) error {
 			if alias == "already-exists" {
 				return ErrAlreadyExists
 			}
@@ -118,6 +216,32 @@ func getByIDUpdateAliasRepo(t *testing.T) Repository {
 	return r
 }
 
+func getByIDUpdateURLRepo(t *testing.T) Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByID", mock.Anything, mock.AnythingOfType("int64")).
+		Return(links.Link{URL: "https://github.com", Alias: "github"}, nil)
+
+	r.
+		On("UpdateURL", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string"),
+			mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).
+		Return(nil)
+	return r
+}
+
+func getByIDUpdateAdDelayRepo(t *testing.T) Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByID", mock.Anything, mock.AnythingOfType("int64")).
+		Return(links.Link{URL: "https://github.com", Alias: "github", AdDelay: links.DefaultAdDelay}, nil)
+
+	r.
+		On("UpdateAdDelay", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int"),
+			mock.AnythingOfType("time.Time")).
+		Return(nil)
+	return r
+}
+
 func getByIDRepo(t *testing.T) Repository {
 	r := mocks.NewRepository(t)
 	r.
@@ -145,8 +269,8 @@ func getByIDAddAdRepo(t *testing.T) Repository {
 		On("GetByID", mock.Anything, mock.AnythingOfType("int64")).
 		Return(links.Link{Ads: []int64{1, 2, 3}, URL: "https://github.com", Alias: "github"}, nil)
 	r.
-		On("AddAd", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
-		Return(func(_ context.Context, link int64, ad int64) error {
+		On("AddAd", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64"), mock.AnythingOfType("int")).
+		Return(func(_ context.Context, link int64, ad int64, weight int) error {
 			if ad == -1 {
 				return ErrNotFound
 			}
@@ -155,6 +279,31 @@ func getByIDAddAdRepo(t *testing.T) Repository {
 	return r
 }
 
+func getByIDAddAdUpdateStrategyRepo(t *testing.T) Repository {
+	r := getByIDAddAdRepo(t).(*mocks.Repository)
+	r.
+		On("UpdateStrategy", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("string"),
+			mock.AnythingOfType("time.Time")).
+		Return(nil)
+	return r
+}
+
+func getByAliasSelectRepo(t *testing.T) Repository {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByAlias", mock.Anything, mock.AnythingOfType("string")).
+		Return(links.Link{Ads: []int64{1, 2, 3}, Strategy: links.StrategyWeighted}, nil)
+	return r
+}
+
+func adSelector(t *testing.T, adID int64) AdSelector {
+	s := mocks.NewAdSelector(t)
+	s.
+		On("Select", mock.Anything, mock.AnythingOfType("links.Link"), mock.AnythingOfType("[]ads.Ad")).
+		Return(ads.Ad{ID: adID}, nil)
+	return s
+}
+
 func getByIDDeleteAdRepo(t *testing.T) Repository {
 	r := mocks.NewRepository(t)
 	r.
@@ -173,14 +322,17 @@ func getByIDDeleteAdRepo(t *testing.T) Repository {
 
 func TestApp_AddAd(t *testing.T) {
 	type fields struct {
-		repo Repository
-		gen  Generator
+		repo      Repository
+		gen       Generator
+		selectors map[string]AdSelector
 	}
 	type args struct {
 		ctx      context.Context
 		linkID   int64
 		adID     int64
 		authorID int64
+		weight   int
+		strategy string
 	}
 	tests := [...]struct {
 		name    string
@@ -201,11 +353,70 @@ func TestApp_AddAd(t *testing.T) {
 				authorID: 0,
 			},
 			want: links.Link{
-				ID:       0,
-				URL:      "https://github.com",
-				Alias:    "github",
-				AuthorID: 0,
-				Ads:      []int64{1, 2, 3, 0},
+				ID:        0,
+				URL:       "https://github.com",
+				Alias:     "github",
+				AuthorID:  0,
+				Ads:       []int64{1, 2, 3, 0},
+				AdWeights: map[int64]int{0: links.DefaultAdWeight},
+			},
+		},
+		{
+			name: "correct changing weight and strategy",
+			fields: fields{
+				repo:      getByIDAddAdUpdateStrategyRepo(t),
+				selectors: map[string]AdSelector{links.StrategyRoundRobin: mocks.NewAdSelector(t)},
+			},
+			args: args{
+				ctx:      context.Background(),
+				adID:     1,
+				weight:   10,
+				strategy: links.StrategyRoundRobin,
+			},
+			want: links.Link{
+				URL:       "https://github.com",
+				Alias:     "github",
+				Ads:       []int64{1, 2, 3},
+				AdWeights: map[int64]int{1: 10},
+				Strategy:  links.StrategyRoundRobin,
+			},
+		},
+		{
+			name: "unknown strategy",
+			fields: fields{
+				repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:      context.Background(),
+				adID:     4,
+				strategy: "unknown",
+			},
+			want: links.Link{
+				URL:   "https://github.com",
+				Alias: "github",
+				Ads:   []int64{1, 2, 3},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "invalid weight",
+			fields: fields{
+				repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:    context.Background(),
+				adID:   4,
+				weight: links.MaxAdWeight + 1,
+			},
+			want: links.Link{
+				URL:   "https://github.com",
+				Alias: "github",
+				Ads:   []int64{1, 2, 3},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
 			},
 		},
 		{
@@ -271,31 +482,40 @@ func TestApp_AddAd(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := App{
-				Repo: tt.fields.repo,
-				Gen:  tt.fields.gen,
+				Repo:      tt.fields.repo,
+				Gen:       tt.fields.gen,
+				Selectors: tt.fields.selectors,
 			}
-			got, err := a.AddAd(tt.args.ctx, tt.args.linkID, tt.args.adID, tt.args.authorID)
-			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("AddAd(%v, %v, %v, %v)", tt.args.ctx, tt.args.linkID, tt.args.adID, tt.args.authorID)) {
+			got, err := a.AddAd(tt.args.ctx, tt.args.linkID, tt.args.adID, tt.args.authorID, tt.args.weight, tt.args.strategy)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("AddAd(%v, %v, %v, %v, %v, %v)", tt.args.ctx, tt.args.linkID, tt.args.adID, tt.args.authorID, tt.args.weight, tt.args.strategy)) {
 				return
 			} else if tt.wantErr == nil {
 				assert.NoError(t, err)
 			}
-			assert.Equalf(t, tt.want, got, "AddAd(%v, %v, %v, %v)", tt.args.ctx, tt.args.linkID, tt.args.adID, tt.args.authorID)
+			// dates of the link are set by the time of the call
+			got.CreateDate, got.UpdateDate = time.Time{}, time.Time{}
+			assert.Equalf(t, tt.want, got, "AddAd(%v, %v, %v, %v, %v, %v)", tt.args.ctx, tt.args.linkID, tt.args.adID, tt.args.authorID, tt.args.weight, tt.args.strategy)
 		})
 	}
 }
 
 func TestApp_Create(t *testing.T) {
 	type fields struct {
-		repo Repository
-		gen  Generator
+		repo   Repository
+		gen    Generator
+		hasher Hasher
 	}
 	type args struct {
-		ctx      context.Context
-		url      string
-		alias    string
-		authorID int64
-		ads      []int64
+		ctx        context.Context
+		url        string
+		alias      string
+		authorID   int64
+		ads        []int64
+		adDelay    int
+		activeFrom time.Time
+		expiresAt  time.Time
+		password   string
+		strategy   string
 	}
 	tests := [...]struct {
 		name    string
@@ -320,6 +540,7 @@ func TestApp_Create(t *testing.T) {
 				Alias:    "test",
 				AuthorID: 0,
 				Ads:      nil,
+				Strategy: links.StrategyRandom,
 			},
 		},
 		{
@@ -338,6 +559,26 @@ func TestApp_Create(t *testing.T) {
 				Alias:    "my-alias",
 				AuthorID: 0,
 				Ads:      nil,
+				Strategy: links.StrategyRandom,
+			},
+		},
+		{
+			name: "correct creating with password",
+			fields: fields{
+				repo:   storeRepo(t),
+				hasher: hasher(t),
+			},
+			args: args{
+				ctx:      context.Background(),
+				url:      "https://github.com",
+				alias:    "my-alias",
+				password: "password",
+			},
+			want: links.Link{
+				URL:          "https://github.com",
+				Alias:        "my-alias",
+				PasswordHash: "hash",
+				Strategy:     links.StrategyRandom,
 			},
 		},
 		{
@@ -356,6 +597,7 @@ func TestApp_Create(t *testing.T) {
 				Alias:    "test",
 				AuthorID: 0,
 				Ads:      nil,
+				Strategy: links.StrategyRandom,
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.ErrorIs(t, err, ErrAlreadyExists, i)
@@ -377,6 +619,74 @@ func TestApp_Create(t *testing.T) {
 				Alias:    "test",
 				AuthorID: 0,
 				Ads:      nil,
+				Strategy: links.StrategyRandom,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "invalid ad delay",
+			fields: fields{
+				repo: mocks.NewRepository(t),
+			},
+			args: args{
+				ctx:     context.Background(),
+				url:     "https://github.com",
+				alias:   "my-alias",
+				adDelay: -1,
+			},
+			want: links.Link{
+				ID:       0,
+				URL:      "https://github.com",
+				Alias:    "my-alias",
+				AuthorID: 0,
+				Ads:      nil,
+				AdDelay:  -1,
+				Strategy: links.StrategyRandom,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "unknown strategy",
+			fields: fields{
+				repo: mocks.NewRepository(t),
+			},
+			args: args{
+				ctx:      context.Background(),
+				url:      "https://github.com",
+				alias:    "my-alias",
+				strategy: "unknown",
+			},
+			want: links.Link{
+				URL:      "https://github.com",
+				Alias:    "my-alias",
+				Strategy: "unknown",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "expires before activation",
+			fields: fields{
+				repo: mocks.NewRepository(t),
+			},
+			args: args{
+				ctx:        context.Background(),
+				url:        "https://github.com",
+				alias:      "my-alias",
+				activeFrom: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				expiresAt:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: links.Link{
+				URL:        "https://github.com",
+				Alias:      "my-alias",
+				ActiveFrom: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				ExpiresAt:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				Strategy:   links.StrategyRandom,
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.ErrorIs(t, err, ErrInvalidContent, i)
@@ -386,16 +696,19 @@ func TestApp_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := App{
-				Repo: tt.fields.repo,
-				Gen:  tt.fields.gen,
+				Repo:   tt.fields.repo,
+				Gen:    tt.fields.gen,
+				Hasher: tt.fields.hasher,
 			}
-			got, err := a.Create(tt.args.ctx, tt.args.url, tt.args.alias, tt.args.authorID, tt.args.ads)
-			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("Create(%v, %v, %v, %v, %v)", tt.args.ctx, tt.args.url, tt.args.alias, tt.args.authorID, tt.args.ads)) {
+			got, err := a.Create(tt.args.ctx, tt.args.url, tt.args.alias, tt.args.authorID, tt.args.ads, tt.args.adDelay, tt.args.activeFrom, tt.args.expiresAt, tt.args.password, tt.args.strategy)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("Create(%v, %v, %v, %v, %v, %v, %v, %v, %v, %v)", tt.args.ctx, tt.args.url, tt.args.alias, tt.args.authorID, tt.args.ads, tt.args.adDelay, tt.args.activeFrom, tt.args.expiresAt, tt.args.password, tt.args.strategy)) {
 				return
 			} else if tt.wantErr == nil {
 				assert.NoError(t, err)
 			}
-			assert.Equalf(t, tt.want, got, "Create(%v, %v, %v, %v, %v)", tt.args.ctx, tt.args.url, tt.args.alias, tt.args.authorID, tt.args.ads)
+			// dates of the link are set by the time of the call
+			got.CreateDate, got.UpdateDate = time.Time{}, time.Time{}
+			assert.Equalf(t, tt.want, got, "Create(%v, %v, %v, %v, %v, %v, %v, %v, %v, %v)", tt.args.ctx, tt.args.url, tt.args.alias, tt.args.authorID, tt.args.ads, tt.args.adDelay, tt.args.activeFrom, tt.args.expiresAt, tt.args.password, tt.args.strategy)
 		})
 	}
 }
@@ -536,6 +849,26 @@ func TestApp_DeleteAd(t *testing.T) {
 	}
 }
 
+func TestApp_GetByAuthor(t *testing.T) {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByAuthor", mock.Anything, int64(1), pagination.Page{Size: pagination.DefaultSize, Sort: "alias"}).
+		Return([]links.Link{{ID: 2, Alias: "a"}}, pagination.Info{NextToken: "next"}, nil).
+		Once()
+	a := App{Repo: r}
+	ctx := context.Background()
+
+	list, info, err := a.GetByAuthor(ctx, 1, pagination.Page{Sort: "alias"})
+	assert.NoError(t, err)
+	assert.Equal(t, []links.Link{{ID: 2, Alias: "a"}}, list)
+	assert.Equal(t, pagination.Info{NextToken: "next"}, info)
+
+	_, _, err = a.GetByAuthor(ctx, 1, pagination.Page{Sort: pagination.SortTitle})
+	assert.ErrorIs(t, err, ErrInvalidContent)
+	_, _, err = a.GetByAuthor(ctx, 1, pagination.Page{Token: "not a token"})
+	assert.ErrorIs(t, err, ErrInvalidContent)
+}
+
 func TestApp_UpdateAlias(t *testing.T) {
 	type fields struct {
 		repo Repository
@@ -604,6 +937,24 @@ func TestApp_UpdateAlias(t *testing.T) {
 				return assert.ErrorIs(t, err, ErrNotFound, i)
 			},
 		},
+		{
+			name: "admin updates any link",
+			fields: fields{
+				repo: getByIDUpdateAliasRepo(t),
+			},
+			args: args{
+				authorID: 1,
+				ctx:      permissions.WithRole(context.Background(), permissions.RoleAdmin),
+				alias:    "my-alias",
+			},
+			want: links.Link{
+				ID:       0,
+				URL:      "https://github.com",
+				Alias:    "my-alias",
+				AuthorID: 0,
+				Ads:      nil,
+			},
+		},
 		{
 			name: "correct creating without generating",
 			fields: fields{
@@ -654,20 +1005,211 @@ func TestApp_UpdateAlias(t *testing.T) {
 			} else if tt.wantErr == nil {
 				assert.NoError(t, err)
 			}
+			// dates of the link are set by the time of the call
+			got.CreateDate, got.UpdateDate = time.Time{}, time.Time{}
 			assert.Equalf(t, tt.want, got, "UpdateAlias(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.alias)
 		})
 	}
 }
 
+func TestApp_UpdateURL(t *testing.T) {
+	type args struct {
+		ctx      context.Context
+		id       int64
+		authorID int64
+		url      string
+	}
+	tests := [...]struct {
+		name    string
+		repo    Repository
+		args    args
+		want    links.Link
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "correct updating",
+			repo: getByIDUpdateURLRepo(t),
+			args: args{
+				ctx: context.Background(),
+				url: "https://gitlab.com",
+			},
+			want: links.Link{
+				URL:   "https://gitlab.com",
+				Alias: "github",
+			},
+		},
+		{
+			name: "permission denied",
+			repo: getByIDRepo(t),
+			args: args{
+				ctx:      context.Background(),
+				authorID: 1,
+				url:      "https://gitlab.com",
+			},
+			want: links.Link{
+				URL:   "https://github.com",
+				Alias: "github",
+				Ads:   []int64{1, 2, 3},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrPermissionDenied, i)
+			},
+		},
+		{
+			name: "invalid url",
+			repo: getByIDRepo(t),
+			args: args{
+				ctx: context.Background(),
+				url: "",
+			},
+			want: links.Link{
+				URL:   "https://github.com",
+				Alias: "github",
+				Ads:   []int64{1, 2, 3},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := App{
+				Repo: tt.repo,
+			}
+			got, err := a.UpdateURL(tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.url)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("UpdateURL(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.url)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			// dates of the link are set by the time of the call
+			got.CreateDate, got.UpdateDate = time.Time{}, time.Time{}
+			assert.Equalf(t, tt.want, got, "UpdateURL(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.url)
+		})
+	}
+}
+
+func TestApp_UpdateURL_Editor(t *testing.T) {
+	admin := permissions.WithRole(context.Background(), permissions.RoleAdmin)
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByID", mock.Anything, int64(1)).
+		Return(links.Link{ID: 1, URL: "https://github.com", Alias: "github", AuthorID: 2}, nil)
+	r.
+		On("UpdateURL", mock.Anything, int64(1), "https://gitlab.com", int64(3), mock.AnythingOfType("time.Time")).
+		Return(nil).
+		Once()
+	a := App{Repo: r}
+
+	_, err := a.UpdateURL(admin, 1, 3, "https://gitlab.com")
+	assert.NoError(t, err, "history must record the admin who changed the link of other user")
+}
+
+func TestApp_UpdateAdDelay(t *testing.T) {
+	type fields struct {
+		repo Repository
+	}
+	type args struct {
+		ctx      context.Context
+		id       int64
+		authorID int64
+		adDelay  int
+	}
+	tests := [...]struct {
+		name    string
+		fields  fields
+		args    args
+		want    links.Link
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "correct updating",
+			fields: fields{
+				repo: getByIDUpdateAdDelayRepo(t),
+			},
+			args: args{
+				ctx:     context.Background(),
+				adDelay: 10,
+			},
+			want: links.Link{
+				URL:     "https://github.com",
+				Alias:   "github",
+				AdDelay: 10,
+			},
+		},
+		{
+			name: "too long delay",
+			fields: fields{
+				repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:     context.Background(),
+				adDelay: 61,
+			},
+			want: links.Link{
+				URL:   "https://github.com",
+				Alias: "github",
+				Ads:   []int64{1, 2, 3},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+		{
+			name: "permission denied",
+			fields: fields{
+				repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:      context.Background(),
+				authorID: 1,
+				adDelay:  10,
+			},
+			want: links.Link{
+				URL:   "https://github.com",
+				Alias: "github",
+				Ads:   []int64{1, 2, 3},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrPermissionDenied, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := App{
+				Repo: tt.fields.repo,
+			}
+			got, err := a.UpdateAdDelay(tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.adDelay)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("UpdateAdDelay(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.adDelay)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			// dates of the link are set by the time of the call
+			got.CreateDate, got.UpdateDate = time.Time{}, time.Time{}
+			assert.Equalf(t, tt.want, got, "UpdateAdDelay(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.authorID, tt.args.adDelay)
+		})
+	}
+}
+
 func TestApp_GetRedirect(t *testing.T) {
 	type fields struct {
-		Repo Repository
-		Gen  Generator
-		Ads  AdsService
+		Repo        Repository
+		Gen         Generator
+		Ads         AdsService
+		Clicks      ClickRecorder
+		Hasher      Hasher
+		Attempts    AttemptsLimiter
+		Selectors   map[string]AdSelector
+		Impressions ImpressionSigner
 	}
 	type args struct {
-		ctx   context.Context
-		alias string
+		ctx      context.Context
+		alias    string
+		visitor  clicks.Visitor
+		password string
 	}
 	tests := []struct {
 		name    string
@@ -679,8 +1221,10 @@ func TestApp_GetRedirect(t *testing.T) {
 		{
 			name: "correct redirecting",
 			fields: fields{
-				Repo: getByAliasRepo(t, []int64{1, 2, 3}),
-				Ads:  adsService(t),
+				Repo:        getByAliasRepo(t, []int64{1, 2, 3}),
+				Ads:         adsService(t),
+				Clicks:      clickRecorder(t),
+				Impressions: impressionSigner(t),
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -690,14 +1234,47 @@ func TestApp_GetRedirect(t *testing.T) {
 				if redirect.Ad.ID != 1 && redirect.Ad.ID != 3 {
 					t.Errorf("incorrect ad ID. Expected 1 or 3, got: %d", redirect.Ad.ID)
 				}
+				assert.Equal(t, "impression", redirect.Impression)
 			},
 			wantErr: nil,
 		},
+		{
+			name: "selecting by strategy",
+			fields: fields{
+				Repo:        getByAliasSelectRepo(t),
+				Ads:         adsService(t),
+				Clicks:      clickRecorder(t),
+				Selectors:   map[string]AdSelector{links.StrategyWeighted: adSelector(t, 3)},
+				Impressions: impressionSigner(t),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Equal(t, int64(3), redirect.Ad.ID)
+			},
+		},
+		{
+			name: "exhausted ad is not shown",
+			fields: fields{
+				Repo:        getByAliasRepo(t, []int64{1, 2, 3}),
+				Ads:         exhaustedAdsService(t),
+				Clicks:      clickRecorder(t),
+				Impressions: impressionSigner(t),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Equal(t, int64(3), redirect.Ad.ID)
+			},
+		},
 		{
 			name: "ad not found",
 			fields: fields{
-				Repo: getByAliasRepo(t, []int64{}),
-				Ads:  adsService(t),
+				Repo:   getByAliasRepo(t, []int64{}),
+				Ads:    adsService(t),
+				Clicks: clickRecorder(t),
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -705,19 +1282,122 @@ func TestApp_GetRedirect(t *testing.T) {
 			},
 			want: func(t assert.TestingT, redirect redirects.Redirect) {
 				assert.Empty(t, redirect.Ad)
+				assert.Empty(t, redirect.Impression, "no token without the shown ad")
 			},
 			wantErr: nil,
 		},
+		{
+			name: "not active yet",
+			fields: fields{
+				Repo: getScheduledByAliasRepo(t, time.Now().Add(time.Hour), time.Time{}),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Empty(t, redirect)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNotActive, i)
+			},
+		},
+		{
+			name: "expired",
+			fields: fields{
+				Repo: getScheduledByAliasRepo(t, time.Now().Add(-time.Hour), time.Now().Add(-time.Minute)),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Empty(t, redirect)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrExpired, i)
+			},
+		},
+		{
+			name: "correct password",
+			fields: fields{
+				Repo:     getProtectedByAliasRepo(t),
+				Ads:      adsService(t),
+				Clicks:   clickRecorder(t),
+				Hasher:   hasher(t),
+				Attempts: attemptsLimiter(t, true, false),
+			},
+			args: args{
+				ctx:      context.Background(),
+				password: "password",
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Equal(t, "secret", redirect.Link.Alias)
+			},
+		},
+		{
+			name: "password required",
+			fields: fields{
+				Repo: getProtectedByAliasRepo(t),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Empty(t, redirect)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrPasswordRequired, i)
+			},
+		},
+		{
+			name: "wrong password",
+			fields: fields{
+				Repo:     getProtectedByAliasRepo(t),
+				Hasher:   hasher(t),
+				Attempts: attemptsLimiter(t, true, true),
+			},
+			args: args{
+				ctx:      context.Background(),
+				password: "wrong",
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Empty(t, redirect)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrWrongPassword, i)
+			},
+		},
+		{
+			name: "too many attempts",
+			fields: fields{
+				Repo:     getProtectedByAliasRepo(t),
+				Attempts: attemptsLimiter(t, false, false),
+			},
+			args: args{
+				ctx:      context.Background(),
+				password: "password",
+			},
+			want: func(t assert.TestingT, redirect redirects.Redirect) {
+				assert.Empty(t, redirect)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrTooManyAttempts, i)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := App{
-				Repo: tt.fields.Repo,
-				Gen:  tt.fields.Gen,
-				Ads:  tt.fields.Ads,
+				Repo:        tt.fields.Repo,
+				Gen:         tt.fields.Gen,
+				Ads:         tt.fields.Ads,
+				Clicks:      tt.fields.Clicks,
+				Hasher:      tt.fields.Hasher,
+				Attempts:    tt.fields.Attempts,
+				Selectors:   tt.fields.Selectors,
+				Impressions: tt.fields.Impressions,
 			}
-			got, err := a.GetRedirect(tt.args.ctx, tt.args.alias)
-			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("GetRedirect(%v, %v)", tt.args.ctx, tt.args.alias)) {
+			got, err := a.GetRedirect(tt.args.ctx, tt.args.alias, tt.args.visitor, tt.args.password)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("GetRedirect(%v, %v, %v, %v)", tt.args.ctx, tt.args.alias, tt.args.visitor, tt.args.password)) {
 				return
 			} else if tt.wantErr == nil {
 				assert.NoError(t, err)
@@ -726,3 +1406,153 @@ func TestApp_GetRedirect(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_GetRedirect_DeletedAds(t *testing.T) {
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByAlias", mock.Anything, "github").
+		Return(links.Link{ID: 1, Ads: []int64{1, 2, 3}}, nil)
+	r.
+		On("DeleteAd", mock.Anything, int64(1), int64(2)).
+		Return(nil).
+		Once()
+	r.
+		On("DeleteAd", mock.Anything, int64(1), int64(3)).
+		Return(assert.AnError).
+		Once()
+	s := mocks.NewAdsService(t)
+	s.
+		On("GetOnlyPublished", mock.Anything, []int64{1, 2, 3}).
+		Return([]ads.Ad{{ID: 1}}, []int64{2, 3}, nil)
+	s.
+		On("CountImpression", mock.Anything, int64(1)).
+		Return(nil)
+	a := App{Repo: r, Ads: s, Clicks: clickRecorder(t), Impressions: impressionSigner(t)}
+
+	redirect, err := a.GetRedirect(context.Background(), "github", clicks.Visitor{}, "")
+	assert.NoError(t, err, "failed cleanup of deleted ads must not break the redirect")
+	assert.Equal(t, int64(1), redirect.Ad.ID)
+}
+
+func TestApp_GetStats(t *testing.T) {
+	type fields struct {
+		repo Repository
+	}
+	type args struct {
+		ctx      context.Context
+		linkID   int64
+		authorID int64
+		from     time.Time
+		to       time.Time
+	}
+	tests := [...]struct {
+		name    string
+		fields  fields
+		args    args
+		want    clicks.Stats
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "correct getting",
+			fields: fields{
+				repo: getByIDGetStatsRepo(t),
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: clicks.Stats{Total: 1, ByAd: []clicks.AdStat{{AdID: 1, Count: 1}}},
+		},
+		{
+			name: "permission denied",
+			fields: fields{
+				repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:      context.Background(),
+				authorID: 1,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrPermissionDenied, i)
+			},
+		},
+		{
+			name: "invalid period",
+			fields: fields{
+				repo: getByIDRepo(t),
+			},
+			args: args{
+				ctx:  context.Background(),
+				from: time.Now().Add(time.Hour),
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrInvalidContent, i)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := App{
+				Repo: tt.fields.repo,
+			}
+			got, err := a.GetStats(tt.args.ctx, tt.args.linkID, tt.args.authorID, tt.args.from, tt.args.to)
+			if tt.wantErr != nil && !tt.wantErr(t, err, fmt.Sprintf("GetStats(%v, %v, %v, %v, %v)", tt.args.ctx, tt.args.linkID, tt.args.authorID, tt.args.from, tt.args.to)) {
+				return
+			} else if tt.wantErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equalf(t, tt.want, got, "GetStats(%v, %v, %v, %v, %v)", tt.args.ctx, tt.args.linkID, tt.args.authorID, tt.args.from, tt.args.to)
+		})
+	}
+}
+
+func TestApp_DeleteUserData(t *testing.T) {
+	r := mocks.NewRepository(t)
+	r.On("DeleteByAuthor", mock.Anything, int64(1)).Return(nil).Once()
+	r.On("DeleteByAuthor", mock.Anything, int64(2)).Return(assert.AnError).Once()
+	a := App{Repo: r}
+
+	assert.NoError(t, a.DeleteUserData(context.Background(), 1))
+	assert.ErrorIs(t, a.DeleteUserData(context.Background(), 2), assert.AnError)
+}
+
+func TestApp_ClickAd(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().UTC().Add(time.Hour)
+	signer := mocks.NewImpressionSigner(t)
+	signer.
+		On("Parse", mock.Anything, "valid", "127.0.0.1").
+		Return(redirects.Impression{ID: "i1", LinkID: 1, AdID: 2, Visitor: "v", ExpiresAt: expires}, nil)
+	signer.
+		On("Parse", mock.Anything, "other ad", "127.0.0.1").
+		Return(redirects.Impression{ID: "i2", LinkID: 1, AdID: 3, Visitor: "v", ExpiresAt: expires}, nil)
+	signer.
+		On("Parse", mock.Anything, "expired", "127.0.0.1").
+		Return(redirects.Impression{ID: "i3", LinkID: 1, AdID: 2, Visitor: "v", ExpiresAt: time.Now().Add(-time.Second)}, nil)
+	signer.
+		On("Parse", mock.Anything, "valid", "10.0.0.1").
+		Return(redirects.Impression{}, ErrInvalidImpression)
+	r := mocks.NewRepository(t)
+	r.
+		On("GetByAlias", mock.Anything, "test").
+		Return(links.Link{ID: 1, Ads: []int64{2, 3}}, nil)
+	r.
+		On("StoreAdClick", mock.Anything, mock.MatchedBy(func(click clicks.AdClick) bool {
+			return click.LinkID == 1 && click.AdID == 2 && click.Impression == "i1" && click.Visitor == "v"
+		}), mock.AnythingOfType("time.Time")).
+		Return(func(_ context.Context, click clicks.AdClick, since time.Time) error {
+			assert.Equal(t, click.Time.Add(-time.Hour), since, "visitor's clicks are deduplicated within the window")
+			return nil
+		}).
+		Once()
+	a := App{Repo: r, Impressions: signer, ClickWindow: time.Hour}
+
+	assert.NoError(t, a.ClickAd(ctx, "test", 2, "valid", "127.0.0.1"))
+	assert.ErrorIs(t, a.ClickAd(ctx, "test", 2, "other ad", "127.0.0.1"), ErrInvalidImpression)
+	assert.ErrorIs(t, a.ClickAd(ctx, "test", 2, "expired", "127.0.0.1"), ErrInvalidImpression)
+	assert.ErrorIs(t, a.ClickAd(ctx, "test", 2, "valid", "10.0.0.1"), ErrInvalidImpression)
+
+	a.Repo = getScheduledByAliasRepo(t, time.Now().Add(-time.Hour), time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, a.ClickAd(ctx, "test", 2, "valid", "127.0.0.1"), ErrExpired, "clicks on expired links are not recorded")
+	a.Repo = getScheduledByAliasRepo(t, time.Now().Add(time.Hour), time.Time{})
+	assert.ErrorIs(t, a.ClickAd(ctx, "test", 2, "valid", "127.0.0.1"), ErrNotActive)
+}
@@ -3,13 +3,19 @@ package app
 import "errors"
 
 var (
-	ErrAdAlreadyAdded   = errors.New("ad has already been added")
-	ErrAlreadyExists    = errors.New("alias already exists")
-	ErrNotFound         = errors.New("not found")
-	ErrNoAds            = errors.New("ads not found")
-	ErrAdNotExists      = errors.New("ad does not exist")
-	ErrPermissionDenied = errors.New("permission denied")
-	ErrInvalidContent   = errors.New("invalid content")
+	ErrAdAlreadyAdded    = errors.New("ad has already been added")
+	ErrAlreadyExists     = errors.New("alias already exists")
+	ErrNotFound          = errors.New("not found")
+	ErrNoAds             = errors.New("ads not found")
+	ErrAdNotExists       = errors.New("ad does not exist")
+	ErrPermissionDenied  = errors.New("permission denied")
+	ErrInvalidContent    = errors.New("invalid content")
+	ErrNotActive         = errors.New("link is not active yet")
+	ErrExpired           = errors.New("link has expired")
+	ErrPasswordRequired  = errors.New("link is protected by password")
+	ErrWrongPassword     = errors.New("wrong link password")
+	ErrTooManyAttempts   = errors.New("too many wrong password attempts")
+	ErrInvalidImpression = errors.New("invalid ad impression")
 )
 
 const ServiceName = "URL Shortener"
@@ -15,13 +15,28 @@ type AdsService struct {
 	mock.Mock
 }
 
+// CountImpression provides a mock function with given fields: ctx, adID
+func (_m *AdsService) CountImpression(ctx context.Context, adID int64) error {
+	ret := _m.Called(ctx, adID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetOnlyPublished provides a mock function with given fields: ctx, ids
-func (_m *AdsService) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, error) {
+func (_m *AdsService) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.Ad, []int64, error) {
 	ret := _m.Called(ctx, ids)
 
 	var r0 []ads.Ad
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]ads.Ad, error)); ok {
+	var r1 []int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]ads.Ad, []int64, error)); ok {
 		return rf(ctx, ids)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, []int64) []ads.Ad); ok {
@@ -32,13 +47,21 @@ func (_m *AdsService) GetOnlyPublished(ctx context.Context, ids []int64) ([]ads.
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) []int64); ok {
 		r1 = rf(ctx, ids)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []int64) error); ok {
+		r2 = rf(ctx, ids)
+	} else {
+		r2 = ret.Error(2)
 	}
 
-	return r0, r1
+	return r0, r1, r2
 }
 
 type mockConstructorTestingTNewAdsService interface {
@@ -4,7 +4,10 @@ package mocks
 
 import (
 	context "context"
+	pagination "goads/internal/pkg/pagination"
+	clicks "goads/internal/urlshortener/entities/clicks"
 	links "goads/internal/urlshortener/entities/links"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -14,13 +17,13 @@ type Repository struct {
 	mock.Mock
 }
 
-// AddAd provides a mock function with given fields: ctx, linkID, adID
-func (_m *Repository) AddAd(ctx context.Context, linkID int64, adID int64) error {
-	ret := _m.Called(ctx, linkID, adID)
+// AddAd provides a mock function with given fields: ctx, linkID, adID, weight
+func (_m *Repository) AddAd(ctx context.Context, linkID int64, adID int64, weight int) error {
+	ret := _m.Called(ctx, linkID, adID, weight)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
-		r0 = rf(ctx, linkID, adID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int) error); ok {
+		r0 = rf(ctx, linkID, adID, weight)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -56,6 +59,20 @@ func (_m *Repository) DeleteAd(ctx context.Context, linkID int64, adID int64) er
 	return r0
 }
 
+// DeleteByAuthor provides a mock function with given fields: ctx, authorID
+func (_m *Repository) DeleteByAuthor(ctx context.Context, authorID int64) error {
+	ret := _m.Called(ctx, authorID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, authorID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetByAlias provides a mock function with given fields: ctx, alias
 func (_m *Repository) GetByAlias(ctx context.Context, alias string) (links.Link, error) {
 	ret := _m.Called(ctx, alias)
@@ -80,30 +97,37 @@ func (_m *Repository) GetByAlias(ctx context.Context, alias string) (links.Link,
 	return r0, r1
 }
 
-// GetByAuthor provides a mock function with given fields: ctx, authorID
-func (_m *Repository) GetByAuthor(ctx context.Context, authorID int64) ([]links.Link, error) {
-	ret := _m.Called(ctx, authorID)
+// GetByAuthor provides a mock function with given fields: ctx, authorID, page
+func (_m *Repository) GetByAuthor(ctx context.Context, authorID int64, page pagination.Page) ([]links.Link, pagination.Info, error) {
+	ret := _m.Called(ctx, authorID, page)
 
 	var r0 []links.Link
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]links.Link, error)); ok {
-		return rf(ctx, authorID)
+	var r1 pagination.Info
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, pagination.Page) ([]links.Link, pagination.Info, error)); ok {
+		return rf(ctx, authorID, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) []links.Link); ok {
-		r0 = rf(ctx, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, pagination.Page) []links.Link); ok {
+		r0 = rf(ctx, authorID, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]links.Link)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, authorID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, pagination.Page) pagination.Info); ok {
+		r1 = rf(ctx, authorID, page)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(pagination.Info)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, int64, pagination.Page) error); ok {
+		r2 = rf(ctx, authorID, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // GetByID provides a mock function with given fields: ctx, id
@@ -130,6 +154,30 @@ func (_m *Repository) GetByID(ctx context.Context, id int64) (links.Link, error)
 	return r0, r1
 }
 
+// GetStats provides a mock function with given fields: ctx, linkID, from, to
+func (_m *Repository) GetStats(ctx context.Context, linkID int64, from time.Time, to time.Time) (clicks.Stats, error) {
+	ret := _m.Called(ctx, linkID, from, to)
+
+	var r0 clicks.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, time.Time) (clicks.Stats, error)); ok {
+		return rf(ctx, linkID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, time.Time) clicks.Stats); ok {
+		r0 = rf(ctx, linkID, from, to)
+	} else {
+		r0 = ret.Get(0).(clicks.Stats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, linkID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Store provides a mock function with given fields: ctx, link
 func (_m *Repository) Store(ctx context.Context, link links.Link) (int64, error) {
 	ret := _m.Called(ctx, link)
@@ -154,13 +202,83 @@ func (_m *Repository) Store(ctx context.Context, link links.Link) (int64, error)
 	return r0, r1
 }
 
-// UpdateAlias provides a mock function with given fields: ctx, id, alias
-func (_m *Repository) UpdateAlias(ctx context.Context, id int64, alias string) error {
-	ret := _m.Called(ctx, id, alias)
+// StoreAdClick provides a mock function with given fields: ctx, click, since
+func (_m *Repository) StoreAdClick(ctx context.Context, click clicks.AdClick, since time.Time) error {
+	ret := _m.Called(ctx, click, since)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, clicks.AdClick, time.Time) error); ok {
+		r0 = rf(ctx, click, since)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAdDelay provides a mock function with given fields: ctx, id, adDelay, moment
+func (_m *Repository) UpdateAdDelay(ctx context.Context, id int64, adDelay int, moment time.Time) error {
+	ret := _m.Called(ctx, id, adDelay, moment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, time.Time) error); ok {
+		r0 = rf(ctx, id, adDelay, moment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlias provides a mock function with given fields: ctx, id, alias, moment
+func (_m *Repository) UpdateAlias(ctx context.Context, id int64, alias string, moment time.Time) error {
+	ret := _m.Called(ctx, id, alias, moment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, alias, moment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, id, passwordHash, moment
+func (_m *Repository) UpdatePassword(ctx context.Context, id int64, passwordHash string, moment time.Time) error {
+	ret := _m.Called(ctx, id, passwordHash, moment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, passwordHash, moment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStrategy provides a mock function with given fields: ctx, id, strategy, moment
+func (_m *Repository) UpdateStrategy(ctx context.Context, id int64, strategy string, moment time.Time) error {
+	ret := _m.Called(ctx, id, strategy, moment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, strategy, moment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateURL provides a mock function with given fields: ctx, id, url, editorID, moment
+func (_m *Repository) UpdateURL(ctx context.Context, id int64, url string, editorID int64, moment time.Time) error {
+	ret := _m.Called(ctx, id, url, editorID, moment)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
-		r0 = rf(ctx, id, alias)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64, time.Time) error); ok {
+		r0 = rf(ctx, id, url, editorID, moment)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,57 @@
+package clicks
+
+import (
+	"fmt"
+	"time"
+)
+
+// Visitor describes the client who followed a short link
+type Visitor struct {
+	Referrer  string
+	UserAgent string
+	IP        string
+}
+
+// Click is a redirect event. IP address of the visitor is never stored, only its hash
+type Click struct {
+	ID        int64
+	LinkID    int64
+	AdID      int64
+	Time      time.Time
+	Referrer  string
+	UserAgent string
+	IPHash    string
+}
+
+func (c Click) String() string {
+	return fmt.Sprintf(
+		"<Click id=%d linkID=%d adID=%d time=%s referrer=`%s` userAgent=`%s`>",
+		c.ID,
+		c.LinkID,
+		c.AdID,
+		c.Time,
+		c.Referrer,
+		c.UserAgent,
+	)
+}
+
+func New(linkID int64, adID int64, referrer string, userAgent string, ipHash string) Click {
+	return Click{
+		LinkID:    linkID,
+		AdID:      adID,
+		Time:      time.Now().UTC(),
+		Referrer:  referrer,
+		UserAgent: userAgent,
+		IPHash:    ipHash,
+	}
+}
+
+// AdClick is a click on the ad shown on redirect of the link. Impression is ID of the ad's impression,
+// Visitor is hash of IP address of the visitor
+type AdClick struct {
+	LinkID     int64
+	AdID       int64
+	Impression string
+	Visitor    string
+	Time       time.Time
+}
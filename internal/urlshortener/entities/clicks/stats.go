@@ -0,0 +1,33 @@
+package clicks
+
+import "time"
+
+type DayStat struct {
+	Day   time.Time
+	Count int64
+}
+
+type ReferrerStat struct {
+	Referrer string
+	Count    int64
+}
+
+type AdStat struct {
+	AdID  int64
+	Count int64
+}
+
+// Stats is aggregated clicks of a link. AdID = 0 in ByAd means redirects without an ad
+type Stats struct {
+	Total      int64
+	ByDay      []DayStat
+	ByReferrer []ReferrerStat
+	ByAd       []AdStat
+}
+
+// AdPerformance is number of redirects of a link showing the ad and number of clicks on the shown ad
+type AdPerformance struct {
+	AdID   int64
+	Shows  int64
+	Clicks int64
+}
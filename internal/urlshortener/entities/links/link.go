@@ -2,32 +2,95 @@ package links
 
 import (
 	"fmt"
+	"time"
 )
 
+// DefaultAdDelay is seconds during which an ad is shown before redirecting
+const DefaultAdDelay = 5
+
+// DefaultAdWeight is weight of an ad which weight is not set. MaxAdWeight limits weights of ads
+const (
+	DefaultAdWeight = 1
+	MaxAdWeight     = 1000
+)
+
+// Strategies of selecting the ad shown on redirect
+const (
+	StrategyRandom     = "random"
+	StrategyWeighted   = "weighted"
+	StrategyRoundRobin = "round_robin"
+	StrategyBandit     = "bandit"
+)
+
+// Link is a short link. Zero ActiveFrom and ExpiresAt mean that the link is active without
+// time restrictions. ArchivedAt is set when expired link has been archived. Link with
+// PasswordHash is protected and reveals URL only with the password. Strategy selects the ad shown on redirect,
+// AdWeights are used by the weighted strategy. UpdateDate is changed by updates of the link's own fields
 type Link struct {
-	ID       int64
-	URL      string `validate:"min:1;max:2048"`
-	Alias    string `validate:"min:1"`
-	AuthorID int64
-	Ads      []int64
+	ID           int64
+	URL          string `validate:"min:1;max:2048"`
+	Alias        string `validate:"min:1"`
+	AuthorID     int64
+	Ads          []int64
+	AdDelay      int `validate:"min:0;max:60"`
+	ActiveFrom   time.Time
+	ExpiresAt    time.Time
+	ArchivedAt   time.Time
+	PasswordHash string
+	Strategy     string
+	AdWeights    map[int64]int
+	CreateDate   time.Time
+	UpdateDate   time.Time
 }
 
 func (l Link) String() string {
 	return fmt.Sprintf(
-		"<Ad id=%d authorID=%d url=`%s` alias=`%v` ads=%v>",
+		"<Ad id=%d authorID=%d url=`%s` alias=`%v` ads=%v adDelay=%d activeFrom=%s expiresAt=%s strategy=%s>",
 		l.ID,
 		l.AuthorID,
 		l.URL,
 		l.Alias,
 		l.Ads,
+		l.AdDelay,
+		l.ActiveFrom,
+		l.ExpiresAt,
+		l.Strategy,
 	)
 }
 
-func New(url string, alias string, authorID int64, ads []int64) Link {
+// AdWeight returns weight of the ad. Ad without set weight has DefaultAdWeight
+func (l Link) AdWeight(adID int64) int {
+	if w, ok := l.AdWeights[adID]; ok {
+		return w
+	}
+	return DefaultAdWeight
+}
+
+func (l Link) IsProtected() bool {
+	return l.PasswordHash != ""
+}
+
+// IsActivated reports whether the link has been activated at the moment
+func (l Link) IsActivated(moment time.Time) bool {
+	return l.ActiveFrom.IsZero() || !moment.Before(l.ActiveFrom)
+}
+
+// IsExpired reports whether the link has been expired at the moment
+func (l Link) IsExpired(moment time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !moment.Before(l.ExpiresAt)
+}
+
+func New(url string, alias string, authorID int64, ads []int64, adDelay int, activeFrom time.Time, expiresAt time.Time) Link {
 	return Link{
-		URL:      url,
-		Alias:    alias,
-		AuthorID: authorID,
-		Ads:      ads,
+		URL:        url,
+		Alias:      alias,
+		AuthorID:   authorID,
+		Ads:        ads,
+		AdDelay:    adDelay,
+		ActiveFrom: activeFrom,
+		ExpiresAt:  expiresAt,
+		Strategy:   StrategyRandom,
+		CreateDate: time.Now().UTC(),
+		UpdateDate: time.Now().UTC(),
 	}
 }
@@ -0,0 +1,37 @@
+package redirects
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+)
+
+// Impression is the ad shown to the visitor on redirect of the link. Visitor is hash of IP address
+// of the visitor. A click on the ad is recorded only once per impression
+type Impression struct {
+	ID        string
+	LinkID    int64
+	AdID      int64
+	Visitor   string
+	ExpiresAt time.Time
+}
+
+// IsExpired reports whether clicks of the impression are not accepted at the moment
+func (i Impression) IsExpired(moment time.Time) bool {
+	return !moment.Before(i.ExpiresAt)
+}
+
+// NewImpression creates the impression with a random ID
+func NewImpression(linkID int64, adID int64, visitor string, expiresAt time.Time) (Impression, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Impression{}, err
+	}
+	return Impression{
+		ID:        base64.RawURLEncoding.EncodeToString(id),
+		LinkID:    linkID,
+		AdID:      adID,
+		Visitor:   visitor,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -6,9 +6,12 @@ import (
 	"goads/internal/urlshortener/entities/links"
 )
 
+// Redirect is the link with the ad shown before redirecting. Impression is the token required to record
+// a click on the ad, it is empty if no ad is shown
 type Redirect struct {
-	Link links.Link
-	Ad   ads.Ad
+	Link       links.Link
+	Ad         ads.Ad
+	Impression string
 }
 
 func (r Redirect) String() string {
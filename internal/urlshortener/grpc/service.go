@@ -2,22 +2,48 @@ package grpc
 
 import (
 	"context"
+	"goads/internal/pkg/pagination"
+	"goads/internal/urlshortener/entities/clicks"
 	"goads/internal/urlshortener/entities/links"
 	"goads/internal/urlshortener/entities/redirects"
 	"goads/internal/urlshortener/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"time"
 )
 
 type App interface {
-	Create(ctx context.Context, url string, alias string, authorID int64, ads []int64) (links.Link, error)
+	Create(
+		ctx context.Context,
+		url string,
+		alias string,
+		authorID int64,
+		ads []int64,
+		adDelay int,
+		activeFrom time.Time,
+		expiresAt time.Time,
+		password string,
+		strategy string,
+	) (links.Link, error)
 	GetByID(ctx context.Context, id int64) (links.Link, error)
-	GetByAuthor(ctx context.Context, author int64) ([]links.Link, error)
+	GetByAuthor(ctx context.Context, author int64, page pagination.Page) ([]links.Link, pagination.Info, error)
 	GetByAlias(ctx context.Context, alias string) (links.Link, error)
-	GetRedirect(ctx context.Context, alias string) (redirects.Redirect, error)
+	GetRedirect(ctx context.Context, alias string, visitor clicks.Visitor, password string) (redirects.Redirect, error)
 	UpdateAlias(ctx context.Context, id int64, authorID int64, alias string) (links.Link, error)
-	AddAd(ctx context.Context, linkID int64, adID int64, authorID int64) (links.Link, error)
+	UpdateURL(ctx context.Context, id int64, authorID int64, url string) (links.Link, error)
+	UpdateAdDelay(ctx context.Context, id int64, authorID int64, adDelay int) (links.Link, error)
+	SetPassword(ctx context.Context, id int64, authorID int64, password string) (links.Link, error)
+	AddAd(
+		ctx context.Context,
+		linkID int64,
+		adID int64,
+		authorID int64,
+		weight int,
+		strategy string,
+	) (links.Link, error)
 	DeleteAd(ctx context.Context, linkID int64, adID int64, authorID int64) (links.Link, error)
+	ClickAd(ctx context.Context, alias string, adID int64, impression string, ip string) error
 	Delete(ctx context.Context, id int64, authorID int64) error
+	GetStats(ctx context.Context, linkID int64, authorID int64, from time.Time, to time.Time) (clicks.Stats, error)
 }
 
 type Service struct {
@@ -25,7 +51,15 @@ type Service struct {
 }
 
 func (s Service) Create(ctx context.Context, request *proto.CreateRequest) (*proto.LinkResponse, error) {
-	link, err := s.app.Create(ctx, request.Url, request.Alias, request.AuthorId, request.Ads)
+	adDelay := links.DefaultAdDelay
+	if request.AdDelay != nil {
+		adDelay = int(*request.AdDelay)
+	}
+	link, err := s.app.Create(
+		ctx, request.Url, request.Alias, request.AuthorId, request.Ads, adDelay,
+		millisToTime(request.ActiveFrom), millisToTime(request.ExpiresAt), request.Password,
+		request.Strategy,
+	)
 	return linkToResponse(link), getErrorStatus(err)
 }
 
@@ -35,8 +69,15 @@ func (s Service) GetByID(ctx context.Context, request *proto.GetByIDRequest) (*p
 }
 
 func (s Service) GetByAuthor(ctx context.Context, request *proto.GetByAuthorRequest) (*proto.LinksResponse, error) {
-	list, err := s.app.GetByAuthor(ctx, request.AuthorId)
-	return listLinkToResponse(list), getErrorStatus(err)
+	list, info, err := s.app.GetByAuthor(ctx, request.AuthorId, pagination.Page{
+		Size:  int(request.PageSize),
+		Token: request.PageToken,
+		Sort:  request.OrderBy,
+		Total: request.WithTotal,
+	})
+	res := listLinkToResponse(list)
+	res.NextPageToken, res.Total = info.NextToken, info.Total
+	return res, getErrorStatus(err)
 }
 
 func (s Service) GetByAlias(ctx context.Context, request *proto.GetByAliasRequest) (*proto.LinkResponse, error) {
@@ -44,8 +85,12 @@ func (s Service) GetByAlias(ctx context.Context, request *proto.GetByAliasReques
 	return linkToResponse(link), getErrorStatus(err)
 }
 
-func (s Service) GetRedirect(ctx context.Context, request *proto.GetByAliasRequest) (*proto.RedirectResponse, error) {
-	redirect, err := s.app.GetRedirect(ctx, request.Alias)
+func (s Service) GetRedirect(ctx context.Context, request *proto.RedirectRequest) (*proto.RedirectResponse, error) {
+	redirect, err := s.app.GetRedirect(ctx, request.Alias, clicks.Visitor{
+		Referrer:  request.Referrer,
+		UserAgent: request.UserAgent,
+		IP:        request.Ip,
+	}, request.Password)
 	return redirectToResponse(redirect), getErrorStatus(err)
 }
 
@@ -54,8 +99,25 @@ func (s Service) UpdateAlias(ctx context.Context, request *proto.UpdateAliasRequ
 	return linkToResponse(link), getErrorStatus(err)
 }
 
+func (s Service) UpdateURL(ctx context.Context, request *proto.UpdateURLRequest) (*proto.LinkResponse, error) {
+	link, err := s.app.UpdateURL(ctx, request.Id, request.AuthorId, request.Url)
+	return linkToResponse(link), getErrorStatus(err)
+}
+
+func (s Service) UpdateAdDelay(ctx context.Context, request *proto.UpdateAdDelayRequest) (*proto.LinkResponse, error) {
+	link, err := s.app.UpdateAdDelay(ctx, request.Id, request.AuthorId, int(request.AdDelay))
+	return linkToResponse(link), getErrorStatus(err)
+}
+
+func (s Service) SetPassword(ctx context.Context, request *proto.SetPasswordRequest) (*proto.LinkResponse, error) {
+	link, err := s.app.SetPassword(ctx, request.Id, request.AuthorId, request.Password)
+	return linkToResponse(link), getErrorStatus(err)
+}
+
 func (s Service) AddAd(ctx context.Context, request *proto.LinkAdRequest) (*proto.LinkResponse, error) {
-	link, err := s.app.AddAd(ctx, request.LinkId, request.AdId, request.AuthorId)
+	link, err := s.app.AddAd(
+		ctx, request.LinkId, request.AdId, request.AuthorId, int(request.Weight), request.Strategy,
+	)
 	return linkToResponse(link), getErrorStatus(err)
 }
 
@@ -64,11 +126,23 @@ func (s Service) DeleteAd(ctx context.Context, request *proto.LinkAdRequest) (*p
 	return linkToResponse(link), getErrorStatus(err)
 }
 
+func (s Service) ClickAd(ctx context.Context, request *proto.ClickAdRequest) (*emptypb.Empty, error) {
+	err := s.app.ClickAd(ctx, request.Alias, request.AdId, request.Impression, request.Ip)
+	return new(emptypb.Empty), getErrorStatus(err)
+}
+
 func (s Service) Delete(ctx context.Context, request *proto.DeleteRequest) (*emptypb.Empty, error) {
 	err := s.app.Delete(ctx, request.Id, request.AuthorId)
 	return new(emptypb.Empty), getErrorStatus(err)
 }
 
+func (s Service) GetStats(ctx context.Context, request *proto.GetStatsRequest) (*proto.StatsResponse, error) {
+	stats, err := s.app.GetStats(
+		ctx, request.LinkId, request.AuthorId, millisToTime(request.From), millisToTime(request.To),
+	)
+	return statsToResponse(stats), getErrorStatus(err)
+}
+
 func NewService(a App) Service {
 	return Service{a}
 }
@@ -5,11 +5,13 @@ import (
 	"goads/internal/pkg/errwrap"
 	"goads/internal/urlshortener/app"
 	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/clicks"
 	"goads/internal/urlshortener/entities/links"
 	"goads/internal/urlshortener/entities/redirects"
 	"goads/internal/urlshortener/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"time"
 )
 
 func getErrorStatus(err error) error {
@@ -33,23 +35,72 @@ func getErrorStatus(err error) error {
 	if errors.Is(err, app.ErrNotFound) || errors.Is(err, app.ErrAdNotExists) {
 		code = codes.NotFound
 	}
-	if errors.Is(err, app.ErrPermissionDenied) {
+	if errors.Is(err, app.ErrPermissionDenied) || errors.Is(err, app.ErrInvalidImpression) {
 		code = codes.PermissionDenied
 	}
+	if errors.Is(err, app.ErrNotActive) {
+		code = codes.NotFound
+	}
+	// link is out of its time range, so it is not found as well, but should be distinguishable
+	if errors.Is(err, app.ErrExpired) {
+		code = codes.OutOfRange
+	}
+	if errors.Is(err, app.ErrPasswordRequired) {
+		code = codes.Unauthenticated
+	}
+	if errors.Is(err, app.ErrWrongPassword) {
+		code = codes.PermissionDenied
+	}
+	if errors.Is(err, app.ErrTooManyAttempts) {
+		code = codes.ResourceExhausted
+	}
 	if code == codes.Internal {
 		err = errors.New("internal error")
 	}
 	return status.Error(code, err.Error())
 }
 
+// millisToTime converts unix milliseconds to time. 0 means unset time and is converted to zero time
+func millisToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// timeToMillis converts time to unix milliseconds. Zero time is converted to 0
+func timeToMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
 func linkToResponse(link links.Link) *proto.LinkResponse {
 	return &proto.LinkResponse{
-		Id:       link.ID,
-		Url:      link.URL,
-		Alias:    link.Alias,
-		AuthorId: link.AuthorID,
-		Ads:      link.Ads,
+		Id:         link.ID,
+		Url:        link.URL,
+		Alias:      link.Alias,
+		AuthorId:   link.AuthorID,
+		Ads:        link.Ads,
+		AdDelay:    int32(link.AdDelay),
+		ActiveFrom: timeToMillis(link.ActiveFrom),
+		ExpiresAt:  timeToMillis(link.ExpiresAt),
+		Archived:   !link.ArchivedAt.IsZero(),
+		Protected:  link.IsProtected(),
+		Strategy:   link.Strategy,
+		AdWeights:  adWeightsToResponse(link),
+		CreateDate: timeToMillis(link.CreateDate),
+		UpdateDate: timeToMillis(link.UpdateDate),
+	}
+}
+
+func adWeightsToResponse(link links.Link) map[int64]int32 {
+	res := make(map[int64]int32, len(link.Ads))
+	for _, id := range link.Ads {
+		res[id] = int32(link.AdWeight(id))
 	}
+	return res
 }
 
 func adToResponse(ad ads.Ad) *proto.AdResponse {
@@ -62,8 +113,9 @@ func adToResponse(ad ads.Ad) *proto.AdResponse {
 
 func redirectToResponse(r redirects.Redirect) *proto.RedirectResponse {
 	return &proto.RedirectResponse{
-		Link: linkToResponse(r.Link),
-		Ad:   adToResponse(r.Ad),
+		Link:       linkToResponse(r.Link),
+		Ad:         adToResponse(r.Ad),
+		Impression: r.Impression,
 	}
 }
 
@@ -76,3 +128,22 @@ func listLinkToResponse(list []links.Link) *proto.LinksResponse {
 	}
 	return &res
 }
+
+func statsToResponse(stats clicks.Stats) *proto.StatsResponse {
+	res := proto.StatsResponse{
+		Total:      stats.Total,
+		ByDay:      make([]*proto.DayStat, len(stats.ByDay)),
+		ByReferrer: make([]*proto.ReferrerStat, len(stats.ByReferrer)),
+		ByAd:       make([]*proto.AdStat, len(stats.ByAd)),
+	}
+	for i, s := range stats.ByDay {
+		res.ByDay[i] = &proto.DayStat{Day: s.Day.UnixMilli(), Count: s.Count}
+	}
+	for i, s := range stats.ByReferrer {
+		res.ByReferrer[i] = &proto.ReferrerStat{Referrer: s.Referrer, Count: s.Count}
+	}
+	for i, s := range stats.ByAd {
+		res.ByAd[i] = &proto.AdStat{AdId: s.AdID, Count: s.Count}
+	}
+	return &res
+}
@@ -20,15 +20,20 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ShortenerService_Create_FullMethodName      = "/urlshortener.ShortenerService/Create"
-	ShortenerService_GetByID_FullMethodName     = "/urlshortener.ShortenerService/GetByID"
-	ShortenerService_GetByAuthor_FullMethodName = "/urlshortener.ShortenerService/GetByAuthor"
-	ShortenerService_GetByAlias_FullMethodName  = "/urlshortener.ShortenerService/GetByAlias"
-	ShortenerService_GetRedirect_FullMethodName = "/urlshortener.ShortenerService/GetRedirect"
-	ShortenerService_UpdateAlias_FullMethodName = "/urlshortener.ShortenerService/UpdateAlias"
-	ShortenerService_AddAd_FullMethodName       = "/urlshortener.ShortenerService/AddAd"
-	ShortenerService_DeleteAd_FullMethodName    = "/urlshortener.ShortenerService/DeleteAd"
-	ShortenerService_Delete_FullMethodName      = "/urlshortener.ShortenerService/Delete"
+	ShortenerService_Create_FullMethodName        = "/urlshortener.ShortenerService/Create"
+	ShortenerService_GetByID_FullMethodName       = "/urlshortener.ShortenerService/GetByID"
+	ShortenerService_GetByAuthor_FullMethodName   = "/urlshortener.ShortenerService/GetByAuthor"
+	ShortenerService_GetByAlias_FullMethodName    = "/urlshortener.ShortenerService/GetByAlias"
+	ShortenerService_GetRedirect_FullMethodName   = "/urlshortener.ShortenerService/GetRedirect"
+	ShortenerService_UpdateAlias_FullMethodName   = "/urlshortener.ShortenerService/UpdateAlias"
+	ShortenerService_UpdateURL_FullMethodName     = "/urlshortener.ShortenerService/UpdateURL"
+	ShortenerService_UpdateAdDelay_FullMethodName = "/urlshortener.ShortenerService/UpdateAdDelay"
+	ShortenerService_SetPassword_FullMethodName   = "/urlshortener.ShortenerService/SetPassword"
+	ShortenerService_AddAd_FullMethodName         = "/urlshortener.ShortenerService/AddAd"
+	ShortenerService_DeleteAd_FullMethodName      = "/urlshortener.ShortenerService/DeleteAd"
+	ShortenerService_ClickAd_FullMethodName       = "/urlshortener.ShortenerService/ClickAd"
+	ShortenerService_Delete_FullMethodName        = "/urlshortener.ShortenerService/Delete"
+	ShortenerService_GetStats_FullMethodName      = "/urlshortener.ShortenerService/GetStats"
 )
 
 // ShortenerServiceClient is the client API for ShortenerService service.
@@ -39,11 +44,16 @@ type ShortenerServiceClient interface {
 	GetByID(ctx context.Context, in *GetByIDRequest, opts ...grpc.CallOption) (*LinkResponse, error)
 	GetByAuthor(ctx context.Context, in *GetByAuthorRequest, opts ...grpc.CallOption) (*LinksResponse, error)
 	GetByAlias(ctx context.Context, in *GetByAliasRequest, opts ...grpc.CallOption) (*LinkResponse, error)
-	GetRedirect(ctx context.Context, in *GetByAliasRequest, opts ...grpc.CallOption) (*RedirectResponse, error)
+	GetRedirect(ctx context.Context, in *RedirectRequest, opts ...grpc.CallOption) (*RedirectResponse, error)
 	UpdateAlias(ctx context.Context, in *UpdateAliasRequest, opts ...grpc.CallOption) (*LinkResponse, error)
+	UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*LinkResponse, error)
+	UpdateAdDelay(ctx context.Context, in *UpdateAdDelayRequest, opts ...grpc.CallOption) (*LinkResponse, error)
+	SetPassword(ctx context.Context, in *SetPasswordRequest, opts ...grpc.CallOption) (*LinkResponse, error)
 	AddAd(ctx context.Context, in *LinkAdRequest, opts ...grpc.CallOption) (*LinkResponse, error)
 	DeleteAd(ctx context.Context, in *LinkAdRequest, opts ...grpc.CallOption) (*LinkResponse, error)
+	ClickAd(ctx context.Context, in *ClickAdRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
 }
 
 type shortenerServiceClient struct {
@@ -90,7 +100,7 @@ func (c *shortenerServiceClient) GetByAlias(ctx context.Context, in *GetByAliasR
 	return out, nil
 }
 
-func (c *shortenerServiceClient) GetRedirect(ctx context.Context, in *GetByAliasRequest, opts ...grpc.CallOption) (*RedirectResponse, error) {
+func (c *shortenerServiceClient) GetRedirect(ctx context.Context, in *RedirectRequest, opts ...grpc.CallOption) (*RedirectResponse, error) {
 	out := new(RedirectResponse)
 	err := c.cc.Invoke(ctx, ShortenerService_GetRedirect_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -108,6 +118,33 @@ func (c *shortenerServiceClient) UpdateAlias(ctx context.Context, in *UpdateAlia
 	return out, nil
 }
 
+func (c *shortenerServiceClient) UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*LinkResponse, error) {
+	out := new(LinkResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_UpdateURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerServiceClient) UpdateAdDelay(ctx context.Context, in *UpdateAdDelayRequest, opts ...grpc.CallOption) (*LinkResponse, error) {
+	out := new(LinkResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_UpdateAdDelay_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerServiceClient) SetPassword(ctx context.Context, in *SetPasswordRequest, opts ...grpc.CallOption) (*LinkResponse, error) {
+	out := new(LinkResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_SetPassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *shortenerServiceClient) AddAd(ctx context.Context, in *LinkAdRequest, opts ...grpc.CallOption) (*LinkResponse, error) {
 	out := new(LinkResponse)
 	err := c.cc.Invoke(ctx, ShortenerService_AddAd_FullMethodName, in, out, opts...)
@@ -126,6 +163,15 @@ func (c *shortenerServiceClient) DeleteAd(ctx context.Context, in *LinkAdRequest
 	return out, nil
 }
 
+func (c *shortenerServiceClient) ClickAd(ctx context.Context, in *ClickAdRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ShortenerService_ClickAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *shortenerServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, ShortenerService_Delete_FullMethodName, in, out, opts...)
@@ -135,6 +181,15 @@ func (c *shortenerServiceClient) Delete(ctx context.Context, in *DeleteRequest,
 	return out, nil
 }
 
+func (c *shortenerServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_GetStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortenerServiceServer is the server API for ShortenerService service.
 // All implementations should embed UnimplementedShortenerServiceServer
 // for forward compatibility
@@ -143,11 +198,16 @@ type ShortenerServiceServer interface {
 	GetByID(context.Context, *GetByIDRequest) (*LinkResponse, error)
 	GetByAuthor(context.Context, *GetByAuthorRequest) (*LinksResponse, error)
 	GetByAlias(context.Context, *GetByAliasRequest) (*LinkResponse, error)
-	GetRedirect(context.Context, *GetByAliasRequest) (*RedirectResponse, error)
+	GetRedirect(context.Context, *RedirectRequest) (*RedirectResponse, error)
 	UpdateAlias(context.Context, *UpdateAliasRequest) (*LinkResponse, error)
+	UpdateURL(context.Context, *UpdateURLRequest) (*LinkResponse, error)
+	UpdateAdDelay(context.Context, *UpdateAdDelayRequest) (*LinkResponse, error)
+	SetPassword(context.Context, *SetPasswordRequest) (*LinkResponse, error)
 	AddAd(context.Context, *LinkAdRequest) (*LinkResponse, error)
 	DeleteAd(context.Context, *LinkAdRequest) (*LinkResponse, error)
+	ClickAd(context.Context, *ClickAdRequest) (*emptypb.Empty, error)
 	Delete(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	GetStats(context.Context, *GetStatsRequest) (*StatsResponse, error)
 }
 
 // UnimplementedShortenerServiceServer should be embedded to have forward compatible implementations.
@@ -166,21 +226,36 @@ func (UnimplementedShortenerServiceServer) GetByAuthor(context.Context, *GetByAu
 func (UnimplementedShortenerServiceServer) GetByAlias(context.Context, *GetByAliasRequest) (*LinkResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetByAlias not implemented")
 }
-func (UnimplementedShortenerServiceServer) GetRedirect(context.Context, *GetByAliasRequest) (*RedirectResponse, error) {
+func (UnimplementedShortenerServiceServer) GetRedirect(context.Context, *RedirectRequest) (*RedirectResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRedirect not implemented")
 }
 func (UnimplementedShortenerServiceServer) UpdateAlias(context.Context, *UpdateAliasRequest) (*LinkResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateAlias not implemented")
 }
+func (UnimplementedShortenerServiceServer) UpdateURL(context.Context, *UpdateURLRequest) (*LinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateURL not implemented")
+}
+func (UnimplementedShortenerServiceServer) UpdateAdDelay(context.Context, *UpdateAdDelayRequest) (*LinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAdDelay not implemented")
+}
+func (UnimplementedShortenerServiceServer) SetPassword(context.Context, *SetPasswordRequest) (*LinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPassword not implemented")
+}
 func (UnimplementedShortenerServiceServer) AddAd(context.Context, *LinkAdRequest) (*LinkResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddAd not implemented")
 }
 func (UnimplementedShortenerServiceServer) DeleteAd(context.Context, *LinkAdRequest) (*LinkResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteAd not implemented")
 }
+func (UnimplementedShortenerServiceServer) ClickAd(context.Context, *ClickAdRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClickAd not implemented")
+}
 func (UnimplementedShortenerServiceServer) Delete(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedShortenerServiceServer) GetStats(context.Context, *GetStatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
 
 // UnsafeShortenerServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ShortenerServiceServer will
@@ -266,7 +341,7 @@ func _ShortenerService_GetByAlias_Handler(srv interface{}, ctx context.Context,
 }
 
 func _ShortenerService_GetRedirect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetByAliasRequest)
+	in := new(RedirectRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -278,7 +353,7 @@ func _ShortenerService_GetRedirect_Handler(srv interface{}, ctx context.Context,
 		FullMethod: ShortenerService_GetRedirect_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ShortenerServiceServer).GetRedirect(ctx, req.(*GetByAliasRequest))
+		return srv.(ShortenerServiceServer).GetRedirect(ctx, req.(*RedirectRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -301,6 +376,60 @@ func _ShortenerService_UpdateAlias_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_UpdateURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).UpdateURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_UpdateURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).UpdateURL(ctx, req.(*UpdateURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortenerService_UpdateAdDelay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAdDelayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).UpdateAdDelay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_UpdateAdDelay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).UpdateAdDelay(ctx, req.(*UpdateAdDelayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortenerService_SetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).SetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_SetPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).SetPassword(ctx, req.(*SetPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShortenerService_AddAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LinkAdRequest)
 	if err := dec(in); err != nil {
@@ -337,6 +466,24 @@ func _ShortenerService_DeleteAd_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_ClickAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClickAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).ClickAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_ClickAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).ClickAd(ctx, req.(*ClickAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShortenerService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteRequest)
 	if err := dec(in); err != nil {
@@ -355,6 +502,24 @@ func _ShortenerService_Delete_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShortenerService_ServiceDesc is the grpc.ServiceDesc for ShortenerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -386,6 +551,18 @@ var ShortenerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateAlias",
 			Handler:    _ShortenerService_UpdateAlias_Handler,
 		},
+		{
+			MethodName: "UpdateURL",
+			Handler:    _ShortenerService_UpdateURL_Handler,
+		},
+		{
+			MethodName: "UpdateAdDelay",
+			Handler:    _ShortenerService_UpdateAdDelay_Handler,
+		},
+		{
+			MethodName: "SetPassword",
+			Handler:    _ShortenerService_SetPassword_Handler,
+		},
 		{
 			MethodName: "AddAd",
 			Handler:    _ShortenerService_AddAd_Handler,
@@ -394,10 +571,18 @@ var ShortenerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAd",
 			Handler:    _ShortenerService_DeleteAd_Handler,
 		},
+		{
+			MethodName: "ClickAd",
+			Handler:    _ShortenerService_ClickAd_Handler,
+		},
 		{
 			MethodName: "Delete",
 			Handler:    _ShortenerService_Delete_Handler,
 		},
+		{
+			MethodName: "GetStats",
+			Handler:    _ShortenerService_GetStats_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "urlshortener.proto",
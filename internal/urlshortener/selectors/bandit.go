@@ -0,0 +1,59 @@
+package selectors
+
+import (
+	"context"
+	"goads/internal/pkg/errwrap"
+	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/clicks"
+	"goads/internal/urlshortener/entities/links"
+	"math/rand"
+)
+
+type Repository interface {
+	GetAdPerformance(ctx context.Context, linkID int64) ([]clicks.AdPerformance, error)
+}
+
+// Bandit is epsilon-greedy multi-armed bandit optimizing click-through rate of the ads.
+// With probability Epsilon it explores a random ad, otherwise it shows the ad with the best rate
+type Bandit struct {
+	Repo    Repository
+	Epsilon float64
+}
+
+// rate is click-through rate with Laplace smoothing, so ads without shows are worth exploring
+func rate(p clicks.AdPerformance) float64 {
+	return float64(p.Clicks+1) / float64(p.Shows+2)
+}
+
+func (b Bandit) Select(ctx context.Context, link links.Link, list []ads.Ad) (ads.Ad, error) {
+	const op = "selectors.Bandit.Select"
+	if rand.Float64() < b.Epsilon {
+		return list[rand.Intn(len(list))], nil
+	}
+	perf, err := b.Repo.GetAdPerformance(ctx, link.ID)
+	if err != nil {
+		return ads.Ad{}, errwrap.JoinWithCaller(err, op)
+	}
+	rates := make(map[int64]float64, len(perf))
+	for _, p := range perf {
+		rates[p.AdID] = rate(p)
+	}
+	best, bestRate := 0, -1.0
+	for i, ad := range list {
+		r, ok := rates[ad.ID]
+		if !ok {
+			r = rate(clicks.AdPerformance{AdID: ad.ID})
+		}
+		if r > bestRate {
+			best, bestRate = i, r
+		}
+	}
+	return list[best], nil
+}
+
+func NewBandit(repo Repository, epsilon float64) Bandit {
+	return Bandit{
+		Repo:    repo,
+		Epsilon: epsilon,
+	}
+}
@@ -0,0 +1,29 @@
+package selectors
+
+import (
+	"context"
+	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/links"
+	"sync"
+)
+
+// RoundRobin selects ads of the link in turn. Turns are kept in memory, so they are not shared between instances
+type RoundRobin struct {
+	mu    *sync.Mutex
+	turns map[int64]int
+}
+
+func (r RoundRobin) Select(_ context.Context, link links.Link, list []ads.Ad) (ads.Ad, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	turn := r.turns[link.ID] % len(list)
+	r.turns[link.ID] = turn + 1
+	return list[turn], nil
+}
+
+func NewRoundRobin() RoundRobin {
+	return RoundRobin{
+		mu:    &sync.Mutex{},
+		turns: make(map[int64]int),
+	}
+}
@@ -0,0 +1,66 @@
+package selectors
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/clicks"
+	"goads/internal/urlshortener/entities/links"
+	"testing"
+)
+
+var list = []ads.Ad{{ID: 1}, {ID: 2}, {ID: 3}}
+
+func TestWeighted_Select(t *testing.T) {
+	link := links.Link{AdWeights: map[int64]int{1: 0, 2: 5, 3: 0}}
+	for i := 0; i < 20; i++ {
+		ad, err := NewWeighted().Select(context.Background(), link, list)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), ad.ID)
+	}
+}
+
+func TestWeighted_Select_ZeroWeights(t *testing.T) {
+	link := links.Link{AdWeights: map[int64]int{1: 0, 2: 0, 3: 0}}
+	for i := 0; i < 20; i++ {
+		ad, err := NewWeighted().Select(context.Background(), link, list)
+		require.NoError(t, err)
+		assert.Contains(t, []int64{1, 2, 3}, ad.ID)
+	}
+}
+
+func TestRoundRobin_Select(t *testing.T) {
+	r := NewRoundRobin()
+	var got []int64
+	for i := 0; i < 4; i++ {
+		ad, err := r.Select(context.Background(), links.Link{ID: 1}, list)
+		require.NoError(t, err)
+		got = append(got, ad.ID)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 1}, got)
+
+	ad, _ := r.Select(context.Background(), links.Link{ID: 2}, list)
+	assert.Equal(t, int64(1), ad.ID, "links must have independent turns")
+}
+
+type repo []clicks.AdPerformance
+
+func (r repo) GetAdPerformance(_ context.Context, _ int64) ([]clicks.AdPerformance, error) {
+	return r, nil
+}
+
+func TestBandit_Select(t *testing.T) {
+	perf := repo{
+		{AdID: 1, Shows: 100, Clicks: 5},
+		{AdID: 2, Shows: 100, Clicks: 30},
+		{AdID: 3, Shows: 100, Clicks: 10},
+	}
+	ad, err := NewBandit(perf, 0).Select(context.Background(), links.Link{}, list)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), ad.ID)
+
+	ad, err = NewBandit(perf[:2], 0).Select(context.Background(), links.Link{}, list)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), ad.ID, "ad without shows must be explored")
+}
@@ -0,0 +1,34 @@
+package selectors
+
+import (
+	"context"
+	"goads/internal/urlshortener/entities/ads"
+	"goads/internal/urlshortener/entities/links"
+	"math/rand"
+)
+
+// Weighted selects the ad randomly with probability proportional to its weight in the link.
+// If all ads have zero weight, any of them is selected with equal probability
+type Weighted struct{}
+
+func (w Weighted) Select(_ context.Context, link links.Link, list []ads.Ad) (ads.Ad, error) {
+	total := 0
+	for _, ad := range list {
+		total += link.AdWeight(ad.ID)
+	}
+	if total <= 0 {
+		return list[rand.Intn(len(list))], nil
+	}
+	r := rand.Intn(total)
+	for _, ad := range list {
+		r -= link.AdWeight(ad.ID)
+		if r < 0 {
+			return ad, nil
+		}
+	}
+	return list[len(list)-1], nil
+}
+
+func NewWeighted() Weighted {
+	return Weighted{}
+}
@@ -0,0 +1,49 @@
+package sweeper
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type Repository interface {
+	ArchiveExpired(ctx context.Context, moment time.Time) (int64, error)
+}
+
+// Sweeper archives expired links every interval in background
+type Sweeper struct {
+	Repo     Repository
+	interval time.Duration
+}
+
+func (s Sweeper) sweep(ctx context.Context) {
+	n, err := s.Repo.ArchiveExpired(ctx, time.Now().UTC())
+	if err != nil {
+		log.Printf("cannot archive expired links: %v\n", err)
+	} else if n > 0 {
+		log.Printf("%d expired links have been archived\n", n)
+	}
+}
+
+// Listen sweeps expired links until ctx is done
+func (s Sweeper) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func New(repo Repository, interval time.Duration) Sweeper {
+	return Sweeper{
+		Repo:     repo,
+		interval: interval,
+	}
+}
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS clicks;
+CREATE TABLE clicks
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    link_id    BIGINT    NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    ad_id      BIGINT,
+    created_at TIMESTAMP NOT NULL,
+    referrer   TEXT      NOT NULL,
+    user_agent TEXT      NOT NULL,
+    ip_hash    TEXT      NOT NULL
+);
+CREATE INDEX clicks_link_id_created_at_idx ON clicks (link_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE links DROP COLUMN ad_delay;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE links ADD COLUMN ad_delay INT NOT NULL DEFAULT 5;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS links_expires_at_idx;
+ALTER TABLE links
+    DROP COLUMN active_from,
+    DROP COLUMN expires_at,
+    DROP COLUMN archived_at;
\ No newline at end of file
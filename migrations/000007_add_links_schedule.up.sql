@@ -0,0 +1,5 @@
+ALTER TABLE links
+    ADD COLUMN active_from TIMESTAMP,
+    ADD COLUMN expires_at  TIMESTAMP,
+    ADD COLUMN archived_at TIMESTAMP;
+CREATE INDEX links_expires_at_idx ON links (expires_at) WHERE archived_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE links
+    ADD COLUMN password TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE link_url_history;
\ No newline at end of file
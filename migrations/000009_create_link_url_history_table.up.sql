@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS link_url_history;
+CREATE TABLE link_url_history
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    link_id    BIGINT    NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    url        TEXT      NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX link_url_history_link_id_idx ON link_url_history (link_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS clicks_link_id_ad_id_idx;
+DROP TABLE ad_clicks;
+ALTER TABLE link_ads
+    DROP COLUMN weight;
+ALTER TABLE links
+    DROP COLUMN strategy;
\ No newline at end of file
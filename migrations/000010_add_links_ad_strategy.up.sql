@@ -0,0 +1,14 @@
+ALTER TABLE links
+    ADD COLUMN strategy TEXT NOT NULL DEFAULT 'random';
+ALTER TABLE link_ads
+    ADD COLUMN weight INT NOT NULL DEFAULT 1;
+DROP TABLE IF EXISTS ad_clicks;
+CREATE TABLE ad_clicks
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    link_id    BIGINT    NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    ad_id      BIGINT    NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX ad_clicks_link_id_ad_id_idx ON ad_clicks (link_id, ad_id);
+CREATE INDEX clicks_link_id_ad_id_idx ON clicks (link_id, ad_id);
@@ -0,0 +1,3 @@
+ALTER TABLE ads
+    DROP COLUMN campaign_id;
+DROP TABLE campaigns;
\ No newline at end of file
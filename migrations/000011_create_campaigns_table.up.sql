@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS campaigns;
+CREATE TABLE campaigns
+(
+    id              BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    author_id       BIGINT
+        CONSTRAINT campaigns_author_id_key NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name            TEXT   NOT NULL,
+    start_date      TIMESTAMP,
+    end_date        TIMESTAMP,
+    total_cap       BIGINT NOT NULL DEFAULT 0,
+    daily_cap       BIGINT NOT NULL DEFAULT 0,
+    impressions     BIGINT NOT NULL DEFAULT 0,
+    impressions_day DATE,
+    day_impressions BIGINT NOT NULL DEFAULT 0
+);
+ALTER TABLE ads
+    ADD COLUMN campaign_id BIGINT
+        CONSTRAINT ads_campaign_id_key REFERENCES campaigns (id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS refresh_tokens;
+CREATE TABLE refresh_tokens
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id    BIGINT
+        CONSTRAINT refresh_tokens_user_id_key NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family     TEXT      NOT NULL,
+    hash       TEXT
+        CONSTRAINT refresh_tokens_hash_key UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used       BOOLEAN   NOT NULL DEFAULT FALSE,
+    revoked    BOOLEAN   NOT NULL DEFAULT FALSE
+);
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS revoked_tokens;
+CREATE TABLE revoked_tokens
+(
+    jti        TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+DROP TABLE IF EXISTS revoked_users;
+CREATE TABLE revoked_users
+(
+    user_id        BIGINT PRIMARY KEY,
+    revoked_before TIMESTAMP NOT NULL
+);
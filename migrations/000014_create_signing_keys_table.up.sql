@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS signing_keys;
+CREATE TABLE signing_keys
+(
+    id          TEXT PRIMARY KEY,
+    private_key TEXT      NOT NULL,
+    created_at  TIMESTAMP NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS used_action_tokens;
+ALTER TABLE users
+    DROP COLUMN verified;
\ No newline at end of file
@@ -0,0 +1,11 @@
+ALTER TABLE users
+    ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+-- accounts registered before verification had been introduced are trusted
+UPDATE users
+SET verified = TRUE;
+DROP TABLE IF EXISTS used_action_tokens;
+CREATE TABLE used_action_tokens
+(
+    id         TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
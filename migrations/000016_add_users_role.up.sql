@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+-- the first admin is appointed manually: UPDATE users SET role = 'admin' WHERE email = '...';
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS api_keys;
+CREATE TABLE api_keys
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id    BIGINT
+        CONSTRAINT api_keys_user_id_key NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT      NOT NULL,
+    prefix     TEXT      NOT NULL,
+    hash       TEXT
+        CONSTRAINT api_keys_hash_key UNIQUE NOT NULL,
+    scopes     TEXT[]    NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS totp_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+CREATE TABLE user_totp
+(
+    user_id   BIGINT PRIMARY KEY
+        CONSTRAINT user_totp_user_id_key REFERENCES users (id) ON DELETE CASCADE,
+    secret    TEXT    NOT NULL,
+    enabled   BOOLEAN NOT NULL DEFAULT FALSE,
+    last_step BIGINT  NOT NULL DEFAULT 0
+);
+CREATE TABLE totp_recovery_codes
+(
+    user_id BIGINT NOT NULL REFERENCES user_totp (user_id) ON DELETE CASCADE,
+    hash    TEXT   NOT NULL,
+    PRIMARY KEY (user_id, hash)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE login_failures
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id    BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    email      TEXT      NOT NULL,
+    ip         TEXT,
+    reason     TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX login_failures_email_idx ON login_failures (email);
+-- login_throttle counts recent failures by keys "account:<email>" and "ip:<address>"
+CREATE TABLE login_throttle
+(
+    key          TEXT PRIMARY KEY,
+    failures     INT       NOT NULL,
+    last_failure TIMESTAMP NOT NULL
+);
@@ -0,0 +1,3 @@
+-- hashes longer than 72 characters must be reset before the rollback
+ALTER TABLE users
+    ALTER COLUMN password TYPE VARCHAR(72);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ALTER COLUMN password TYPE TEXT;
@@ -0,0 +1,26 @@
+CREATE TABLE sessions
+(
+    id           TEXT PRIMARY KEY,
+    user_id      BIGINT
+        CONSTRAINT sessions_user_id_key NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device       TEXT      NOT NULL DEFAULT '',
+    ip           TEXT      NOT NULL DEFAULT '',
+    user_agent   TEXT      NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NOT NULL,
+    expires_at   TIMESTAMP NOT NULL,
+    revoked      BOOLEAN   NOT NULL DEFAULT FALSE
+);
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+-- families of refresh tokens issued before sessions become sessions of unknown clients
+INSERT INTO sessions (id, user_id, created_at, last_used_at, expires_at)
+SELECT family, user_id, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC', MAX(expires_at)
+FROM refresh_tokens
+WHERE NOT revoked
+GROUP BY family, user_id;
+-- revoked_sessions is revocation list of access tokens by their session
+CREATE TABLE revoked_sessions
+(
+    id         TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,13 @@
+DELETE FROM ads WHERE author_id NOT IN (SELECT id FROM users);
+DELETE FROM campaigns WHERE author_id NOT IN (SELECT id FROM users);
+DELETE FROM links WHERE author_id NOT IN (SELECT id FROM users);
+ALTER TABLE ads
+    ADD CONSTRAINT ads_author_id_key FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE campaigns
+    ADD CONSTRAINT campaigns_author_id_key FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE links
+    ADD CONSTRAINT links_author_id_key FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE;
+DROP TABLE IF EXISTS event_cursors;
+DROP TABLE IF EXISTS user_events;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS delete_after;
\ No newline at end of file
@@ -0,0 +1,33 @@
+ALTER TABLE users
+    ADD COLUMN delete_after TIMESTAMP;
+CREATE INDEX users_delete_after_idx ON users (delete_after) WHERE delete_after IS NOT NULL;
+-- user_events is outbox of Auth read by other services, so it has no reference to users
+CREATE TABLE user_events
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    type       TEXT      NOT NULL,
+    user_id    BIGINT    NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+-- event_cursors stores ID of the last handled event for each consumer
+CREATE TABLE event_cursors
+(
+    consumer      TEXT PRIMARY KEY,
+    last_event_id BIGINT NOT NULL
+);
+-- data of other services is deleted by user events instead of cascades
+DO
+$$
+    DECLARE
+        c RECORD;
+    BEGIN
+        FOR c IN SELECT conrelid::regclass AS tbl, conname
+                 FROM pg_constraint
+                 WHERE contype = 'f'
+                   AND confrelid = 'users'::regclass
+                   AND conrelid IN ('ads'::regclass, 'campaigns'::regclass, 'links'::regclass)
+            LOOP
+                EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', c.tbl, c.conname);
+            END LOOP;
+    END
+$$;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS ads_search_idx;
+ALTER TABLE ads
+    DROP COLUMN IF EXISTS search;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS ads_search;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- ads_search stems Cyrillic words by Russian and Latin words by English snowball stemmer
+CREATE TEXT SEARCH CONFIGURATION ads_search (COPY = russian);
+ALTER TABLE ads
+    ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('ads_search'::regconfig, title), 'A') ||
+        setweight(to_tsvector('ads_search'::regconfig, text), 'B')
+        ) STORED;
+CREATE INDEX ads_search_idx ON ads USING GIN (search);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS ads_title_idx;
+DROP INDEX IF EXISTS ads_update_date_idx;
+DROP INDEX IF EXISTS ads_create_date_idx;
+DROP INDEX IF EXISTS links_author_alias_idx;
+DROP INDEX IF EXISTS links_author_updated_idx;
+DROP INDEX IF EXISTS links_author_created_idx;
+ALTER TABLE links
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at;
\ No newline at end of file
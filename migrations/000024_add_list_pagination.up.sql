@@ -0,0 +1,10 @@
+ALTER TABLE links
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC');
+-- indexes of keyset pagination: sort key with ID as tie-breaker
+CREATE INDEX links_author_created_idx ON links (author_id, created_at, id);
+CREATE INDEX links_author_updated_idx ON links (author_id, updated_at, id);
+CREATE INDEX links_author_alias_idx ON links (author_id, alias, id);
+CREATE INDEX ads_create_date_idx ON ads (create_date, id);
+CREATE INDEX ads_update_date_idx ON ads (update_date, id);
+CREATE INDEX ads_title_idx ON ads (title, id);
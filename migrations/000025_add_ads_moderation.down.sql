@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS ads_status_update_date_idx;
+UPDATE ads
+SET published = status = 'published';
+ALTER TABLE ads
+    DROP COLUMN IF EXISTS reject_reason,
+    DROP COLUMN IF EXISTS status;
\ No newline at end of file
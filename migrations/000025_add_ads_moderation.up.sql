@@ -0,0 +1,11 @@
+ALTER TABLE ads
+    ADD COLUMN status        TEXT NOT NULL DEFAULT 'draft'
+        CONSTRAINT ads_status_check CHECK (status IN
+                                           ('draft', 'pending', 'approved', 'rejected', 'published', 'paused',
+                                            'archived')),
+    ADD COLUMN reject_reason TEXT NOT NULL DEFAULT '';
+-- existing ads have never been reviewed, so published ads are sent to the moderation queue
+UPDATE ads
+SET status    = CASE WHEN published THEN 'pending' ELSE 'draft' END,
+    published = false;
+CREATE INDEX ads_status_update_date_idx ON ads (status, update_date, id);
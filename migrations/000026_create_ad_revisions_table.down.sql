@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ad_revisions;
\ No newline at end of file
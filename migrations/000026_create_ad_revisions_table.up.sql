@@ -0,0 +1,15 @@
+CREATE TABLE ad_revisions
+(
+    id         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    ad_id      BIGINT    NOT NULL REFERENCES ads (id) ON DELETE CASCADE,
+    version    INTEGER   NOT NULL,
+    editor_id  BIGINT    NOT NULL,
+    title      TEXT      NOT NULL,
+    text       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT ad_revisions_ad_id_version_key UNIQUE (ad_id, version)
+);
+-- current content of existing ads is their first revision
+INSERT INTO ad_revisions (ad_id, version, editor_id, title, text, created_at)
+SELECT id, 1, author_id, COALESCE(title, ''), COALESCE(text, ''), COALESCE(update_date, create_date, NOW() AT TIME ZONE 'UTC')
+FROM ads;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS ad_clicks_visitor_idx;
+ALTER TABLE ad_clicks
+    DROP COLUMN IF EXISTS visitor,
+    DROP COLUMN IF EXISTS impression_id;
\ No newline at end of file
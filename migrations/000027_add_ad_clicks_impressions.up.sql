@@ -0,0 +1,5 @@
+ALTER TABLE ad_clicks
+    ADD COLUMN impression_id TEXT UNIQUE,
+    ADD COLUMN visitor       TEXT NOT NULL DEFAULT '';
+-- clicks of the visitor are deduplicated within a window
+CREATE INDEX ad_clicks_visitor_idx ON ad_clicks (link_id, ad_id, visitor, created_at);
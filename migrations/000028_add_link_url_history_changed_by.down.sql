@@ -0,0 +1,2 @@
+ALTER TABLE link_url_history
+    DROP COLUMN IF EXISTS changed_by;
\ No newline at end of file
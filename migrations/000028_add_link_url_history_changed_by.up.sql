@@ -0,0 +1,3 @@
+-- editor of the link, NULL for changes made before editors were recorded
+ALTER TABLE link_url_history
+    ADD COLUMN changed_by BIGINT;
@@ -0,0 +1,4 @@
+DELETE FROM link_ads la
+WHERE NOT EXISTS (SELECT 1 FROM ads a WHERE a.id = la.ad_id);
+ALTER TABLE link_ads
+    ADD CONSTRAINT link_ads_ad_id_key FOREIGN KEY (ad_id) REFERENCES ads (id) ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- ads are owned by the ads service, links drop rows of deleted ads themselves
+ALTER TABLE link_ads
+    DROP CONSTRAINT IF EXISTS link_ads_ad_id_key;
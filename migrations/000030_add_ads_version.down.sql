@@ -0,0 +1,2 @@
+ALTER TABLE ads
+    DROP COLUMN IF EXISTS version;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- the last version of the ad revisions. Edits increment it under the row lock, so concurrent edits get distinct versions
+ALTER TABLE ads
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+UPDATE ads a
+SET version = r.version
+FROM (SELECT ad_id, MAX(version) AS version FROM ad_revisions GROUP BY ad_id) r
+WHERE r.ad_id = a.id;